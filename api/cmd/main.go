@@ -4,7 +4,9 @@ import (
 	"github.com/VladPetriv/d2j/config"
 	"github.com/VladPetriv/d2j/internal/app"
 	"github.com/VladPetriv/d2j/pkg/logger"
+	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
 func main() {
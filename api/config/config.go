@@ -3,16 +3,20 @@ package config
 import (
 	"log"
 	"sync"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 // Config represents a structure that contains a configurations for different part of application.
 type Config struct {
-	App    App
-	HTTP   HTTP
-	Logger Logger
-	Redis  Redis
+	App      App
+	Database Database
+	Export   Export
+	HTTP     HTTP
+	Logger   Logger
+	Redis    Redis
+	SQLite   SQLite
 }
 
 type (
@@ -20,6 +24,42 @@ type (
 	// App represents a configuration for entire application.
 	App struct {
 		EncryptionSecretKey string `env:"ENCRYPTION_SECRET_KEY" env-default:"dxc3hve7mwfJEU9q"`
+		// AllowRawWhere enables raw SQL WHERE conditions in conversion requests, which are not safe against SQL injections.
+		AllowRawWhere bool `env:"ALLOW_RAW_WHERE" env-default:"false"`
+		// MaxConnectionSessionTime is a maximum lifetime of connection session since its creation, including extensions.
+		MaxConnectionSessionTime time.Duration `env:"MAX_CONNECTION_SESSION_TIME" env-default:"24h"`
+		// AdminToken is a bearer token for admin endpoints, which are disabled when it's empty.
+		AdminToken string `env:"ADMIN_TOKEN" env-default:""`
+	}
+
+	// Database represents a configuration for connections to user databases.
+	Database struct {
+		// PoolMaxClients is a maximum number of sessions which could keep their connections open at the same time.
+		PoolMaxClients int `env:"DATABASE_POOL_MAX_CLIENTS" env-default:"100"`
+		// PoolIdleTimeout is a time after which connections of unused session are closed.
+		PoolIdleTimeout    time.Duration `env:"DATABASE_POOL_IDLE_TIMEOUT" env-default:"10m"`
+		MaxOpenConnections int           `env:"DATABASE_MAX_OPEN_CONNECTIONS" env-default:"5"`
+		MaxIdleConnections int           `env:"DATABASE_MAX_IDLE_CONNECTIONS" env-default:"2"`
+		// CustomQueryTimeout is a maximum execution time of SQL queries written by users.
+		CustomQueryTimeout time.Duration `env:"DATABASE_CUSTOM_QUERY_TIMEOUT" env-default:"30s"`
+		// MaxImportSize is a maximum size of request body with imported rows in bytes.
+		MaxImportSize int64 `env:"DATABASE_MAX_IMPORT_SIZE" env-default:"104857600"`
+		// MaxSampleSize is a maximum size of sample rows for table inference in bytes. Sample is kept in memory.
+		MaxSampleSize int64 `env:"DATABASE_MAX_SAMPLE_SIZE" env-default:"10485760"`
+	}
+
+	// Export represents a configuration for asynchronous export jobs.
+	Export struct {
+		// Directory is a place where export files are stored. It must be shared by all API replicas.
+		Directory string `env:"EXPORT_DIRECTORY" env-default:"./exports"`
+		// Workers is a number of export jobs that could run at the same time on a single replica.
+		Workers int `env:"EXPORT_WORKERS" env-default:"4"`
+		// QueueSize is a maximum number of export jobs that wait for a free worker on a single replica.
+		QueueSize int `env:"EXPORT_QUEUE_SIZE" env-default:"100"`
+		// JobTTL is a time for which job state and its export file are kept.
+		JobTTL time.Duration `env:"EXPORT_JOB_TTL" env-default:"24h"`
+		// EventsTokenTTL is a time for which token of the job events stream could be used to open the stream.
+		EventsTokenTTL time.Duration `env:"EXPORT_EVENTS_TOKEN_TTL" env-default:"1m"`
 	}
 
 	// HTTP represents a configuration for HTTP server.
@@ -39,6 +79,12 @@ type (
 		Password string `env:"REDIS_PASSWORD" env-default:""`
 		Database int    `env:"REDIS_DATABASE" env-default:"0"`
 	}
+
+	// SQLite represents a configuration for SQLite databases.
+	SQLite struct {
+		// Directory is the only place where SQLite database files could be opened from.
+		Directory string `env:"SQLITE_DIRECTORY" env-default:"./data"`
+	}
 )
 
 var (
@@ -15,12 +15,27 @@ import (
 	"github.com/VladPetriv/d2j/pkg/hashing"
 	"github.com/VladPetriv/d2j/pkg/httpserver"
 	"github.com/VladPetriv/d2j/pkg/logger"
+	"github.com/VladPetriv/d2j/pkg/workerpool"
 	"github.com/gin-gonic/gin"
 )
 
 // Run runs entire application
 func Run(config config.Config, logger logger.Logger) {
-	postgreSQL := database.NewPostgreSQL(logger)
+	databases := map[database.Dialect]database.Database{
+		database.DialectPostgreSQL: database.NewPostgreSQL(logger),
+		database.DialectMySQL:      database.NewMySQL(logger),
+		database.DialectSQLite:     database.NewSQLite(logger, config.SQLite.Directory),
+	}
+
+	clientPool := database.NewClientPool(logger, database.ClientPoolOptions{
+		MaxClients:  config.Database.PoolMaxClients,
+		IdleTimeout: config.Database.PoolIdleTimeout,
+	})
+
+	exportPool := workerpool.New(workerpool.Options{
+		Workers:   config.Export.Workers,
+		QueueSize: config.Export.QueueSize,
+	})
 
 	encryptor := encryption.New()
 	hasher := hashing.NewBcrypt()
@@ -32,12 +47,14 @@ func Run(config config.Config, logger logger.Logger) {
 	})
 
 	serviceOptions := service.Options{
-		Logger:    logger,
-		Config:    config,
-		Cacher:    redis,
-		Encryptor: encryptor,
-		Hasher:    hasher,
-		Database:  postgreSQL,
+		Logger:     logger,
+		Config:     config,
+		Cacher:     redis,
+		Encryptor:  encryptor,
+		Hasher:     hasher,
+		Databases:  databases,
+		ClientPool: clientPool,
+		ExportPool: exportPool,
 	}
 
 	services := service.Services{
@@ -77,6 +94,17 @@ func Run(config config.Config, logger logger.Logger) {
 		logger.Error("app - Run - httpServer.Shutdown", "err", err)
 	}
 
+	// Export jobs are stopped before their database clients are closed.
+	err = exportPool.Close()
+	if err != nil {
+		logger.Error("close export worker pool", "err", err)
+	}
+
+	err = clientPool.Close()
+	if err != nil {
+		logger.Error("close database client pool", "err", err)
+	}
+
 	err = redis.Close()
 	if err != nil {
 		logger.Error("close redis connection", "err", err)
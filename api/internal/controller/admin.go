@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/VladPetriv/d2j/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type adminRouter struct {
+	RouterContext
+}
+
+func setupAdminRoutes(options RouterOptions) {
+	r := adminRouter{
+		RouterContext: RouterContext{
+			logger:   options.Logger,
+			config:   options.Config,
+			services: options.Services,
+		},
+	}
+
+	admin := options.Handler.Group("/admin", r.authMiddleware)
+	{
+		admin.POST("/sessions/revoke", wrapHandler(options, r.revokeDatabaseSessions))
+		admin.GET("/pool-stats", wrapHandler(options, r.listConnectionPoolStats))
+	}
+}
+
+// authMiddleware allows requests only with a bearer token that matches configured admin token.
+func (r adminRouter) authMiddleware(c *gin.Context) {
+	logger := r.logger.Named("adminRouter.authMiddleware")
+
+	if r.config.App.AdminToken == "" {
+		logger.Info("admin endpoints are disabled")
+		c.AbortWithStatusJSON(http.StatusForbidden, httpResponseError{Message: "admin endpoints are disabled"})
+		return
+	}
+
+	token, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(r.config.App.AdminToken)) != 1 {
+		logger.Info("invalid admin token")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, httpResponseError{Message: "invalid admin token"})
+		return
+	}
+
+	c.Next()
+}
+
+type revokeDatabaseSessionsRequestBody struct {
+	*service.RevokeDatabaseSessionsOptions
+}
+
+type revokeDatabaseSessionsResponseBody struct {
+	RevokedSessions int `json:"revokedSessions"`
+}
+
+func (r adminRouter) revokeDatabaseSessions(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("adminRouter.revokeDatabaseSessions")
+
+	var requestBody revokeDatabaseSessionsRequestBody
+	err := c.ShouldBindJSON(&requestBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "requestBody", requestBody)
+
+	revokedSessions, err := r.services.Database.RevokeDatabaseSessions(c, *requestBody.RevokeDatabaseSessionsOptions)
+	if err != nil {
+		logger.Error("revoke database sessions", "err", err)
+		return nil, &httpResponseError{Message: "revoke database sessions", Type: ErrorTypeServer}
+	}
+
+	logger.Info("revoked database sessions", "revokedSessions", revokedSessions)
+	return revokeDatabaseSessionsResponseBody{revokedSessions}, nil
+}
+
+type listConnectionPoolStatsResponseBody struct {
+	Sessions []service.ConnectionPoolStats `json:"sessions"`
+}
+
+func (r adminRouter) listConnectionPoolStats(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("adminRouter.listConnectionPoolStats")
+
+	stats := r.services.Database.ListConnectionPoolStats(c)
+
+	logger.Info("got connection pool stats", "sessionsCount", len(stats))
+	return listConnectionPoolStatsResponseBody{stats}, nil
+}
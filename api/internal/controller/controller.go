@@ -47,6 +47,7 @@ func New(options Options) {
 	// Routers
 	{
 		setupDatabaseRoutes(routerOptions)
+		setupAdminRoutes(routerOptions)
 	}
 }
 
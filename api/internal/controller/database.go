@@ -2,6 +2,8 @@ package controller
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 
 	"github.com/VladPetriv/d2j/internal/service"
 	"github.com/VladPetriv/d2j/pkg/errs"
@@ -25,8 +27,31 @@ func setupDatabaseRoutes(options RouterOptions) {
 	{
 		database.POST("/test-connection", wrapHandler(options, r.testDBConnection))
 		database.POST("/connect", wrapHandler(options, r.connectToDatabase))
+		database.POST("/disconnect", wrapHandler(options, r.disconnectFromDatabase))
+		database.POST("/session", wrapHandler(options, r.getDatabaseSession))
+		database.POST("/session/extend", wrapHandler(options, r.extendDatabaseSession))
+		database.POST("/list-schemas", wrapHandler(options, r.listDatabaseSchemas))
 		database.POST("/list-tables", wrapHandler(options, r.listDatabaseTables))
+		database.POST("/describe-table", wrapHandler(options, r.describeDatabaseTable))
+		database.POST("/json-schema", wrapHandler(options, r.generateDatabaseTableJSONSchema))
+		database.POST("/codegen", wrapHandler(options, r.generateDatabaseTableCode))
 		database.POST("/get-json", wrapHandler(options, r.convertDatabaseResultToJSON))
+		database.POST("/custom-query", wrapHandler(options, r.convertCustomQueryResultToJSON))
+		database.POST("/get-yaml", wrapHandler(options, r.convertDatabaseResultToYAML))
+		database.POST("/get-toml", wrapHandler(options, r.convertDatabaseResultToTOML))
+		database.POST("/stream-json", wrapHandler(options, r.streamDatabaseResultToJSON))
+		database.POST("/stream-csv", wrapHandler(options, r.streamDatabaseResultToCSV))
+		database.POST("/stream-xml", wrapHandler(options, r.streamDatabaseResultToXML))
+		database.POST("/stream-parquet", wrapHandler(options, r.streamDatabaseResultToParquet))
+		database.POST("/dump", wrapHandler(options, r.dumpDatabase))
+		database.POST("/import", wrapHandler(options, r.importDatabaseRows))
+		database.POST("/create-table", wrapHandler(options, r.createDatabaseTableFromJSON))
+		database.POST("/export-jobs/submit", wrapHandler(options, r.submitExportJob))
+		database.POST("/export-jobs/status", wrapHandler(options, r.getExportJob))
+		database.POST("/export-jobs/cancel", wrapHandler(options, r.cancelExportJob))
+		database.POST("/export-jobs/download", wrapHandler(options, r.downloadExportJobFile))
+		database.POST("/export-jobs/events-token", wrapHandler(options, r.createExportJobEventsToken))
+		database.GET("/export-jobs/events", wrapHandler(options, r.streamExportJobEvents))
 	}
 }
 
@@ -97,6 +122,11 @@ func (r databaseRouter) connectToDatabase(c *gin.Context) (interface{}, *httpRes
 
 	databaseKey, err := r.services.Database.ConnectToDatabase(c, *requestBody.ConnectToDatabaseOptions)
 	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
 		logger.Error("connect to database", "err", err)
 		return nil, &httpResponseError{Message: "connect to database", Type: ErrorTypeServer}
 	}
@@ -105,12 +135,142 @@ func (r databaseRouter) connectToDatabase(c *gin.Context) (interface{}, *httpRes
 	return connectToDatabaseResponse{databaseKey}, nil
 }
 
+type disconnectFromDatabaseRequestBody struct {
+	*service.DisconnectFromDatabaseOptions
+}
+
+type disconnectFromDatabaseResponseBody struct {
+	Message string `json:"message"`
+}
+
+func (r databaseRouter) disconnectFromDatabase(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.disconnectFromDatabase")
+
+	var requestBody disconnectFromDatabaseRequestBody
+	err := c.ShouldBindJSON(&requestBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+
+	err = r.services.Database.DisconnectFromDatabase(c, *requestBody.DisconnectFromDatabaseOptions)
+	if err != nil {
+		logger.Error("disconnect from database", "err", err)
+		return nil, &httpResponseError{Message: "disconnect from database", Type: ErrorTypeServer}
+	}
+
+	logger.Info("disconnected from database")
+	return disconnectFromDatabaseResponseBody{
+		Message: "You have successfully disconnected from your database.",
+	}, nil
+}
+
+type getDatabaseSessionRequestBody struct {
+	*service.GetDatabaseSessionOptions
+}
+
+func (r databaseRouter) getDatabaseSession(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.getDatabaseSession")
+
+	var requestBody getDatabaseSessionRequestBody
+	err := c.ShouldBindJSON(&requestBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+
+	session, err := r.services.Database.GetDatabaseSession(c, *requestBody.GetDatabaseSessionOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+
+		logger.Error("get database session", "err", err)
+		return nil, &httpResponseError{Message: "get database session", Type: ErrorTypeServer}
+	}
+
+	logger.Info("got database session")
+	return session, nil
+}
+
+type extendDatabaseSessionRequestBody struct {
+	*service.ExtendDatabaseSessionOptions
+}
+
+func (r databaseRouter) extendDatabaseSession(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.extendDatabaseSession")
+
+	var requestBody extendDatabaseSessionRequestBody
+	err := c.ShouldBindJSON(&requestBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "extendBy", requestBody.ExtendBy)
+
+	session, err := r.services.Database.ExtendDatabaseSession(c, *requestBody.ExtendDatabaseSessionOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("extend database session", "err", err)
+		return nil, &httpResponseError{Message: "extend database session", Type: ErrorTypeServer}
+	}
+
+	logger.Info("extended database session", "remainingTime", session.RemainingTime)
+	return session, nil
+}
+
+type listDatabaseSchemasRequestBody struct {
+	*service.ListDatabaseSchemasOptions
+}
+
+type listDatabaseSchemasResponse struct {
+	Schemas []string `json:"schemas"`
+}
+
+func (r databaseRouter) listDatabaseSchemas(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.listDatabaseSchemas")
+
+	var requestBody listDatabaseSchemasRequestBody
+	err := c.ShouldBindJSON(&requestBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+
+	schemas, err := r.services.Database.ListDatabaseSchemas(c, *requestBody.ListDatabaseSchemasOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("list database schemas", "err", err)
+		return nil, &httpResponseError{Message: "list database schemas", Type: ErrorTypeServer}
+	}
+
+	logger.Info("got database schemas", "schemas", schemas)
+	return listDatabaseSchemasResponse{schemas}, nil
+}
+
 type listDatabaseTablesRequestBody struct {
 	*service.ListDatabaseTablesOptions
 }
 
 type listDatabaseTablesResponse struct {
-	Tables []string `json:"tables"`
+	Tables []service.DatabaseTable `json:"tables"`
 }
 
 func (r databaseRouter) listDatabaseTables(c *gin.Context) (interface{}, *httpResponseError) {
@@ -131,6 +291,11 @@ func (r databaseRouter) listDatabaseTables(c *gin.Context) (interface{}, *httpRe
 			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
 		}
 
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
 		logger.Error("list database tables", "err", err)
 		return nil, &httpResponseError{Message: "list database tables", Type: ErrorTypeServer}
 	}
@@ -139,12 +304,122 @@ func (r databaseRouter) listDatabaseTables(c *gin.Context) (interface{}, *httpRe
 	return listDatabaseTablesResponse{tables}, nil
 }
 
+type describeDatabaseTableRequestBody struct {
+	*service.DescribeDatabaseTableOptions
+}
+
+func (r databaseRouter) describeDatabaseTable(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.describeDatabaseTable")
+
+	var requestBody describeDatabaseTableRequestBody
+	err := c.ShouldBindJSON(&requestBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "tableName", requestBody.TableName)
+
+	description, err := r.services.Database.DescribeDatabaseTable(c, *requestBody.DescribeDatabaseTableOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("describe database table", "err", err)
+		return nil, &httpResponseError{Message: "describe database table", Type: ErrorTypeServer}
+	}
+
+	logger.Info("described database table", "tableName", description.TableName)
+	return description, nil
+}
+
+type generateDatabaseTableJSONSchemaRequestBody struct {
+	*service.GenerateDatabaseTableJSONSchemaOptions
+}
+
+func (r databaseRouter) generateDatabaseTableJSONSchema(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.generateDatabaseTableJSONSchema")
+
+	var requestBody generateDatabaseTableJSONSchemaRequestBody
+	err := c.ShouldBindJSON(&requestBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "tableName", requestBody.TableName, "fields", requestBody.Fields)
+
+	schema, err := r.services.Database.GenerateDatabaseTableJSONSchema(c, *requestBody.GenerateDatabaseTableJSONSchemaOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("generate database table json schema", "err", err)
+		return nil, &httpResponseError{Message: "generate database table json schema", Type: ErrorTypeServer}
+	}
+
+	logger.Info("generated database table json schema", "tableName", requestBody.TableName)
+	return schema, nil
+}
+
+type generateDatabaseTableCodeRequestBody struct {
+	*service.GenerateDatabaseTableCodeOptions
+}
+
+type generateDatabaseTableCodeResponseBody struct {
+	Result string `json:"result"`
+}
+
+func (r databaseRouter) generateDatabaseTableCode(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.generateDatabaseTableCode")
+
+	var requestBody generateDatabaseTableCodeRequestBody
+	err := c.ShouldBindJSON(&requestBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "tableName", requestBody.TableName, "language", requestBody.Language)
+
+	code, err := r.services.Database.GenerateDatabaseTableCode(c, *requestBody.GenerateDatabaseTableCodeOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("generate database table code", "err", err)
+		return nil, &httpResponseError{Message: "generate database table code", Type: ErrorTypeServer}
+	}
+
+	logger.Info("generated database table code", "tableName", requestBody.TableName, "language", requestBody.Language)
+	return generateDatabaseTableCodeResponseBody{code}, nil
+}
+
 type convertDatabaseResultToJSONRequestBody struct {
 	*service.ConvertDatabaseResultToJSONOptions
+	// Async submits the conversion as an export job of JSON format and returns the job instead of the result.
+	// Progress of the job could be watched by server-sent events and the result is downloaded after completion.
+	Async bool `json:"async"`
 }
 
 type convertDatabaseResultToJSONResponseBody struct {
-	Result string `json:"result"`
+	Result     string `json:"result"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 func (r databaseRouter) convertDatabaseResultToJSON(c *gin.Context) (interface{}, *httpResponseError) {
@@ -158,17 +433,500 @@ func (r databaseRouter) convertDatabaseResultToJSON(c *gin.Context) (interface{}
 	}
 	logger.Debug("parsed request body", "reqBody", reqBody)
 
+	if reqBody.Async {
+		return r.submitConversionJob(c, *reqBody.ConvertDatabaseResultToJSONOptions)
+	}
+
 	convertedResult, err := r.services.Database.ConvertDatabaseResultToJSON(c, *reqBody.ConvertDatabaseResultToJSONOptions)
 	if err != nil {
 		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
 			logger.Info(err.Error())
 			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
 		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
 
 		logger.Error("convert database result to JSON", "err", err)
 		return nil, &httpResponseError{Message: "convert database result to JSON", Type: ErrorTypeServer}
 	}
 
 	logger.Info("converted database result to JSON")
-	return convertDatabaseResultToJSONResponseBody{convertedResult}, nil
+	return convertDatabaseResultToJSONResponseBody{
+		Result:     convertedResult.Result,
+		NextCursor: convertedResult.NextCursor,
+	}, nil
+}
+
+// submitConversionJob runs the conversion in the background, so long conversions are not limited by request timeout.
+func (r databaseRouter) submitConversionJob(
+	c *gin.Context, options service.ConvertDatabaseResultToJSONOptions,
+) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.submitConversionJob")
+
+	job, err := r.services.Database.SubmitExportJob(c, service.SubmitExportJobOptions{
+		ConvertDatabaseResultToJSONOptions: options,
+		Format:                             service.ExportFormatJSON,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("submit conversion job", "err", err)
+		return nil, &httpResponseError{Message: "submit conversion job", Type: ErrorTypeServer}
+	}
+
+	logger.Info("submitted conversion job", "jobId", job.ID)
+	return job, nil
+}
+
+type convertCustomQueryResultToJSONRequestBody struct {
+	*service.ConvertCustomQueryResultToJSONOptions
+}
+
+func (r databaseRouter) convertCustomQueryResultToJSON(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.convertCustomQueryResultToJSON")
+
+	var reqBody convertCustomQueryResultToJSONRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "reqBody", reqBody)
+
+	convertedResult, err := r.services.Database.ConvertCustomQueryResultToJSON(c, *reqBody.ConvertCustomQueryResultToJSONOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("convert custom query result to JSON", "err", err)
+		return nil, &httpResponseError{Message: "convert custom query result to JSON", Type: ErrorTypeServer}
+	}
+
+	logger.Info("converted custom query result to JSON")
+	return convertDatabaseResultToJSONResponseBody{Result: convertedResult}, nil
+}
+
+type convertDatabaseResultToDocumentRequestBody struct {
+	*service.ConvertDatabaseResultToDocumentOptions
+}
+
+type convertDatabaseResultToDocumentResponseBody struct {
+	Result string `json:"result"`
+}
+
+func (r databaseRouter) convertDatabaseResultToYAML(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.convertDatabaseResultToYAML")
+
+	var reqBody convertDatabaseResultToDocumentRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "reqBody", reqBody)
+
+	convertedResult, err := r.services.Database.ConvertDatabaseResultToYAML(c, *reqBody.ConvertDatabaseResultToDocumentOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("convert database result to YAML", "err", err)
+		return nil, &httpResponseError{Message: "convert database result to YAML", Type: ErrorTypeServer}
+	}
+
+	logger.Info("converted database result to YAML")
+	return convertDatabaseResultToDocumentResponseBody{convertedResult}, nil
+}
+
+func (r databaseRouter) convertDatabaseResultToTOML(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.convertDatabaseResultToTOML")
+
+	var reqBody convertDatabaseResultToDocumentRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "reqBody", reqBody)
+
+	convertedResult, err := r.services.Database.ConvertDatabaseResultToTOML(c, *reqBody.ConvertDatabaseResultToDocumentOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("convert database result to TOML", "err", err)
+		return nil, &httpResponseError{Message: "convert database result to TOML", Type: ErrorTypeServer}
+	}
+
+	logger.Info("converted database result to TOML")
+	return convertDatabaseResultToDocumentResponseBody{convertedResult}, nil
+}
+
+type streamDatabaseResultToJSONRequestBody struct {
+	*service.StreamDatabaseResultToJSONOptions
+}
+
+var streamContentTypes = map[service.StreamFormat]string{
+	service.StreamFormatNDJSON: "application/x-ndjson",
+	service.StreamFormatArray:  "application/json",
+}
+
+func (r databaseRouter) streamDatabaseResultToJSON(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.streamDatabaseResultToJSON")
+
+	var reqBody streamDatabaseResultToJSONRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "reqBody", reqBody)
+
+	if reqBody.Format == "" {
+		reqBody.Format = service.StreamFormatNDJSON
+	}
+
+	// Request context is canceled when client disconnects, which stops the running query.
+	err = r.services.Database.StreamDatabaseResultToJSON(
+		c.Request.Context(), *reqBody.StreamDatabaseResultToJSONOptions,
+		newStreamWriter(c.Writer, streamContentTypes[reqBody.Format]),
+	)
+	if err != nil {
+		// Status and part of the body are already sent, so it's only possible to interrupt the stream.
+		if c.Writer.Written() {
+			logger.Error("stream database result to JSON interrupted", "err", err)
+			c.Abort()
+			return nil, nil
+		}
+
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("stream database result to JSON", "err", err)
+		return nil, &httpResponseError{Message: "stream database result to JSON", Type: ErrorTypeServer}
+	}
+
+	logger.Info("streamed database result to JSON")
+	return nil, nil
+}
+
+type streamDatabaseResultToCSVRequestBody struct {
+	*service.StreamDatabaseResultToCSVOptions
+}
+
+var csvContentTypes = map[service.CSVFormat]string{
+	service.CSVFormatCSV: "text/csv; charset=utf-8",
+	service.CSVFormatTSV: "text/tab-separated-values; charset=utf-8",
+}
+
+func (r databaseRouter) streamDatabaseResultToCSV(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.streamDatabaseResultToCSV")
+
+	var reqBody streamDatabaseResultToCSVRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "reqBody", reqBody)
+
+	if reqBody.Format == "" {
+		reqBody.Format = service.CSVFormatCSV
+	}
+
+	// Request context is canceled when client disconnects, which stops the running query.
+	err = r.services.Database.StreamDatabaseResultToCSV(
+		c.Request.Context(), *reqBody.StreamDatabaseResultToCSVOptions,
+		newStreamWriter(c.Writer, csvContentTypes[reqBody.Format]),
+	)
+	if err != nil {
+		// Status and part of the body are already sent, so it's only possible to interrupt the stream.
+		if c.Writer.Written() {
+			logger.Error("stream database result to CSV interrupted", "err", err)
+			c.Abort()
+			return nil, nil
+		}
+
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("stream database result to CSV", "err", err)
+		return nil, &httpResponseError{Message: "stream database result to CSV", Type: ErrorTypeServer}
+	}
+
+	logger.Info("streamed database result to CSV")
+	return nil, nil
+}
+
+type streamDatabaseResultToXMLRequestBody struct {
+	*service.StreamDatabaseResultToXMLOptions
+}
+
+func (r databaseRouter) streamDatabaseResultToXML(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.streamDatabaseResultToXML")
+
+	var reqBody streamDatabaseResultToXMLRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "reqBody", reqBody)
+
+	// Request context is canceled when client disconnects, which stops the running query.
+	err = r.services.Database.StreamDatabaseResultToXML(
+		c.Request.Context(), *reqBody.StreamDatabaseResultToXMLOptions,
+		newStreamWriter(c.Writer, "application/xml; charset=utf-8"),
+	)
+	if err != nil {
+		// Status and part of the body are already sent, so it's only possible to interrupt the stream.
+		if c.Writer.Written() {
+			logger.Error("stream database result to XML interrupted", "err", err)
+			c.Abort()
+			return nil, nil
+		}
+
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("stream database result to XML", "err", err)
+		return nil, &httpResponseError{Message: "stream database result to XML", Type: ErrorTypeServer}
+	}
+
+	logger.Info("streamed database result to XML")
+	return nil, nil
+}
+
+type streamDatabaseResultToParquetRequestBody struct {
+	*service.StreamDatabaseResultToParquetOptions
+}
+
+func (r databaseRouter) streamDatabaseResultToParquet(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.streamDatabaseResultToParquet")
+
+	var reqBody streamDatabaseResultToParquetRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "reqBody", reqBody)
+
+	// Request context is canceled when client disconnects, which stops the running query.
+	err = r.services.Database.StreamDatabaseResultToParquet(
+		c.Request.Context(), *reqBody.StreamDatabaseResultToParquetOptions,
+		newStreamWriter(c.Writer, "application/vnd.apache.parquet"),
+	)
+	if err != nil {
+		// Status and part of the body are already sent, so it's only possible to interrupt the stream.
+		if c.Writer.Written() {
+			logger.Error("stream database result to Parquet interrupted", "err", err)
+			c.Abort()
+			return nil, nil
+		}
+
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("stream database result to Parquet", "err", err)
+		return nil, &httpResponseError{Message: "stream database result to Parquet", Type: ErrorTypeServer}
+	}
+
+	logger.Info("streamed database result to Parquet")
+	return nil, nil
+}
+
+type dumpDatabaseRequestBody struct {
+	*service.DumpDatabaseOptions
+}
+
+func (r databaseRouter) dumpDatabase(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.dumpDatabase")
+
+	var reqBody dumpDatabaseRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "reqBody", reqBody)
+
+	c.Header("Content-Disposition", `attachment; filename="dump.zip"`)
+
+	// Request context is canceled when client disconnects, which stops the running query.
+	err = r.services.Database.DumpDatabase(
+		c.Request.Context(), *reqBody.DumpDatabaseOptions,
+		newStreamWriter(c.Writer, "application/zip"),
+	)
+	if err != nil {
+		// Status and part of the body are already sent, so it's only possible to interrupt the stream.
+		if c.Writer.Written() {
+			logger.Error("dump database interrupted", "err", err)
+			c.Abort()
+			return nil, nil
+		}
+
+		// Error is sent as JSON, so it must not be downloaded as the archive.
+		c.Writer.Header().Del("Content-Disposition")
+
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("dump database", "err", err)
+		return nil, &httpResponseError{Message: "dump database", Type: ErrorTypeServer}
+	}
+
+	logger.Info("dumped database")
+	return nil, nil
+}
+
+type importDatabaseRowsRequestQuery struct {
+	*service.ImportDatabaseRowsOptions
+}
+
+// importDatabaseRows reads options from query parameters, because request body is a JSON array or NDJSON of the rows.
+func (r databaseRouter) importDatabaseRows(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.importDatabaseRows")
+
+	var reqQuery importDatabaseRowsRequestQuery
+	err := c.ShouldBindQuery(&reqQuery)
+	if err != nil {
+		logger.Error("bind query parameters", "err", err)
+		return nil, &httpResponseError{Message: "invalid query parameters", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed query parameters", "tableName", reqQuery.TableName, "mode", reqQuery.Mode)
+
+	body := newStreamReader(c, r.config.Database.MaxImportSize)
+
+	report, err := r.services.Database.ImportDatabaseRows(c.Request.Context(), *reqQuery.ImportDatabaseRowsOptions, body)
+	// Rows of the last batch are inserted and committed after the body is read, so the response could be late.
+	body.extendWriteDeadline()
+	if err != nil {
+		maxBytesErr := &http.MaxBytesError{}
+		if errors.As(err, &maxBytesErr) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{
+				Message: fmt.Sprintf("The imported rows are too large. Please send at most %d bytes at once.", maxBytesErr.Limit),
+				Type:    ErrorTypeClient,
+			}
+		}
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("import database rows", "err", err)
+		return nil, &httpResponseError{Message: "import database rows", Type: ErrorTypeServer}
+	}
+
+	logger.Info("imported database rows")
+	return report, nil
+}
+
+type createDatabaseTableFromJSONRequestQuery struct {
+	*service.CreateDatabaseTableFromJSONOptions
+}
+
+// createDatabaseTableFromJSON reads options from query parameters, because request body is a JSON array or NDJSON of sample rows.
+func (r databaseRouter) createDatabaseTableFromJSON(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.createDatabaseTableFromJSON")
+
+	var reqQuery createDatabaseTableFromJSONRequestQuery
+	err := c.ShouldBindQuery(&reqQuery)
+	if err != nil {
+		logger.Error("bind query parameters", "err", err)
+		return nil, &httpResponseError{Message: "invalid query parameters", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed query parameters", "tableName", reqQuery.TableName, "primaryKey", reqQuery.PrimaryKey)
+
+	body := newStreamReader(c, r.config.Database.MaxSampleSize)
+
+	proposedTable, err := r.services.Database.CreateDatabaseTableFromJSON(
+		c.Request.Context(), *reqQuery.CreateDatabaseTableFromJSONOptions, body,
+	)
+	if err != nil {
+		maxBytesErr := &http.MaxBytesError{}
+		if errors.As(err, &maxBytesErr) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{
+				Message: fmt.Sprintf("The sample is too large. Please send at most %d bytes of rows.", maxBytesErr.Limit),
+				Type:    ErrorTypeClient,
+			}
+		}
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("create database table from JSON", "err", err)
+		return nil, &httpResponseError{Message: "create database table from JSON", Type: ErrorTypeServer}
+	}
+
+	logger.Info("proposed database table from JSON", "created", proposedTable.Created)
+	return proposedTable, nil
 }
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/VladPetriv/d2j/internal/service"
+	"github.com/VladPetriv/d2j/pkg/errs"
+	"github.com/gin-gonic/gin"
+)
+
+// exportFileChunkSize is a size of the export file part that is written to the client before the next flush.
+const exportFileChunkSize = 1 << 20
+
+var exportContentTypes = map[service.ExportFormat]string{
+	service.ExportFormatNDJSON:  "application/x-ndjson",
+	service.ExportFormatJSON:    "application/json",
+	service.ExportFormatCSV:     "text/csv; charset=utf-8",
+	service.ExportFormatTSV:     "text/tab-separated-values; charset=utf-8",
+	service.ExportFormatXML:     "application/xml; charset=utf-8",
+	service.ExportFormatParquet: "application/vnd.apache.parquet",
+}
+
+type submitExportJobRequestBody struct {
+	*service.SubmitExportJobOptions
+}
+
+func (r databaseRouter) submitExportJob(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.submitExportJob")
+
+	var reqBody submitExportJobRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+	logger.Debug("parsed request body", "reqBody", reqBody)
+
+	job, err := r.services.Database.SubmitExportJob(c, *reqBody.SubmitExportJobOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("submit export job", "err", err)
+		return nil, &httpResponseError{Message: "submit export job", Type: ErrorTypeServer}
+	}
+
+	logger.Info("submitted export job", "jobId", job.ID)
+	return job, nil
+}
+
+type exportJobRequestBody struct {
+	*service.ExportJobOptions
+}
+
+func (r databaseRouter) getExportJob(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.getExportJob")
+
+	var reqBody exportJobRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+
+	job, err := r.services.Database.GetExportJob(c, *reqBody.ExportJobOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("get export job", "err", err)
+		return nil, &httpResponseError{Message: "get export job", Type: ErrorTypeServer}
+	}
+
+	logger.Info("got export job", "status", job.Status)
+	return job, nil
+}
+
+type cancelExportJobResponseBody struct {
+	Message string `json:"message"`
+}
+
+func (r databaseRouter) cancelExportJob(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.cancelExportJob")
+
+	var reqBody exportJobRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+
+	err = r.services.Database.CancelExportJob(c, *reqBody.ExportJobOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("cancel export job", "err", err)
+		return nil, &httpResponseError{Message: "cancel export job", Type: ErrorTypeServer}
+	}
+
+	logger.Info("canceled export job")
+	return cancelExportJobResponseBody{
+		Message: "The export job will be canceled in a few seconds.",
+	}, nil
+}
+
+func (r databaseRouter) downloadExportJobFile(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.downloadExportJobFile")
+
+	var reqBody exportJobRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+
+	file, err := r.services.Database.OpenExportJobFile(c, *reqBody.ExportJobOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("open export job file", "err", err)
+		return nil, &httpResponseError{Message: "open export job file", Type: ErrorTypeServer}
+	}
+	defer file.Content.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	c.Header("Content-Length", strconv.FormatInt(file.Size, 10))
+
+	// File is written by chunks, because every flush extends the write deadline of large downloads.
+	w := newStreamWriter(c.Writer, exportContentTypes[file.Format])
+	buffer := make([]byte, exportFileChunkSize)
+
+	for {
+		n, err := file.Content.Read(buffer)
+		if n > 0 {
+			_, writeErr := w.Write(buffer[:n])
+			if writeErr == nil {
+				writeErr = w.Flush()
+			}
+			if writeErr != nil {
+				logger.Error("download export job file interrupted", "err", writeErr)
+				c.Abort()
+				return nil, nil
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Error("read export job file", "err", err)
+			c.Abort()
+			return nil, nil
+		}
+	}
+
+	logger.Info("downloaded export job file", "size", file.Size)
+	return nil, nil
+}
+
+func (r databaseRouter) createExportJobEventsToken(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.createExportJobEventsToken")
+
+	var reqBody exportJobRequestBody
+	err := c.ShouldBindJSON(&reqBody)
+	if err != nil {
+		logger.Error("bind request body to json", "err", err)
+		return nil, &httpResponseError{Message: "invalid request body", Type: ErrorTypeClient}
+	}
+
+	token, err := r.services.Database.CreateExportJobEventsToken(c, *reqBody.ExportJobOptions)
+	if err != nil {
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("create export job events token", "err", err)
+		return nil, &httpResponseError{Message: "create export job events token", Type: ErrorTypeServer}
+	}
+
+	logger.Info("created export job events token", "expiresAt", token.ExpiresAt)
+	return token, nil
+}
+
+type watchExportJobRequestQuery struct {
+	service.WatchExportJobOptions
+}
+
+// streamExportJobEvents sends progress of the export job as server-sent events until the job is finished.
+// Options are passed as query parameters, so the stream could be opened by EventSource of the browser.
+// Conversions submitted with async option are export jobs too, so their progress is streamed the same way.
+func (r databaseRouter) streamExportJobEvents(c *gin.Context) (interface{}, *httpResponseError) {
+	logger := r.logger.Named("databaseRouter.streamExportJobEvents")
+
+	var reqQuery watchExportJobRequestQuery
+	err := c.ShouldBindQuery(&reqQuery)
+	if err != nil {
+		logger.Error("bind query parameters", "err", err)
+		return nil, &httpResponseError{Message: "invalid query parameters", Type: ErrorTypeClient}
+	}
+
+	w := newStreamWriter(c.Writer, "text/event-stream")
+
+	// Request context is canceled when client disconnects, which stops watching the job.
+	err = r.services.Database.WatchExportJob(c.Request.Context(), reqQuery.WatchExportJobOptions, func(event service.ExportJobEvent) error {
+		if !c.Writer.Written() {
+			c.Header("Cache-Control", "no-cache")
+		}
+
+		c.SSEvent(string(event.Type), event)
+
+		return w.Flush()
+	})
+	if err != nil {
+		// Status and part of the events are already sent, so it's only possible to interrupt the stream.
+		if c.Writer.Written() {
+			logger.Info("stream export job events interrupted", "err", err)
+			c.Abort()
+			return nil, nil
+		}
+
+		if errors.Is(err, service.ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: "Connection session time expired", Type: ErrorTypeClient}
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, &httpResponseError{Message: err.Error(), Type: ErrorTypeClient}
+		}
+
+		logger.Error("watch export job", "err", err)
+		return nil, &httpResponseError{Message: "watch export job", Type: ErrorTypeServer}
+	}
+
+	logger.Info("streamed export job events")
+	return nil, nil
+}
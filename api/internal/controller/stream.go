@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// streamWriteTimeout is a time given to write the next chunk of streamed response.
+	streamWriteTimeout = time.Minute
+	// streamDeadlineInterval is a minimum time between extensions of the write deadline by writes,
+	// because rows are written by small parts and setting the deadline on every one of them slows streaming down.
+	streamDeadlineInterval = time.Second
+)
+
+// streamWriter writes streamed response to the client.
+// On every write and flush it extends the write deadline, so long streams are not interrupted by server write timeout,
+// even when rows are produced slowly and there are minutes between flushes.
+type streamWriter struct {
+	writer      gin.ResponseWriter
+	controller  *http.ResponseController
+	contentType string
+	// deadlineExtendedAt is a time of the last extension of the write deadline.
+	deadlineExtendedAt time.Time
+}
+
+// newStreamWriter creates a stream writer that sets the content type right before writing the first chunk,
+// so errors that occur before streaming is started could still be sent as JSON.
+func newStreamWriter(writer gin.ResponseWriter, contentType string) *streamWriter {
+	w := &streamWriter{
+		writer:      writer,
+		controller:  http.NewResponseController(writer),
+		contentType: contentType,
+	}
+
+	w.extendWriteDeadline()
+
+	return w
+}
+
+func (w *streamWriter) Write(data []byte) (int, error) {
+	if !w.writer.Written() {
+		w.writer.Header().Set("Content-Type", w.contentType)
+	}
+
+	// Buffered data is sent to the client by a write when buffer is full, so deadline is extended before it.
+	if time.Since(w.deadlineExtendedAt) >= streamDeadlineInterval {
+		w.extendWriteDeadline()
+	}
+
+	return w.writer.Write(data)
+}
+
+func (w *streamWriter) Flush() error {
+	err := w.controller.Flush()
+	if err != nil {
+		return fmt.Errorf("flush response: %w", err)
+	}
+
+	w.extendWriteDeadline()
+
+	return nil
+}
+
+func (w *streamWriter) extendWriteDeadline() {
+	w.deadlineExtendedAt = time.Now()
+
+	// Ignore error, because not all writers support deadlines and the stream could be written without it.
+	_ = w.controller.SetWriteDeadline(w.deadlineExtendedAt.Add(streamWriteTimeout))
+}
+
+// streamReader reads request body, which is processed while it's read, e.g. imported rows.
+// On every read it extends read and write deadlines, so long uploads are not interrupted by server timeouts.
+type streamReader struct {
+	reader     io.Reader
+	controller *http.ResponseController
+}
+
+// newStreamReader creates a stream reader, which fails with *http.MaxBytesError when body is larger than maxSize.
+func newStreamReader(c *gin.Context, maxSize int64) *streamReader {
+	return &streamReader{
+		reader:     http.MaxBytesReader(c.Writer, c.Request.Body, maxSize),
+		controller: http.NewResponseController(c.Writer),
+	}
+}
+
+func (r *streamReader) Read(data []byte) (int, error) {
+	// Ignore errors, because not all connections support deadlines and the body could be read without it.
+	_ = r.controller.SetReadDeadline(time.Now().Add(streamWriteTimeout))
+	_ = r.controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+
+	return r.reader.Read(data)
+}
+
+// extendWriteDeadline gives time to write the response after the rest of processing, which is done after reading.
+func (r *streamReader) extendWriteDeadline() {
+	_ = r.controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+}
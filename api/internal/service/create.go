@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/VladPetriv/d2j/pkg/database"
+	"github.com/VladPetriv/d2j/pkg/errs"
+	"github.com/VladPetriv/d2j/pkg/export"
+)
+
+// maxSampleRows is a number of rows, from which table is inferred. The rest of rows is not read.
+const maxSampleRows = 1000
+
+func (d databaseService) CreateDatabaseTableFromJSON(
+	ctx context.Context, options CreateDatabaseTableFromJSONOptions, r io.Reader,
+) (*ProposedTable, error) {
+	logger := d.logger.Named("databaseService.CreateDatabaseTableFromJSON")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get database client", "err", err)
+		return nil, fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	rows, err := readSampleRows(r)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("read sample rows", "err", err)
+		return nil, fmt.Errorf("read sample rows: %w", err)
+	}
+	logger.Debug("read sample rows", "rowsCount", len(rows))
+
+	table, err := export.InferTable(rows)
+	if err != nil {
+		if errors.Is(err, export.ErrInvalidSample) {
+			logger.Info(err.Error())
+			return nil, ErrInvalidSample
+		}
+
+		logger.Error("infer table", "err", err)
+		return nil, fmt.Errorf("infer table: %w", err)
+	}
+	if options.PrimaryKey != nil {
+		table.PrimaryKey = options.PrimaryKey
+	}
+	logger.Debug("inferred table", "table", table)
+
+	createTableOptions := database.CreateTableOptions{
+		TableName:  options.TableName,
+		Columns:    table.Columns,
+		PrimaryKey: table.PrimaryKey,
+	}
+
+	query, err := databaseClient.BuildCreateTableQuery(createTableOptions)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidTableDefinition) {
+			logger.Info(err.Error())
+			return nil, ErrInvalidTableDefinition
+		}
+		if queryErr := d.handleQueryErrors(err); queryErr != nil {
+			logger.Info(err.Error())
+			return nil, queryErr
+		}
+
+		logger.Error("build create table query", "err", err)
+		return nil, fmt.Errorf("build create table query: %w", err)
+	}
+	logger.Debug("built create table query", "query", query)
+
+	hash := sha256.Sum256([]byte(query.Text))
+	proposedTable := &ProposedTable{
+		Columns:      table.Columns,
+		PrimaryKey:   table.PrimaryKey,
+		DDL:          query.Text,
+		Confirmation: hex.EncodeToString(hash[:]),
+	}
+
+	if options.Confirmation == "" {
+		return proposedTable, nil
+	}
+	// Another sample or table name changes the statement, so it must be reviewed again.
+	if options.Confirmation != proposedTable.Confirmation {
+		logger.Info(ErrInvalidConfirmation.Error())
+		return nil, ErrInvalidConfirmation
+	}
+
+	err = databaseClient.CreateTable(ctx, createTableOptions)
+	if err != nil {
+		// Errors of the statement, e.g. existing table, are caused by user, so they are returned as is.
+		queryErr := &database.QueryError{}
+		if errors.As(err, &queryErr) {
+			logger.Info(err.Error())
+			return nil, errs.New(fmt.Sprintf("The table could not be created: %s", queryErr.Message))
+		}
+
+		logger.Error("create table", "err", err)
+		return nil, fmt.Errorf("create table: %w", err)
+	}
+	proposedTable.Created = true
+
+	return proposedTable, nil
+}
+
+// readSampleRows reads the first maxSampleRows rows of JSON array or newline-delimited JSON.
+func readSampleRows(r io.Reader) ([]json.RawMessage, error) {
+	reader, err := newImportRowsReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []json.RawMessage
+	for len(rows) < maxSampleRows {
+		row, err := reader.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -2,15 +2,24 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/VladPetriv/d2j/pkg/caching"
 	"github.com/VladPetriv/d2j/pkg/database"
 	"github.com/VladPetriv/d2j/pkg/encryption"
 	"github.com/VladPetriv/d2j/pkg/errs"
+	"github.com/VladPetriv/d2j/pkg/export"
+	"github.com/VladPetriv/d2j/pkg/logger"
 	"github.com/google/uuid"
 )
 
@@ -24,12 +33,14 @@ var _ DatabaseService = (*databaseService)(nil)
 func NewDatabaseService(options *Options) *databaseService {
 	return &databaseService{
 		serviceContext: serviceContext{
-			logger:    options.Logger,
-			config:    options.Config,
-			database:  options.Database,
-			cacher:    options.Cacher,
-			hasher:    options.Hasher,
-			encryptor: options.Encryptor,
+			logger:     options.Logger,
+			config:     options.Config,
+			databases:  options.Databases,
+			clientPool: options.ClientPool,
+			cacher:     options.Cacher,
+			hasher:     options.Hasher,
+			encryptor:  options.Encryptor,
+			exportPool: options.ExportPool,
 		},
 	}
 }
@@ -37,13 +48,20 @@ func NewDatabaseService(options *Options) *databaseService {
 func (d databaseService) TestDatabaseConnection(ctx context.Context, options DatabaseConnectionOptions) error {
 	logger := d.logger.Named("databaseService.TestDatabaseConnection")
 
-	_, err := d.database.Connect(database.ConnectionOptions{
+	db, err := d.getDatabase(options.Dialect)
+	if err != nil {
+		logger.Info(err.Error())
+		return err
+	}
+
+	databaseClient, err := db.Connect(database.ConnectionOptions{
 		Host:           options.Host,
 		Port:           options.Port,
 		Username:       options.Username,
 		Password:       options.Password,
 		DatabaseName:   options.DatabaseName,
 		SSLModeEnabled: options.SSLModeEnabled,
+		Path:           options.Path,
 	})
 	if err != nil {
 		if errs.IsExpected(err) {
@@ -56,7 +74,7 @@ func (d databaseService) TestDatabaseConnection(ctx context.Context, options Dat
 	}
 	logger.Debug("connected to database")
 
-	err = d.database.Close()
+	err = databaseClient.Close()
 	if err != nil {
 		logger.Error("close database connection", "err", err)
 		return fmt.Errorf("close database connection: %w", err)
@@ -69,13 +87,20 @@ func (d databaseService) TestDatabaseConnection(ctx context.Context, options Dat
 func (d databaseService) ConnectToDatabase(ctx context.Context, options ConnectToDatabaseOptions) (string, error) {
 	logger := d.logger.Named("databaseService.ConnectToDatabase")
 
-	_, err := d.database.Connect(database.ConnectionOptions{
+	db, err := d.getDatabase(options.DatabaseConnectionOptions.Dialect)
+	if err != nil {
+		logger.Info(err.Error())
+		return "", err
+	}
+
+	databaseClient, err := db.Connect(database.ConnectionOptions{
 		Host:           options.DatabaseConnectionOptions.Host,
 		Port:           options.DatabaseConnectionOptions.Port,
 		Username:       options.DatabaseConnectionOptions.Username,
 		Password:       options.DatabaseConnectionOptions.Password,
 		DatabaseName:   options.DatabaseConnectionOptions.DatabaseName,
 		SSLModeEnabled: options.DatabaseConnectionOptions.SSLModeEnabled,
+		Path:           options.DatabaseConnectionOptions.Path,
 	})
 	if err != nil {
 		logger.Error("connect to database", "err", err)
@@ -84,7 +109,7 @@ func (d databaseService) ConnectToDatabase(ctx context.Context, options ConnectT
 	logger.Debug("connected to database")
 
 	defer func() {
-		err = d.database.Close()
+		err = databaseClient.Close()
 		if err != nil {
 			logger.Error("close database connection", "err", err)
 		} else {
@@ -99,7 +124,23 @@ func (d databaseService) ConnectToDatabase(ctx context.Context, options ConnectT
 	}
 	logger.Debug("generated hash")
 
-	marshalledConnectionOptions, err := json.Marshal(options.DatabaseConnectionOptions)
+	connectionSessionTime, err := time.ParseDuration(options.ConnectionSessionTime)
+	if err != nil || connectionSessionTime <= 0 {
+		logger.Info(ErrInvalidConnectionSessionTime.Error(), "connectionSessionTime", options.ConnectionSessionTime)
+		return "", ErrInvalidConnectionSessionTime
+	}
+	logger.Debug("parsed connection session time")
+
+	if connectionSessionTime > d.config.App.MaxConnectionSessionTime {
+		logger.Info(ErrConnectionSessionTimeTooLong.Error(), "connectionSessionTime", connectionSessionTime)
+		return "", ErrConnectionSessionTimeTooLong
+	}
+
+	marshalledConnectionOptions, err := json.Marshal(connectionSession{
+		DatabaseConnectionOptions: options.DatabaseConnectionOptions,
+		CreatedAt:                 time.Now(),
+		SessionTime:               connectionSessionTime,
+	})
 	if err != nil {
 		logger.Error("marshal connection options to JSON", "err", err)
 		return "", fmt.Errorf("marshal connection options to JSON: %w", err)
@@ -116,13 +157,6 @@ func (d databaseService) ConnectToDatabase(ctx context.Context, options ConnectT
 	}
 	logger.Debug("encrypted connection data")
 
-	connectionSessionTime, err := time.ParseDuration(options.ConnectionSessionTime)
-	if err != nil {
-		logger.Error("parse connection session time", "err", err)
-		return "", fmt.Errorf("parse connection session time: %w", err)
-	}
-	logger.Debug("parsed connection session time")
-
 	err = d.cacher.Write(ctx, caching.WriteOptions{
 		Key:   databaseKey,
 		Value: encryptedConnectionData,
@@ -134,143 +168,1392 @@ func (d databaseService) ConnectToDatabase(ctx context.Context, options ConnectT
 	}
 	logger.Debug("wrote connection data to cache")
 
+	err = d.cacher.AddToSet(ctx, caching.AddToSetOptions{
+		Key:    sessionsIndexKey(options.DatabaseConnectionOptions),
+		Member: databaseKey,
+		TTL:    connectionSessionTime,
+	})
+	if err != nil {
+		logger.Error("add database key to sessions index", "err", err)
+		return "", fmt.Errorf("add database key to sessions index: %w", err)
+	}
+	logger.Debug("added database key to sessions index")
+
+	// Session is already created, so failed pruning is only logged and it's retried on the next connection.
+	err = d.pruneSessionsIndex(ctx, sessionsIndexKey(options.DatabaseConnectionOptions))
+	if err != nil {
+		logger.Error("prune sessions index", "err", err)
+	}
+
 	return databaseKey, nil
 }
 
-func (d databaseService) ListDatabaseTables(ctx context.Context, options ListDatabaseTablesOptions) ([]string, error) {
-	logger := d.logger.Named("databaseService.ListDatabaseTables")
+func (d databaseService) DisconnectFromDatabase(ctx context.Context, options DisconnectFromDatabaseOptions) error {
+	logger := d.logger.Named("databaseService.DisconnectFromDatabase")
+
+	session, err := d.getConnectionSession(ctx, options.DatabaseKey)
+	if err != nil && !errors.Is(err, ErrConnectionSessionTimeExpired) {
+		logger.Error("get database credentials", "err", err)
+		return fmt.Errorf("get database credentials: %w", err)
+	}
+	logger.Debug("got database credentials")
+
+	err = d.cacher.Delete(ctx, options.DatabaseKey)
+	if err != nil {
+		logger.Error("delete connection data from cache", "err", err)
+		return fmt.Errorf("delete connection data from cache: %w", err)
+	}
+	logger.Debug("deleted connection data from cache")
+
+	// Credentials are missing when session is already expired, so its key is removed from the index by pruning.
+	if session != nil {
+		err = d.cacher.RemoveFromSet(ctx, sessionsIndexKey(session.DatabaseConnectionOptions), options.DatabaseKey)
+		if err != nil {
+			logger.Error("remove database key from sessions index", "err", err)
+			return fmt.Errorf("remove database key from sessions index: %w", err)
+		}
+		logger.Debug("removed database key from sessions index")
+	}
+
+	err = d.clientPool.Remove(options.DatabaseKey)
+	if err != nil {
+		logger.Error("remove session client from the pool", "err", err)
+		return fmt.Errorf("remove session client from the pool: %w", err)
+	}
+	logger.Debug("removed session client from the pool")
 
-	databaseConnectionOptions, err := d.getDatabaseCredentials(ctx, options.DatabaseKey)
+	return nil
+}
+
+func (d databaseService) GetDatabaseSession(ctx context.Context, options GetDatabaseSessionOptions) (*DatabaseSession, error) {
+	logger := d.logger.Named("databaseService.GetDatabaseSession")
+
+	session, err := d.getConnectionSession(ctx, options.DatabaseKey)
 	if err != nil {
 		if errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get connection session", "err", err)
+		return nil, fmt.Errorf("get connection session: %w", err)
+	}
+	logger.Debug("got connection session")
+
+	remainingTime, err := d.cacher.TTL(ctx, options.DatabaseKey)
+	if err != nil {
+		if errors.Is(err, caching.ErrResultIsNil) {
 			logger.Info("connection session time expired")
+			return nil, ErrConnectionSessionTimeExpired
+		}
+
+		logger.Error("get connection session ttl", "err", err)
+		return nil, fmt.Errorf("get connection session ttl: %w", err)
+	}
+	logger.Debug("got connection session ttl", "remainingTime", remainingTime)
+
+	return newDatabaseSession(*session, remainingTime), nil
+}
+
+func (d databaseService) ExtendDatabaseSession(ctx context.Context, options ExtendDatabaseSessionOptions) (*DatabaseSession, error) {
+	logger := d.logger.Named("databaseService.ExtendDatabaseSession")
+
+	session, err := d.getConnectionSession(ctx, options.DatabaseKey)
+	if err != nil {
+		if errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
 			return nil, err
 		}
-		logger.Error("get database credentials", "err", err)
-		return nil, fmt.Errorf("get database credentials: %w", err)
+
+		logger.Error("get connection session", "err", err)
+		return nil, fmt.Errorf("get connection session: %w", err)
 	}
-	logger.Debug("got database credentials")
+	logger.Debug("got connection session")
+
+	// Refresh session to its initial time by default.
+	newRemainingTime := session.SessionTime
+
+	if options.ExtendBy != "" {
+		extendBy, err := time.ParseDuration(options.ExtendBy)
+		if err != nil || extendBy <= 0 {
+			logger.Info(ErrInvalidConnectionSessionTime.Error(), "extendBy", options.ExtendBy)
+			return nil, ErrInvalidConnectionSessionTime
+		}
+
+		remainingTime, err := d.cacher.TTL(ctx, options.DatabaseKey)
+		if err != nil {
+			if errors.Is(err, caching.ErrResultIsNil) {
+				logger.Info("connection session time expired")
+				return nil, ErrConnectionSessionTimeExpired
+			}
+
+			logger.Error("get connection session ttl", "err", err)
+			return nil, fmt.Errorf("get connection session ttl: %w", err)
+		}
+		logger.Debug("got connection session ttl", "remainingTime", remainingTime)
+
+		newRemainingTime = remainingTime + extendBy
+	}
+
+	// Maximum is a total lifetime of the session, so it could not be kept alive by repeated extensions.
+	// Sessions created before their creation time was stored have unknown lifetime, so they could not be extended.
+	maxExpiresAt := session.CreatedAt.Add(d.config.App.MaxConnectionSessionTime)
+	if session.CreatedAt.IsZero() || newRemainingTime <= 0 || time.Now().Add(newRemainingTime).After(maxExpiresAt) {
+		logger.Info(ErrConnectionSessionTimeTooLong.Error(), "newRemainingTime", newRemainingTime, "maxExpiresAt", maxExpiresAt)
+		return nil, ErrConnectionSessionTimeTooLong
+	}
+
+	err = d.cacher.Expire(ctx, options.DatabaseKey, newRemainingTime)
+	if err != nil {
+		if errors.Is(err, caching.ErrResultIsNil) {
+			logger.Info("connection session time expired")
+			return nil, ErrConnectionSessionTimeExpired
+		}
+
+		logger.Error("extend connection session ttl", "err", err)
+		return nil, fmt.Errorf("extend connection session ttl: %w", err)
+	}
+	logger.Debug("extended connection session ttl", "newRemainingTime", newRemainingTime)
 
-	databaseClient, err := d.database.Connect(database.ConnectionOptions{
-		Host:           databaseConnectionOptions.Host,
-		Port:           databaseConnectionOptions.Port,
-		Username:       databaseConnectionOptions.Username,
-		Password:       databaseConnectionOptions.Password,
-		DatabaseName:   databaseConnectionOptions.DatabaseName,
-		SSLModeEnabled: databaseConnectionOptions.SSLModeEnabled,
+	// Sessions index must live at least as long as the session to keep it revocable.
+	err = d.cacher.AddToSet(ctx, caching.AddToSetOptions{
+		Key:    sessionsIndexKey(session.DatabaseConnectionOptions),
+		Member: options.DatabaseKey,
+		TTL:    newRemainingTime,
 	})
 	if err != nil {
-		if errs.IsExpected(err) {
+		logger.Error("add database key to sessions index", "err", err)
+		return nil, fmt.Errorf("add database key to sessions index: %w", err)
+	}
+	logger.Debug("extended sessions index ttl")
+
+	return newDatabaseSession(*session, newRemainingTime), nil
+}
+
+func newDatabaseSession(session connectionSession, remainingTime time.Duration) *DatabaseSession {
+	dialect := session.Dialect
+	if dialect == "" {
+		dialect = database.DialectPostgreSQL
+	}
+
+	return &DatabaseSession{
+		Dialect:        dialect,
+		Host:           session.Host,
+		Port:           session.Port,
+		DatabaseName:   session.DatabaseName,
+		Username:       session.Username,
+		Path:           session.Path,
+		SSLModeEnabled: session.SSLModeEnabled,
+		CreatedAt:      session.CreatedAt,
+		ExpiresAt:      time.Now().Add(remainingTime),
+		RemainingTime:  remainingTime.Round(time.Second).String(),
+	}
+}
+
+func (d databaseService) RevokeDatabaseSessions(ctx context.Context, options RevokeDatabaseSessionsOptions) (int, error) {
+	logger := d.logger.Named("databaseService.RevokeDatabaseSessions")
+
+	indexKey := sessionsIndexKey(DatabaseConnectionOptions{
+		Host:         options.Host,
+		DatabaseName: options.DatabaseName,
+		Path:         options.Path,
+	})
+
+	databaseKeys, err := d.cacher.ReadSet(ctx, indexKey)
+	if err != nil {
+		logger.Error("read sessions index", "err", err)
+		return 0, fmt.Errorf("read sessions index: %w", err)
+	}
+	logger.Debug("read sessions index", "sessionsCount", len(databaseKeys))
+
+	// Index could contain keys of expired sessions, so only existing sessions are counted as revoked.
+	revokedCount, err := d.cacher.DeleteMany(ctx, databaseKeys)
+	if err != nil {
+		logger.Error("delete connection data from cache", "err", err)
+		return 0, fmt.Errorf("delete connection data from cache: %w", err)
+	}
+
+	for _, databaseKey := range databaseKeys {
+		err = d.clientPool.Remove(databaseKey)
+		if err != nil {
+			logger.Error("remove session client from the pool", "err", err)
+		}
+	}
+	logger.Debug("deleted all sessions", "revokedCount", revokedCount)
+
+	err = d.cacher.Delete(ctx, indexKey)
+	if err != nil {
+		logger.Error("delete sessions index", "err", err)
+		return 0, fmt.Errorf("delete sessions index: %w", err)
+	}
+	logger.Debug("deleted sessions index")
+
+	return revokedCount, nil
+}
+
+// pruneSessionsIndex removes keys of expired sessions from the index, so it does not grow with every connection.
+// Sessions that expire are not removed from the index by cache, because it does not notify about expired keys.
+func (d databaseService) pruneSessionsIndex(ctx context.Context, indexKey string) error {
+	databaseKeys, err := d.cacher.ReadSet(ctx, indexKey)
+	if err != nil {
+		return fmt.Errorf("read sessions index: %w", err)
+	}
+
+	for _, databaseKey := range databaseKeys {
+		_, err := d.cacher.TTL(ctx, databaseKey)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, caching.ErrResultIsNil) {
+			return fmt.Errorf("get connection session ttl: %w", err)
+		}
+
+		err = d.cacher.RemoveFromSet(ctx, indexKey, databaseKey)
+		if err != nil {
+			return fmt.Errorf("remove database key from sessions index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sessionsIndexKey returns a cache key of the set that contains database keys of all sessions for the same database.
+func sessionsIndexKey(options DatabaseConnectionOptions) string {
+	hash := sha256.Sum256([]byte(strings.Join(
+		[]string{strings.ToLower(options.Host), options.DatabaseName, options.Path}, "\x00",
+	)))
+
+	return "sessions:" + hex.EncodeToString(hash[:])
+}
+
+func (d databaseService) ListDatabaseSchemas(ctx context.Context, options ListDatabaseSchemasOptions) ([]string, error) {
+	logger := d.logger.Named("databaseService.ListDatabaseSchemas")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
 			logger.Info(err.Error())
-			return nil, d.handleConnectionErrors(err)
+			return nil, err
 		}
 
-		logger.Error("connect to database", "err", err)
-		return nil, fmt.Errorf("connect to database: %w", err)
+		logger.Error("get database client", "err", err)
+		return nil, fmt.Errorf("get database client: %w", err)
 	}
-	logger.Debug("connected to database")
+	defer release()
+	logger.Debug("got database client")
+
+	schemas, err := databaseClient.ListSchemas(ctx)
+	if err != nil {
+		logger.Error("list database schemas", "err", err)
+		return nil, fmt.Errorf("list database schemas: %w", err)
+	}
+	logger.Debug("got database schemas", "schemas", schemas)
+
+	return schemas, nil
+}
+
+func (d databaseService) ListDatabaseTables(ctx context.Context, options ListDatabaseTablesOptions) ([]DatabaseTable, error) {
+	logger := d.logger.Named("databaseService.ListDatabaseTables")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get database client", "err", err)
+		return nil, fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
 
-	databaseTables, err := databaseClient.ListTables()
+	databaseTables, err := databaseClient.ListTables(ctx, options.Schema)
 	if err != nil {
 		logger.Error("list database tables", "err", err)
 		return nil, fmt.Errorf("list database tables: %w", err)
 	}
 	logger.Debug("got database tables", "databaseTables", databaseTables)
 
-	tableNames := make([]string, len(databaseTables))
+	tables := make([]DatabaseTable, 0, len(databaseTables))
+
+	for _, table := range databaseTables {
+		matches, err := matchTableName(table.TableName, options.Include, options.Exclude)
+		if err != nil {
+			logger.Info(err.Error())
+			return nil, err
+		}
 
-	for i := range tableNames {
-		tableNames[i] = databaseTables[i].TableName
+		if matches {
+			tables = append(tables, DatabaseTable{Name: table.TableName, Kind: table.Kind})
+		}
 	}
-	logger.Debug("converted database tables to slice of strings", "tableNames", tableNames)
+	logger.Debug("filtered database tables", "tables", tables)
 
-	return tableNames, nil
+	return tables, nil
 }
 
-func (d databaseService) ConvertDatabaseResultToJSON(ctx context.Context, options ConvertDatabaseResultToJSONOptions) (string, error) {
-	logger := d.logger.Named("databaseService.ConvertDatabaseResultToJSON")
+func (d databaseService) DescribeDatabaseTable(ctx context.Context, options DescribeDatabaseTableOptions) (*database.TableDescription, error) {
+	logger := d.logger.Named("databaseService.DescribeDatabaseTable")
 
-	databaseConnectionOptions, err := d.getDatabaseCredentials(ctx, options.DatabaseKey)
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
 	if err != nil {
-		logger.Error("get database credentials", "err", err)
-		return "", fmt.Errorf("get database credentials: %w", err)
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get database client", "err", err)
+		return nil, fmt.Errorf("get database client: %w", err)
 	}
-	logger.Debug("got database credentials")
+	defer release()
+	logger.Debug("got database client")
 
-	databaseClient, err := d.database.Connect(database.ConnectionOptions{
-		Host:           databaseConnectionOptions.Host,
-		Port:           databaseConnectionOptions.Port,
-		Username:       databaseConnectionOptions.Username,
-		Password:       databaseConnectionOptions.Password,
-		DatabaseName:   databaseConnectionOptions.DatabaseName,
-		SSLModeEnabled: databaseConnectionOptions.SSLModeEnabled,
-	})
+	description, err := databaseClient.DescribeTable(ctx, options.TableName)
 	if err != nil {
-		if errs.IsExpected(err) {
+		if queryErr := d.handleQueryErrors(err); queryErr != nil {
 			logger.Info(err.Error())
-			return "", d.handleConnectionErrors(err)
+			return nil, queryErr
 		}
-		logger.Error("connect to database", "err", err)
-		return "", fmt.Errorf("connect to database: %w", err)
+
+		logger.Error("describe database table", "err", err)
+		return nil, fmt.Errorf("describe database table: %w", err)
 	}
-	logger.Debug("connected to database")
+	logger.Debug("got table description", "description", description)
+
+	return description, nil
+}
+
+func (d databaseService) GenerateDatabaseTableJSONSchema(
+	ctx context.Context, options GenerateDatabaseTableJSONSchemaOptions,
+) (json.RawMessage, error) {
+	logger := d.logger.Named("databaseService.GenerateDatabaseTableJSONSchema")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
 
-	query := databaseClient.BuildQuery(database.BuildQueryOptions{
-		TableName: options.TableName,
-		Limit:     options.Limit,
-		Fields:    options.Fields,
-		Where:     options.Where,
+		logger.Error("get database client", "err", err)
+		return nil, fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	// Query is built only to get keys of the rows, which are the same as in ConvertDatabaseResultToJSON.
+	query, err := d.buildConversionQuery(ctx, databaseClient, ConvertDatabaseResultToJSONOptions{
+		DatabaseKey: options.DatabaseKey,
+		TableName:   options.TableName,
+		Fields:      options.Fields,
 	})
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("build conversion query", "err", err)
+		return nil, fmt.Errorf("build conversion query: %w", err)
+	}
 	logger.Debug("built query", "query", query)
 
-	databaseResult, err := databaseClient.ExecuteQuery(query)
+	description, err := databaseClient.DescribeTable(ctx, options.TableName)
 	if err != nil {
-		logger.Error("execute query", "err", err)
-		return "", fmt.Errorf("execute query: %w", err)
+		if queryErr := d.handleQueryErrors(err); queryErr != nil {
+			logger.Info(err.Error())
+			return nil, queryErr
+		}
+
+		logger.Error("describe database table", "err", err)
+		return nil, fmt.Errorf("describe database table: %w", err)
 	}
-	logger.Debug("got database result", "databaseResult", databaseResult)
+	logger.Debug("got table description", "description", description)
 
-	JSONResult := "[ "
+	schema, err := export.BuildJSONSchema(description, query.Columns)
+	if err != nil {
+		logger.Error("build json schema", "err", err)
+		return nil, fmt.Errorf("build json schema: %w", err)
+	}
+	logger.Debug("built json schema", "schema", string(schema))
 
-	for index, row := range databaseResult {
-		// Do not add comma for the last slice element to get valid JSON format.
-		if index == len(databaseResult)-1 {
-			JSONResult += fmt.Sprintf("%s\n", row)
+	return schema, nil
+}
 
-			continue
+func (d databaseService) GenerateDatabaseTableCode(ctx context.Context, options GenerateDatabaseTableCodeOptions) (string, error) {
+	logger := d.logger.Named("databaseService.GenerateDatabaseTableCode")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return "", err
 		}
 
-		JSONResult += fmt.Sprintf("%s,\n", row)
+		logger.Error("get database client", "err", err)
+		return "", fmt.Errorf("get database client: %w", err)
 	}
+	defer release()
+	logger.Debug("got database client")
 
-	JSONResult += " ]"
-	logger.Debug("converted database result to JSON", "JSONResult", JSONResult)
+	// Query is built only to get keys of the rows, so generated types match exported JSON.
+	query, err := d.buildConversionQuery(ctx, databaseClient, ConvertDatabaseResultToJSONOptions{
+		DatabaseKey: options.DatabaseKey,
+		TableName:   options.TableName,
+		Fields:      options.Fields,
+	})
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return "", err
+		}
 
-	return JSONResult, nil
-}
+		logger.Error("build conversion query", "err", err)
+		return "", fmt.Errorf("build conversion query: %w", err)
+	}
+	logger.Debug("built query", "query", query)
 
-func (d databaseService) handleConnectionErrors(err error) error {
-	if errs.HasAnyGivenMessage(err, database.ErrDatabaseDoesNotExists.Error()) {
-		return ErrDatabaseDoesNotExists
+	description, err := databaseClient.DescribeTable(ctx, options.TableName)
+	if err != nil {
+		if queryErr := d.handleQueryErrors(err); queryErr != nil {
+			logger.Info(err.Error())
+			return "", queryErr
+		}
+
+		logger.Error("describe database table", "err", err)
+		return "", fmt.Errorf("describe database table: %w", err)
 	}
-	if errs.HasAnyGivenMessage(err, database.ErrInvalidUsername.Error()) {
-		return ErrInvalidUsername
+	logger.Debug("got table description", "description", description)
+
+	codegenOptions := export.CodegenOptions{
+		Columns:     query.Columns,
+		TypeName:    options.TypeName,
+		PackageName: options.PackageName,
 	}
-	if errs.HasAnyGivenMessage(err, database.ErrInvalidHost.Error()) {
-		return ErrInvalidHost
+
+	var code strings.Builder
+	switch options.Language {
+	case CodeLanguageTypeScript:
+		err = export.RenderTypeScriptInterface(&code, description, codegenOptions)
+	default:
+		err = export.RenderGoStruct(&code, description, codegenOptions)
 	}
-	if errs.HasAnyGivenMessage(err, database.ErrInvalidPort.Error()) {
-		return ErrInvalidPort
+	if err != nil {
+		if errors.Is(err, export.ErrInvalidIdentifier) {
+			logger.Info(err.Error())
+			return "", ErrInvalidIdentifier
+		}
+
+		logger.Error("render code", "err", err, "language", options.Language)
+		return "", fmt.Errorf("render code: %w", err)
 	}
-	if errs.HasAnyGivenMessage(err, database.ErrNoAccess.Error()) {
-		return ErrNoAccessToDatabase
+	logger.Debug("generated code", "language", options.Language)
+
+	return code.String(), nil
+}
+
+func (d databaseService) ConvertDatabaseResultToJSON(ctx context.Context, options ConvertDatabaseResultToJSONOptions) (*ConvertedDatabaseResult, error) {
+	logger := d.logger.Named("databaseService.ConvertDatabaseResultToJSON")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get database client", "err", err)
+		return nil, fmt.Errorf("get database client: %w", err)
 	}
+	defer release()
+	logger.Debug("got database client")
 
-	return err
+	query, err := d.buildConversionQuery(ctx, databaseClient, options)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("build conversion query", "err", err)
+		return nil, fmt.Errorf("build conversion query: %w", err)
+	}
+	logger.Debug("built query", "query", query)
+
+	databaseResult, err := databaseClient.ExecuteQuery(ctx, query)
+	if err != nil {
+		logger.Error("execute query", "err", err)
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+	logger.Debug("got database result", "databaseResult", databaseResult)
+
+	JSONResult := joinJSONRows(databaseResult)
+	logger.Debug("converted database result to JSON", "JSONResult", JSONResult)
+
+	// Full page means that there could be more rows, so the cursor of the next page is returned.
+	var nextCursor string
+	if options.Limit != 0 && len(databaseResult) == options.Limit {
+		nextCursor, err = query.NextCursor([]byte(databaseResult[len(databaseResult)-1]))
+		if err != nil {
+			logger.Error("build next cursor", "err", err)
+			return nil, fmt.Errorf("build next cursor: %w", err)
+		}
+		logger.Debug("built next cursor", "nextCursor", nextCursor)
+	}
+
+	return &ConvertedDatabaseResult{
+		Result:     JSONResult,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func (d databaseService) ConvertCustomQueryResultToJSON(ctx context.Context, options ConvertCustomQueryResultToJSONOptions) (string, error) {
+	logger := d.logger.Named("databaseService.ConvertCustomQueryResultToJSON")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return "", err
+		}
+
+		logger.Error("get database client", "err", err)
+		return "", fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	databaseResult, err := databaseClient.ExecuteReadOnlyQuery(ctx, database.ReadOnlyQueryOptions{
+		Text:    options.Query,
+		Limit:   options.Limit,
+		Timeout: d.config.Database.CustomQueryTimeout,
+	})
+	if err != nil {
+		queryErr := &database.QueryError{}
+		switch {
+		case errors.Is(err, database.ErrInvalidQuery):
+			logger.Info(err.Error())
+			return "", ErrInvalidCustomQuery
+
+		case errors.Is(err, database.ErrQueryTimeout):
+			logger.Info(err.Error())
+			return "", ErrCustomQueryTimeout
+
+		// Errors of the query are caused by user, so they are returned as is to help fixing the query.
+		case errors.As(err, &queryErr):
+			logger.Info(err.Error())
+			return "", errs.New(fmt.Sprintf("The query failed: %s", queryErr.Message))
+		}
+
+		logger.Error("execute read-only query", "err", err)
+		return "", fmt.Errorf("execute read-only query: %w", err)
+	}
+	logger.Debug("got custom query result", "rowsCount", len(databaseResult))
+
+	return joinJSONRows(databaseResult), nil
+}
+
+// joinJSONRows joins rows, which are JSON objects, into JSON array with a row per line.
+func joinJSONRows(rows []string) string {
+	JSONResult := "[ "
+
+	for index, row := range rows {
+		// Do not add comma for the last slice element to get valid JSON format.
+		if index == len(rows)-1 {
+			JSONResult += fmt.Sprintf("%s\n", row)
+
+			continue
+		}
+
+		JSONResult += fmt.Sprintf("%s,\n", row)
+	}
+
+	JSONResult += " ]"
+
+	return JSONResult
+}
+
+func (d databaseService) ConvertDatabaseResultToYAML(ctx context.Context, options ConvertDatabaseResultToDocumentOptions) (string, error) {
+	return d.convertDatabaseResultToDocument(ctx, d.logger.Named("databaseService.ConvertDatabaseResultToYAML"), options, export.RenderYAML)
+}
+
+func (d databaseService) ConvertDatabaseResultToTOML(ctx context.Context, options ConvertDatabaseResultToDocumentOptions) (string, error) {
+	return d.convertDatabaseResultToDocument(ctx, d.logger.Named("databaseService.ConvertDatabaseResultToTOML"), options, export.RenderTOML)
+}
+
+// documentRenderer renders rows, which are JSON objects, as a single document.
+type documentRenderer func(w io.Writer, rows [][]byte, options export.DocumentOptions) error
+
+// convertDatabaseResultToDocument selects table rows and renders them with the given renderer.
+func (d databaseService) convertDatabaseResultToDocument(
+	ctx context.Context, logger logger.Logger, options ConvertDatabaseResultToDocumentOptions, render documentRenderer,
+) (string, error) {
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return "", err
+		}
+
+		logger.Error("get database client", "err", err)
+		return "", fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	query, err := d.buildConversionQuery(ctx, databaseClient, options.ConvertDatabaseResultToJSONOptions)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return "", err
+		}
+
+		logger.Error("build conversion query", "err", err)
+		return "", fmt.Errorf("build conversion query: %w", err)
+	}
+	logger.Debug("built query", "query", query)
+
+	if options.KeyColumn != "" && !slices.Contains(query.Columns, options.KeyColumn) {
+		logger.Info(ErrColumnDoesNotExist.Error(), "keyColumn", options.KeyColumn)
+		return "", ErrColumnDoesNotExist
+	}
+
+	databaseResult, err := databaseClient.ExecuteQuery(ctx, query)
+	if err != nil {
+		logger.Error("execute query", "err", err)
+		return "", fmt.Errorf("execute query: %w", err)
+	}
+	logger.Debug("got database result", "rowsCount", len(databaseResult))
+
+	rows := make([][]byte, len(databaseResult))
+	for i, row := range databaseResult {
+		rows[i] = []byte(row)
+	}
+
+	// Rows list is named by the table, because some formats do not support top-level lists.
+	rootKey := options.TableName
+	if table, err := database.ParseTableName(options.TableName); err == nil {
+		rootKey = table.Name
+	}
+
+	var result strings.Builder
+	err = render(&result, rows, export.DocumentOptions{
+		Columns:   query.Columns,
+		KeyColumn: options.KeyColumn,
+		RootKey:   rootKey,
+	})
+	if err != nil {
+		if renderErr := handleRenderErrors(err); renderErr != nil {
+			logger.Info(err.Error())
+			return "", renderErr
+		}
+
+		logger.Error("render document", "err", err)
+		return "", fmt.Errorf("render document: %w", err)
+	}
+	logger.Debug("rendered document")
+
+	return result.String(), nil
+}
+
+// handleRenderErrors converts expected errors of rendering into service errors.
+// It returns nil when error is unexpected.
+func handleRenderErrors(err error) error {
+	if errors.Is(err, export.ErrDuplicateKey) {
+		return ErrDuplicateKey
+	}
+	if errors.Is(err, export.ErrInvalidKey) {
+		return ErrInvalidKey
+	}
+	if errors.Is(err, export.ErrUnsupportedValue) {
+		return ErrUnsupportedValue
+	}
+
+	return nil
+}
+
+// streamFlushRowsInterval is a number of rows after which streamed data is flushed to the client.
+const streamFlushRowsInterval = 500
+
+// flusher is implemented by writers that buffer streamed data, e.g. HTTP response writers.
+type flusher interface {
+	Flush() error
+}
+
+// rowsCounter is implemented by writers that track progress of streaming, e.g. export files.
+type rowsCounter interface {
+	countRows(count int)
+}
+
+func (d databaseService) StreamDatabaseResultToJSON(ctx context.Context, options StreamDatabaseResultToJSONOptions, w io.Writer) error {
+	logger := d.logger.Named("databaseService.StreamDatabaseResultToJSON")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("get database client", "err", err)
+		return fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	query, err := d.buildConversionQuery(ctx, databaseClient, options.ConvertDatabaseResultToJSONOptions)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("build conversion query", "err", err)
+		return fmt.Errorf("build conversion query: %w", err)
+	}
+	logger.Debug("built query", "query", query)
+
+	rowsCount, err := streamJSONRows(ctx, databaseClient, query, options.Format, w)
+	if err != nil {
+		logger.Error("stream JSON rows", "err", err, "rowsCount", rowsCount)
+		return fmt.Errorf("stream JSON rows: %w", err)
+	}
+	logger.Debug("streamed query result", "rowsCount", rowsCount)
+
+	return nil
+}
+
+// streamJSONRows writes rows of the query as JSON array or newline-delimited JSON and flushes the writer at the end.
+// It returns a number of written rows.
+func streamJSONRows(ctx context.Context, streamer rowsStreamer, query database.Query, format StreamFormat, w io.Writer) (int, error) {
+	rowsCount, err := streamRows(ctx, streamer, query, w, func(row []byte, position int) error {
+		_, err := io.WriteString(w, streamRowPrefix(format, position))
+		if err != nil {
+			return fmt.Errorf("write row prefix: %w", err)
+		}
+
+		_, err = w.Write(row)
+		if err != nil {
+			return fmt.Errorf("write row: %w", err)
+		}
+
+		// Every NDJSON row must be terminated by a new line.
+		if format != StreamFormatArray {
+			_, err = io.WriteString(w, "\n")
+			if err != nil {
+				return fmt.Errorf("write row ending: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rowsCount, fmt.Errorf("stream query: %w", err)
+	}
+
+	if format == StreamFormatArray {
+		ending := "\n]\n"
+		if rowsCount == 0 {
+			ending = "[]\n"
+		}
+
+		_, err = io.WriteString(w, ending)
+		if err != nil {
+			return rowsCount, fmt.Errorf("write array ending: %w", err)
+		}
+	}
+
+	err = flush(w)
+	if err != nil {
+		return rowsCount, fmt.Errorf("flush rows: %w", err)
+	}
+
+	return rowsCount, nil
+}
+
+func (d databaseService) StreamDatabaseResultToCSV(ctx context.Context, options StreamDatabaseResultToCSVOptions, w io.Writer) error {
+	logger := d.logger.Named("databaseService.StreamDatabaseResultToCSV")
+
+	csvOptions, err := buildCSVOptions(options)
+	if err != nil {
+		logger.Info(err.Error())
+		return err
+	}
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("get database client", "err", err)
+		return fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	query, err := d.buildConversionQuery(ctx, databaseClient, options.ConvertDatabaseResultToJSONOptions)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("build conversion query", "err", err)
+		return fmt.Errorf("build conversion query: %w", err)
+	}
+	logger.Debug("built query", "query", query)
+
+	csvOptions.Columns = query.Columns
+	csvWriter := export.NewCSVWriter(w, csvOptions)
+
+	err = csvWriter.WriteHeader()
+	if err != nil {
+		logger.Error("write csv header", "err", err)
+		return fmt.Errorf("write csv header: %w", err)
+	}
+
+	rowsCount, err := streamRows(ctx, databaseClient, query, w, func(row []byte, _ int) error {
+		return csvWriter.WriteRow(row)
+	})
+	if err != nil {
+		logger.Error("stream query", "err", err, "rowsCount", rowsCount)
+		return fmt.Errorf("stream query: %w", err)
+	}
+	logger.Debug("streamed query result", "rowsCount", rowsCount)
+
+	err = flush(w)
+	if err != nil {
+		logger.Error("flush rows", "err", err)
+		return fmt.Errorf("flush rows: %w", err)
+	}
+
+	return nil
+}
+
+func (d databaseService) StreamDatabaseResultToXML(ctx context.Context, options StreamDatabaseResultToXMLOptions, w io.Writer) error {
+	logger := d.logger.Named("databaseService.StreamDatabaseResultToXML")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("get database client", "err", err)
+		return fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	query, err := d.buildConversionQuery(ctx, databaseClient, options.ConvertDatabaseResultToJSONOptions)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("build conversion query", "err", err)
+		return fmt.Errorf("build conversion query: %w", err)
+	}
+	logger.Debug("built query", "query", query)
+
+	for _, column := range options.AttributeColumns {
+		if !slices.Contains(query.Columns, column) {
+			logger.Info(ErrColumnDoesNotExist.Error(), "attributeColumn", column)
+			return ErrColumnDoesNotExist
+		}
+	}
+
+	xmlOptions := export.XMLOptions{
+		Columns:          query.Columns,
+		RootElement:      "rows",
+		RowElement:       "row",
+		AttributeColumns: options.AttributeColumns,
+	}
+	if options.RootElement != "" {
+		xmlOptions.RootElement = options.RootElement
+	}
+	if options.RowElement != "" {
+		xmlOptions.RowElement = options.RowElement
+	}
+
+	xmlWriter, err := export.NewXMLWriter(w, xmlOptions)
+	if err != nil {
+		if errors.Is(err, export.ErrInvalidXMLName) {
+			logger.Info(err.Error())
+			return ErrInvalidXMLName
+		}
+
+		logger.Error("create xml writer", "err", err)
+		return fmt.Errorf("create xml writer: %w", err)
+	}
+
+	err = xmlWriter.WriteStart()
+	if err != nil {
+		logger.Error("write xml start", "err", err)
+		return fmt.Errorf("write xml start: %w", err)
+	}
+
+	rowsCount, err := streamRows(ctx, databaseClient, query, w, func(row []byte, _ int) error {
+		return xmlWriter.WriteRow(row)
+	})
+	if err != nil {
+		logger.Error("stream query", "err", err, "rowsCount", rowsCount)
+		return fmt.Errorf("stream query: %w", err)
+	}
+	logger.Debug("streamed query result", "rowsCount", rowsCount)
+
+	err = xmlWriter.WriteEnd()
+	if err != nil {
+		logger.Error("write xml end", "err", err)
+		return fmt.Errorf("write xml end: %w", err)
+	}
+
+	err = flush(w)
+	if err != nil {
+		logger.Error("flush rows", "err", err)
+		return fmt.Errorf("flush rows: %w", err)
+	}
+
+	return nil
+}
+
+func (d databaseService) StreamDatabaseResultToParquet(ctx context.Context, options StreamDatabaseResultToParquetOptions, w io.Writer) error {
+	logger := d.logger.Named("databaseService.StreamDatabaseResultToParquet")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("get database client", "err", err)
+		return fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	query, err := d.buildConversionQuery(ctx, databaseClient, options.ConvertDatabaseResultToJSONOptions)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("build conversion query", "err", err)
+		return fmt.Errorf("build conversion query: %w", err)
+	}
+	logger.Debug("built query", "query", query)
+
+	// Parquet schema must be known before the first row, so column types are taken from the table description.
+	description, err := databaseClient.DescribeTable(ctx, options.TableName)
+	if err != nil {
+		if queryErr := d.handleQueryErrors(err); queryErr != nil {
+			logger.Info(err.Error())
+			return queryErr
+		}
+
+		logger.Error("describe database table", "err", err)
+		return fmt.Errorf("describe database table: %w", err)
+	}
+	logger.Debug("got table description", "description", description)
+
+	dataTypes := make(map[string]string, len(description.Columns))
+	for _, column := range description.Columns {
+		dataTypes[column.Name] = column.DataType
+	}
+
+	parquetOptions := export.ParquetOptions{
+		Columns:      make([]export.ParquetColumn, len(query.Columns)),
+		RowGroupSize: DefaultParquetRowGroupSize,
+	}
+	for i, column := range query.Columns {
+		parquetOptions.Columns[i] = export.ParquetColumnFromSQLType(column, dataTypes[column])
+	}
+	if options.RowGroupSize != 0 {
+		parquetOptions.RowGroupSize = options.RowGroupSize
+	}
+	logger.Debug("built parquet columns", "columns", parquetOptions.Columns)
+
+	parquetWriter := export.NewParquetWriter(w, parquetOptions)
+
+	rowsCount, err := streamRows(ctx, databaseClient, query, w, func(row []byte, _ int) error {
+		return parquetWriter.WriteRow(row)
+	})
+	if err != nil {
+		if errors.Is(err, export.ErrUnsupportedValue) {
+			logger.Info(err.Error(), "rowsCount", rowsCount)
+			return ErrUnsupportedValue
+		}
+
+		logger.Error("stream query", "err", err, "rowsCount", rowsCount)
+		return fmt.Errorf("stream query: %w", err)
+	}
+	logger.Debug("streamed query result", "rowsCount", rowsCount)
+
+	err = parquetWriter.Close()
+	if err != nil {
+		logger.Error("close parquet writer", "err", err)
+		return fmt.Errorf("close parquet writer: %w", err)
+	}
+
+	err = flush(w)
+	if err != nil {
+		logger.Error("flush rows", "err", err)
+		return fmt.Errorf("flush rows: %w", err)
+	}
+
+	return nil
+}
+
+// buildCSVOptions converts request options into CSV writer options, applying defaults of the format.
+func buildCSVOptions(options StreamDatabaseResultToCSVOptions) (export.CSVOptions, error) {
+	csvOptions := export.CSVOptions{
+		Delimiter: ',',
+		QuoteMode: export.QuoteMinimal,
+	}
+
+	if options.Format == CSVFormatTSV {
+		csvOptions.Delimiter = '\t'
+		csvOptions.QuoteMode = export.QuoteNone
+		csvOptions.NullValue = `\N`
+	}
+
+	if options.Delimiter != "" {
+		delimiter := []rune(options.Delimiter)
+		if len(delimiter) != 1 || strings.ContainsRune("\"\r\n", delimiter[0]) || delimiter[0] == utf8.RuneError {
+			return export.CSVOptions{}, ErrInvalidDelimiter
+		}
+
+		csvOptions.Delimiter = delimiter[0]
+	}
+	if options.QuoteMode != "" {
+		csvOptions.QuoteMode = options.QuoteMode
+	}
+	if options.NullValue != nil {
+		csvOptions.NullValue = *options.NullValue
+	}
+
+	return csvOptions, nil
+}
+
+// rowsStreamer runs query and passes its rows one by one, it's implemented by database client and snapshot.
+type rowsStreamer interface {
+	StreamQuery(ctx context.Context, query database.Query, handleRow func(row []byte) error) error
+}
+
+// streamRows streams query result into writeRow and periodically flushes the writer, if it's possible.
+// It returns a number of written rows.
+func streamRows(
+	ctx context.Context, streamer rowsStreamer, query database.Query, w io.Writer,
+	writeRow func(row []byte, position int) error,
+) (int, error) {
+	var rowsCount int
+
+	counter, countsRows := w.(rowsCounter)
+
+	err := streamer.StreamQuery(ctx, query, func(row []byte) error {
+		err := writeRow(row, rowsCount)
+		if err != nil {
+			return err
+		}
+
+		rowsCount++
+
+		if countsRows {
+			counter.countRows(rowsCount)
+		}
+
+		if rowsCount%streamFlushRowsInterval == 0 {
+			err = flush(w)
+			if err != nil {
+				return fmt.Errorf("flush rows: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	return rowsCount, err
+}
+
+// flush flushes the writer when it buffers data.
+func flush(w io.Writer) error {
+	flushWriter, ok := w.(flusher)
+	if !ok {
+		return nil
+	}
+
+	return flushWriter.Flush()
+}
+
+func (d databaseService) ListConnectionPoolStats(ctx context.Context) []ConnectionPoolStats {
+	logger := d.logger.Named("databaseService.ListConnectionPoolStats")
+
+	clientsStats := d.clientPool.Stats()
+	logger.Debug("got client pool stats", "clientsStats", clientsStats)
+
+	stats := make([]ConnectionPoolStats, len(clientsStats))
+
+	for i, clientStats := range clientsStats {
+		stats[i] = ConnectionPoolStats{
+			SessionFingerprint: clientStats.KeyFingerprint,
+			CreatedAt:          clientStats.CreatedAt,
+			LastUsedAt:         clientStats.LastUsedAt,
+			MaxOpenConnections: clientStats.DBStats.MaxOpenConnections,
+			OpenConnections:    clientStats.DBStats.OpenConnections,
+			InUse:              clientStats.DBStats.InUse,
+			Idle:               clientStats.DBStats.Idle,
+			WaitCount:          clientStats.DBStats.WaitCount,
+			WaitDuration:       clientStats.DBStats.WaitDuration.String(),
+		}
+	}
+
+	return stats
+}
+
+// streamRowPrefix returns a string that should be written before the row with the given index.
+func streamRowPrefix(format StreamFormat, index int) string {
+	if format != StreamFormatArray {
+		return ""
+	}
+	if index == 0 {
+		return "[\n"
+	}
+
+	return ",\n"
+}
+
+// getDatabaseClient connects to the database by credentials stored for the given database key.
+// Client is leased from the pool, so release must be called when the client is not used anymore.
+func (d databaseService) getDatabaseClient(ctx context.Context, databaseKey string) (database.DBClient, func(), error) {
+	logger := d.logger.Named("databaseService.getDatabaseClient")
+
+	session, err := d.getConnectionSession(ctx, databaseKey)
+	if err != nil {
+		if errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info("connection session time expired")
+
+			// Session is over, so its connections are not needed anymore.
+			err := d.clientPool.Remove(databaseKey)
+			if err != nil {
+				logger.Error("remove expired session client from the pool", "err", err)
+			}
+
+			return nil, nil, ErrConnectionSessionTimeExpired
+		}
+
+		logger.Error("get database credentials", "err", err)
+		return nil, nil, fmt.Errorf("get database credentials: %w", err)
+	}
+	logger.Debug("got database credentials")
+
+	// Connections are opened once per session and reused by all its requests.
+	databaseClient, release, err := d.clientPool.Get(databaseKey, func() (database.DBClient, error) {
+		db, err := d.getDatabase(session.Dialect)
+		if err != nil {
+			return nil, err
+		}
+
+		return db.Connect(database.ConnectionOptions{
+			Host:               session.Host,
+			Port:               session.Port,
+			Username:           session.Username,
+			Password:           session.Password,
+			DatabaseName:       session.DatabaseName,
+			SSLModeEnabled:     session.SSLModeEnabled,
+			Path:               session.Path,
+			MaxOpenConnections: d.config.Database.MaxOpenConnections,
+			MaxIdleConnections: d.config.Database.MaxIdleConnections,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrTooManyClients) {
+			logger.Info(err.Error())
+			return nil, nil, ErrTooManyConnections
+		}
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, nil, d.handleConnectionErrors(err)
+		}
+
+		logger.Error("connect to database", "err", err)
+		return nil, nil, fmt.Errorf("connect to database: %w", err)
+	}
+	logger.Debug("connected to database")
+
+	return databaseClient, release, nil
+}
+
+// buildConversionQuery builds a query that selects table rows as JSON objects by conversion options.
+// Materialized view is refreshed before building the query when it's requested.
+func (d databaseService) buildConversionQuery(ctx context.Context, databaseClient database.DBClient, options ConvertDatabaseResultToJSONOptions) (database.Query, error) {
+	logger := d.logger.Named("databaseService.buildConversionQuery")
+
+	if options.Where != "" && !d.config.App.AllowRawWhere {
+		logger.Info(ErrRawWhereDisabled.Error())
+		return database.Query{}, ErrRawWhereDisabled
+	}
+
+	if options.RefreshMaterializedView {
+		err := databaseClient.RefreshMaterializedView(ctx, options.TableName)
+		if err != nil {
+			if queryErr := d.handleQueryErrors(err); queryErr != nil {
+				logger.Info(err.Error())
+				return database.Query{}, queryErr
+			}
+
+			logger.Error("refresh materialized view", "err", err)
+			return database.Query{}, fmt.Errorf("refresh materialized view: %w", err)
+		}
+		logger.Debug("refreshed materialized view")
+	}
+
+	query, err := databaseClient.BuildQuery(database.BuildQueryOptions{
+		TableName:   options.TableName,
+		Limit:       options.Limit,
+		Fields:      options.Fields,
+		Filter:      options.Filter,
+		RawWhere:    options.Where,
+		ExpandDepth: options.Expand,
+		Sort:        options.Sort,
+		Offset:      options.Offset,
+		Cursor:      options.Cursor,
+		GroupBy:     options.GroupBy,
+		Aggregates:  options.Aggregates,
+	})
+	if err != nil {
+		if queryErr := d.handleQueryErrors(err); queryErr != nil {
+			logger.Info(err.Error())
+			return database.Query{}, queryErr
+		}
+
+		logger.Error("build query", "err", err)
+		return database.Query{}, fmt.Errorf("build query: %w", err)
+	}
+
+	return query, nil
+}
+
+// matchTableName reports whether table name matches any of include glob patterns and none of exclude ones.
+// Empty include patterns match any name.
+func matchTableName(name string, include, exclude []string) (bool, error) {
+	matchAny := func(patterns []string) (bool, error) {
+		for _, pattern := range patterns {
+			matches, err := path.Match(pattern, name)
+			if err != nil {
+				return false, ErrInvalidTablePattern
+			}
+
+			if matches {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	if len(include) != 0 {
+		included, err := matchAny(include)
+		if err != nil || !included {
+			return false, err
+		}
+	}
+
+	excluded, err := matchAny(exclude)
+	if err != nil {
+		return false, err
+	}
+
+	return !excluded, nil
+}
+
+// getDatabase returns a database implementation for the given dialect.
+// PostgreSQL is used by default to keep sessions created without dialect working.
+func (d databaseService) getDatabase(dialect database.Dialect) (database.Database, error) {
+	if dialect == "" {
+		dialect = database.DialectPostgreSQL
+	}
+
+	db, ok := d.databases[dialect]
+	if !ok {
+		return nil, ErrUnsupportedDialect
+	}
+
+	return db, nil
+}
+
+func (d databaseService) handleConnectionErrors(err error) error {
+	if errs.HasAnyGivenMessage(err, database.ErrDatabaseDoesNotExists.Error()) {
+		return ErrDatabaseDoesNotExists
+	}
+	if errs.HasAnyGivenMessage(err, database.ErrInvalidUsername.Error()) {
+		return ErrInvalidUsername
+	}
+	if errs.HasAnyGivenMessage(err, database.ErrInvalidHost.Error()) {
+		return ErrInvalidHost
+	}
+	if errs.HasAnyGivenMessage(err, database.ErrInvalidPort.Error()) {
+		return ErrInvalidPort
+	}
+	if errs.HasAnyGivenMessage(err, database.ErrNoAccess.Error()) {
+		return ErrNoAccessToDatabase
+	}
+	if errs.HasAnyGivenMessage(err, database.ErrInvalidDatabaseFile.Error()) {
+		return ErrInvalidDatabaseFile
+	}
+
+	return err
+}
+
+// handleQueryErrors converts expected errors of query building into service errors.
+// It returns nil when error is unexpected.
+func (d databaseService) handleQueryErrors(err error) error {
+	if errors.Is(err, database.ErrTableDoesNotExist) {
+		return ErrTableDoesNotExist
+	}
+	if errors.Is(err, database.ErrInvalidTableName) {
+		return ErrInvalidTableName
+	}
+	if errors.Is(err, database.ErrNotMaterializedView) {
+		return ErrNotMaterializedView
+	}
+	if errors.Is(err, database.ErrColumnDoesNotExist) {
+		return ErrColumnDoesNotExist
+	}
+	if errors.Is(err, database.ErrInvalidFilter) {
+		return ErrInvalidFilter
+	}
+	if errors.Is(err, database.ErrInvalidExpandDepth) {
+		return ErrInvalidExpandDepth
+	}
+	if errors.Is(err, database.ErrInvalidSort) {
+		return ErrInvalidSort
+	}
+	if errors.Is(err, database.ErrInvalidCursor) {
+		return ErrInvalidCursor
+	}
+	if errors.Is(err, database.ErrOrderNotSelected) {
+		return ErrOrderNotSelected
+	}
+	if errors.Is(err, database.ErrOrderNotUnique) {
+		return ErrOrderNotUnique
+	}
+	if errors.Is(err, database.ErrInvalidAggregation) {
+		return ErrInvalidAggregation
+	}
+
+	return nil
 }
 
-func (d databaseService) getDatabaseCredentials(ctx context.Context, databaseKey string) (*DatabaseConnectionOptions, error) {
-	logger := d.logger.Named("databaseService.getDatabaseCredentials")
+// getConnectionSession reads and decrypts connection session data stored in cache by the database key.
+func (d databaseService) getConnectionSession(ctx context.Context, databaseKey string) (*connectionSession, error) {
+	logger := d.logger.Named("databaseService.getConnectionSession")
 
 	encryptedDatabaseCredentials, err := d.cacher.Read(ctx, databaseKey)
 	if err != nil {
@@ -294,8 +1577,8 @@ func (d databaseService) getDatabaseCredentials(ctx context.Context, databaseKey
 	}
 	logger.Debug("decrypted database credentials")
 
-	var databaseConnectionOptions DatabaseConnectionOptions
-	err = json.Unmarshal(decryptedDatabaseCredentials, &databaseConnectionOptions)
+	var session connectionSession
+	err = json.Unmarshal(decryptedDatabaseCredentials, &session)
 	if err != nil {
 		logger.Error("unmarshal database credentials", "err", err)
 		return nil, fmt.Errorf("unmarshal database credentials: %w", err)
@@ -303,5 +1586,5 @@ func (d databaseService) getDatabaseCredentials(ctx context.Context, databaseKey
 	}
 	logger.Debug("unmarshalled database credentials")
 
-	return &databaseConnectionOptions, nil
+	return &session, nil
 }
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/VladPetriv/d2j/pkg/database"
+	"github.com/VladPetriv/d2j/pkg/errs"
+)
+
+// dumpManifestFileName is a name of the archive file that describes dumped tables.
+const dumpManifestFileName = "manifest.json"
+
+// dumpManifest represents content of the manifest file of the database dump.
+type dumpManifest struct {
+	ExportedAt time.Time           `json:"exportedAt"`
+	Format     StreamFormat        `json:"format"`
+	Tables     []dumpManifestTable `json:"tables"`
+}
+
+// dumpManifestTable represents a dumped table in the manifest.
+type dumpManifestTable struct {
+	Name       string             `json:"name"`
+	SchemaName string             `json:"schemaName"`
+	Kind       database.TableKind `json:"kind"`
+	// File is a name of the archive file with rows of the table.
+	File       string            `json:"file"`
+	RowsCount  int               `json:"rowsCount"`
+	Columns    []database.Column `json:"columns"`
+	PrimaryKey []string          `json:"primaryKey"`
+}
+
+func (d databaseService) DumpDatabase(ctx context.Context, options DumpDatabaseOptions, w io.Writer) error {
+	logger := d.logger.Named("databaseService.DumpDatabase")
+
+	if options.Format == "" {
+		options.Format = StreamFormatNDJSON
+	}
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("get database client", "err", err)
+		return fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	databaseTables, err := databaseClient.ListTables(ctx, options.Schema)
+	if err != nil {
+		logger.Error("list database tables", "err", err)
+		return fmt.Errorf("list database tables: %w", err)
+	}
+	logger.Debug("got database tables", "databaseTables", databaseTables)
+
+	tables := make([]database.Table, 0, len(databaseTables))
+
+	for _, table := range databaseTables {
+		matches, err := matchTableName(table.TableName, options.Include, options.Exclude)
+		if err != nil {
+			logger.Info(err.Error())
+			return err
+		}
+
+		if matches {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		logger.Info(ErrNoTablesToDump.Error())
+		return ErrNoTablesToDump
+	}
+	logger.Debug("filtered database tables", "tables", tables)
+
+	manifest := dumpManifest{
+		ExportedAt: time.Now().UTC(),
+		Format:     options.Format,
+		Tables:     make([]dumpManifestTable, 0, len(tables)),
+	}
+
+	extension := "ndjson"
+	if options.Format == StreamFormatArray {
+		extension = "json"
+	}
+
+	// Structure of the tables is read before the snapshot, because snapshot holds a connection until the end of the dump.
+	queries := make([]database.Query, len(tables))
+	fileNames := map[string]struct{}{dumpManifestFileName: {}}
+
+	for i, table := range tables {
+		tableName := database.TableName{Schema: table.SchemaName, Name: table.TableName}.String()
+
+		description, err := databaseClient.DescribeTable(ctx, tableName)
+		if err != nil {
+			logger.Error("describe table", "err", err, "tableName", tableName)
+			return fmt.Errorf("describe table %s: %w", tableName, err)
+		}
+
+		queries[i], err = d.buildConversionQuery(ctx, databaseClient, ConvertDatabaseResultToJSONOptions{
+			DatabaseKey: options.DatabaseKey,
+			TableName:   tableName,
+		})
+		if err != nil {
+			logger.Error("build conversion query", "err", err, "tableName", tableName)
+			return fmt.Errorf("build table %s query: %w", tableName, err)
+		}
+
+		manifest.Tables = append(manifest.Tables, dumpManifestTable{
+			Name:       table.TableName,
+			SchemaName: table.SchemaName,
+			Kind:       table.Kind,
+			File:       dumpFileName(table.TableName, extension, fileNames),
+			Columns:    description.Columns,
+			PrimaryKey: description.PrimaryKey,
+		})
+	}
+	logger.Debug("built table queries", "queries", queries)
+
+	// All tables are read from the same snapshot, so rows of related tables are consistent with each other.
+	snapshot, err := databaseClient.BeginSnapshot(ctx)
+	if err != nil {
+		logger.Error("begin snapshot", "err", err)
+		return fmt.Errorf("begin snapshot: %w", err)
+	}
+	defer snapshot.Close() //nolint:errcheck
+
+	archive := zip.NewWriter(w)
+
+	// Errors after the first table could not be sent to the client as JSON, so they only interrupt the archive.
+	for i := range manifest.Tables {
+		table := &manifest.Tables[i]
+
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     table.File,
+			Method:   zip.Deflate,
+			Modified: manifest.ExportedAt,
+		})
+		if err != nil {
+			logger.Error("create archive file", "err", err, "fileName", table.File)
+			return fmt.Errorf("create archive file %s: %w", table.File, err)
+		}
+
+		fileWriter := &dumpFileWriter{file: file, archive: archive, w: w}
+
+		_, err = streamJSONRows(ctx, snapshot, queries[i], options.Format, fileWriter)
+		if err != nil {
+			logger.Error("stream table rows", "err", err, "tableName", table.Name)
+			return fmt.Errorf("stream table %s rows: %w", table.Name, err)
+		}
+		logger.Debug("dumped table", "tableName", table.Name, "rowsCount", fileWriter.rowsCount)
+
+		table.RowsCount = fileWriter.rowsCount
+	}
+
+	file, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     dumpManifestFileName,
+		Method:   zip.Deflate,
+		Modified: manifest.ExportedAt,
+	})
+	if err != nil {
+		logger.Error("create manifest file", "err", err)
+		return fmt.Errorf("create manifest file: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(manifest)
+	if err != nil {
+		logger.Error("write manifest file", "err", err)
+		return fmt.Errorf("write manifest file: %w", err)
+	}
+
+	err = archive.Close()
+	if err != nil {
+		logger.Error("close archive", "err", err)
+		return fmt.Errorf("close archive: %w", err)
+	}
+
+	err = flush(w)
+	if err != nil {
+		logger.Error("flush archive", "err", err)
+		return fmt.Errorf("flush archive: %w", err)
+	}
+
+	logger.Debug("dumped database", "tablesCount", len(manifest.Tables))
+	return nil
+}
+
+// dumpFileName returns a unique name of the archive file for the table.
+// Table names could contain any characters, so unsafe ones are replaced and a number is added to repeated names.
+func dumpFileName(tableName, extension string, used map[string]struct{}) string {
+	base := unsafeFileNameCharacters.ReplaceAllString(tableName, "_")
+
+	name := base + "." + extension
+	for i := 2; ; i++ {
+		if _, ok := used[name]; !ok {
+			break
+		}
+
+		name = base + "_" + strconv.Itoa(i) + "." + extension
+	}
+
+	used[name] = struct{}{}
+
+	return name
+}
+
+// dumpFileWriter writes a file of the archive and counts its rows.
+// Flushing of the file flushes compressed data of the archive to the client.
+type dumpFileWriter struct {
+	file      io.Writer
+	archive   *zip.Writer
+	w         io.Writer
+	rowsCount int
+}
+
+func (f *dumpFileWriter) Write(data []byte) (int, error) {
+	return f.file.Write(data)
+}
+
+func (f *dumpFileWriter) Flush() error {
+	err := f.archive.Flush()
+	if err != nil {
+		return fmt.Errorf("flush archive: %w", err)
+	}
+
+	return flush(f.w)
+}
+
+func (f *dumpFileWriter) countRows(count int) {
+	f.rowsCount = count
+}
@@ -0,0 +1,819 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/VladPetriv/d2j/pkg/caching"
+	"github.com/VladPetriv/d2j/pkg/database"
+	"github.com/VladPetriv/d2j/pkg/encryption"
+	"github.com/VladPetriv/d2j/pkg/errs"
+	"github.com/VladPetriv/d2j/pkg/workerpool"
+	"github.com/google/uuid"
+)
+
+// exportJobProgressInterval is a period of saving progress of the running job and checking whether it's canceled.
+const exportJobProgressInterval = time.Second
+
+// exportFileExtensions are extensions of export files by their format.
+var exportFileExtensions = map[ExportFormat]string{
+	ExportFormatNDJSON:  "ndjson",
+	ExportFormatJSON:    "json",
+	ExportFormatCSV:     "csv",
+	ExportFormatTSV:     "tsv",
+	ExportFormatXML:     "xml",
+	ExportFormatParquet: "parquet",
+}
+
+func (d databaseService) SubmitExportJob(ctx context.Context, options SubmitExportJobOptions) (*ExportJob, error) {
+	logger := d.logger.Named("databaseService.SubmitExportJob")
+
+	// Query is built before the job is queued, so invalid options are reported immediately.
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get database client", "err", err)
+		return nil, fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	query, err := d.buildConversionQuery(ctx, databaseClient, options.ConvertDatabaseResultToJSONOptions)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("build conversion query", "err", err)
+		return nil, fmt.Errorf("build conversion query: %w", err)
+	}
+	logger.Debug("built query", "query", query)
+
+	estimatedRows, warnings := d.estimateExportRows(ctx, databaseClient, options.ConvertDatabaseResultToJSONOptions)
+	logger.Debug("estimated exported rows", "estimatedRows", estimatedRows, "warnings", warnings)
+
+	job := exportJobState{
+		ExportJob: ExportJob{
+			ID:            uuid.NewString(),
+			Status:        ExportJobStatusQueued,
+			Format:        options.Format,
+			TableName:     options.TableName,
+			EstimatedRows: estimatedRows,
+			Warnings:      warnings,
+			CreatedAt:     time.Now(),
+		},
+		Owner: exportJobOwner(options.DatabaseKey),
+	}
+
+	err = d.writeExportJob(ctx, job)
+	if err != nil {
+		logger.Error("write export job to cache", "err", err)
+		return nil, fmt.Errorf("write export job to cache: %w", err)
+	}
+	logger.Debug("wrote export job to cache", "jobId", job.ID)
+
+	err = d.exportPool.Submit(func(ctx context.Context) {
+		d.runExportJob(ctx, job, options)
+	})
+	if err != nil {
+		deleteErr := d.cacher.Delete(ctx, exportJobKey(job.ID))
+		if deleteErr != nil {
+			logger.Error("delete export job from cache", "err", deleteErr)
+		}
+
+		if errors.Is(err, workerpool.ErrQueueFull) {
+			logger.Info(ErrTooManyExportJobs.Error())
+			return nil, ErrTooManyExportJobs
+		}
+
+		logger.Error("submit export job", "err", err)
+		return nil, fmt.Errorf("submit export job: %w", err)
+	}
+	logger.Debug("submitted export job", "jobId", job.ID)
+
+	return &job.ExportJob, nil
+}
+
+func (d databaseService) GetExportJob(ctx context.Context, options ExportJobOptions) (*ExportJob, error) {
+	logger := d.logger.Named("databaseService.GetExportJob")
+
+	job, err := d.getExportJob(ctx, options)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get export job", "err", err)
+		return nil, fmt.Errorf("get export job: %w", err)
+	}
+	logger.Debug("got export job", "status", job.Status)
+
+	return &job.ExportJob, nil
+}
+
+func (d databaseService) CancelExportJob(ctx context.Context, options ExportJobOptions) error {
+	logger := d.logger.Named("databaseService.CancelExportJob")
+
+	job, err := d.getExportJob(ctx, options)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("get export job", "err", err)
+		return fmt.Errorf("get export job: %w", err)
+	}
+	logger.Debug("got export job", "status", job.Status)
+
+	if job.Status != ExportJobStatusQueued && job.Status != ExportJobStatusRunning {
+		logger.Info(ErrExportJobFinished.Error(), "status", job.Status)
+		return ErrExportJobFinished
+	}
+
+	// Job could run on another replica, so it's only marked as canceled and is stopped by its worker.
+	err = d.cacher.Write(ctx, caching.WriteOptions{
+		Key:   exportJobCancelKey(job.ID),
+		Value: "1",
+		TTL:   d.config.Export.JobTTL,
+	})
+	if err != nil {
+		logger.Error("write export job cancellation to cache", "err", err)
+		return fmt.Errorf("write export job cancellation to cache: %w", err)
+	}
+	logger.Debug("wrote export job cancellation to cache")
+
+	return nil
+}
+
+func (d databaseService) OpenExportJobFile(ctx context.Context, options ExportJobOptions) (*ExportJobFile, error) {
+	logger := d.logger.Named("databaseService.OpenExportJobFile")
+
+	job, err := d.getExportJob(ctx, options)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get export job", "err", err)
+		return nil, fmt.Errorf("get export job: %w", err)
+	}
+	logger.Debug("got export job", "status", job.Status)
+
+	if job.Status != ExportJobStatusCompleted {
+		logger.Info(ErrExportJobNotCompleted.Error(), "status", job.Status)
+		return nil, ErrExportJobNotCompleted
+	}
+
+	file, err := os.Open(d.exportFilePath(job.ExportJob))
+	if err != nil {
+		// File could be already removed together with expired jobs.
+		if errors.Is(err, fs.ErrNotExist) {
+			logger.Info(ErrExportJobDoesNotExist.Error())
+			return nil, ErrExportJobDoesNotExist
+		}
+
+		logger.Error("open export file", "err", err)
+		return nil, fmt.Errorf("open export file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+
+		logger.Error("get export file info", "err", err)
+		return nil, fmt.Errorf("get export file info: %w", err)
+	}
+	logger.Debug("opened export file", "size", info.Size())
+
+	return &ExportJobFile{
+		Name:    exportFileName(job.ExportJob),
+		Format:  job.Format,
+		Size:    info.Size(),
+		Content: file,
+	}, nil
+}
+
+func (d databaseService) CreateExportJobEventsToken(ctx context.Context, options ExportJobOptions) (*ExportJobEventsToken, error) {
+	logger := d.logger.Named("databaseService.CreateExportJobEventsToken")
+
+	_, err := d.getExportJob(ctx, options)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get export job", "err", err)
+		return nil, fmt.Errorf("get export job: %w", err)
+	}
+	logger.Debug("got export job")
+
+	marshalledOptions, err := json.Marshal(options)
+	if err != nil {
+		logger.Error("marshal export job options", "err", err)
+		return nil, fmt.Errorf("marshal export job options: %w", err)
+	}
+
+	// Token resolves to the database key, so the key is stored encrypted like connection credentials.
+	encryptedOptions, err := d.encryptor.Encrypt(encryption.EncryptOptions{
+		Data:   marshalledOptions,
+		Secret: d.config.App.EncryptionSecretKey,
+	})
+	if err != nil {
+		logger.Error("encrypt export job options", "err", err)
+		return nil, fmt.Errorf("encrypt export job options: %w", err)
+	}
+
+	token := uuid.NewString()
+	expiresAt := time.Now().Add(d.config.Export.EventsTokenTTL)
+
+	err = d.cacher.Write(ctx, caching.WriteOptions{
+		Key:   exportJobEventsTokenKey(token),
+		Value: encryptedOptions,
+		TTL:   d.config.Export.EventsTokenTTL,
+	})
+	if err != nil {
+		logger.Error("write export job events token to cache", "err", err)
+		return nil, fmt.Errorf("write export job events token to cache: %w", err)
+	}
+	logger.Debug("created export job events token", "expiresAt", expiresAt)
+
+	return &ExportJobEventsToken{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (d databaseService) WatchExportJob(
+	ctx context.Context, watchOptions WatchExportJobOptions, handleEvent func(event ExportJobEvent) error,
+) error {
+	logger := d.logger.Named("databaseService.WatchExportJob")
+
+	options, err := d.getExportJobEventsTokenOptions(ctx, watchOptions.Token)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("get export job events token options", "err", err)
+		return fmt.Errorf("get export job events token options: %w", err)
+	}
+	logger.Debug("got export job events token options")
+
+	// Session is still checked on every read of the job, so disconnecting stops the stream opened by token.
+	job, err := d.getExportJob(ctx, *options)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return err
+		}
+
+		logger.Error("get export job", "err", err)
+		return fmt.Errorf("get export job: %w", err)
+	}
+	logger.Debug("got export job", "status", job.Status)
+
+	for _, warning := range job.Warnings {
+		err = handleEvent(ExportJobEvent{Type: ExportJobEventWarning, Message: warning})
+		if err != nil {
+			logger.Error("handle warning event", "err", err)
+			return fmt.Errorf("handle warning event: %w", err)
+		}
+	}
+
+	ticker := time.NewTicker(exportJobProgressInterval)
+	defer ticker.Stop()
+
+	var estimateExceeded bool
+
+	for {
+		event := ExportJobEvent{Type: ExportJobEventProgress, Progress: newExportJobProgress(job.ExportJob)}
+
+		switch job.Status {
+		case ExportJobStatusCompleted:
+			event.Type = ExportJobEventCompleted
+		case ExportJobStatusFailed:
+			event.Type = ExportJobEventError
+			event.Message = job.Error
+		case ExportJobStatusCanceled:
+			event.Type = ExportJobEventError
+			event.Message = "The export job was canceled."
+		}
+
+		// Statistics could be outdated, so the estimate is only a hint and it's reported once it's exceeded.
+		if !estimateExceeded && job.EstimatedRows != nil && job.RowsProcessed > *job.EstimatedRows {
+			estimateExceeded = true
+
+			err = handleEvent(ExportJobEvent{
+				Type:    ExportJobEventWarning,
+				Message: "More rows are processed than estimated, because statistics of the table are outdated.",
+			})
+			if err != nil {
+				logger.Error("handle warning event", "err", err)
+				return fmt.Errorf("handle warning event: %w", err)
+			}
+		}
+
+		err = handleEvent(event)
+		if err != nil {
+			logger.Error("handle event", "err", err, "type", event.Type)
+			return fmt.Errorf("handle %s event: %w", event.Type, err)
+		}
+
+		if event.Type != ExportJobEventProgress {
+			logger.Debug("export job is finished", "status", job.Status)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Debug("stopped watching export job", "err", ctx.Err())
+			return ctx.Err()
+
+		case <-ticker.C:
+		}
+
+		job, err = d.getExportJob(ctx, *options)
+		if err != nil {
+			if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+				logger.Info(err.Error())
+				return err
+			}
+
+			logger.Error("get export job", "err", err)
+			return fmt.Errorf("get export job: %w", err)
+		}
+	}
+}
+
+// newExportJobProgress returns progress of the job at the current moment.
+func newExportJobProgress(job ExportJob) *ExportJobProgress {
+	var elapsed time.Duration
+	switch {
+	case job.StartedAt != nil && job.FinishedAt != nil:
+		elapsed = job.FinishedAt.Sub(*job.StartedAt)
+	case job.StartedAt != nil:
+		elapsed = time.Since(*job.StartedAt)
+	}
+
+	return &ExportJobProgress{
+		Status:        job.Status,
+		RowsProcessed: job.RowsProcessed,
+		BytesWritten:  job.BytesWritten,
+		EstimatedRows: job.EstimatedRows,
+		Elapsed:       elapsed.Round(time.Millisecond).String(),
+	}
+}
+
+// estimateExportRows returns approximate number of exported rows and warnings about accuracy of the estimate.
+// Estimate is only a hint for progress, so it's never an error when it could not be made.
+func (d databaseService) estimateExportRows(
+	ctx context.Context, databaseClient database.DBClient, options ConvertDatabaseResultToJSONOptions,
+) (*int64, []string) {
+	logger := d.logger.Named("databaseService.estimateExportRows")
+
+	unknown := []string{"The total number of rows could not be estimated, so only processed rows are reported."}
+
+	// Number of groups could not be estimated by number of rows.
+	if len(options.GroupBy) != 0 || len(options.Aggregates) != 0 {
+		return nil, unknown
+	}
+
+	estimate, err := databaseClient.EstimateRowsCount(ctx, options.TableName)
+	if err != nil {
+		if !errors.Is(err, database.ErrRowsCountUnknown) {
+			logger.Error("estimate rows count", "err", err)
+		}
+
+		return nil, unknown
+	}
+
+	estimate = max(estimate-int64(options.Offset), 0)
+	if options.Limit != 0 {
+		estimate = min(estimate, int64(options.Limit))
+	}
+
+	var warnings []string
+	if options.Filter != nil || options.Where != "" || options.Cursor != "" {
+		warnings = append(warnings, "The estimated total does not consider filters, so fewer rows could be processed.")
+	}
+
+	return &estimate, warnings
+}
+
+// runExportJob writes result of the conversion into export file and saves progress of the job in cache.
+// It's called by export worker, whose context is canceled when server is shutting down.
+func (d databaseService) runExportJob(ctx context.Context, job exportJobState, options SubmitExportJobOptions) {
+	logger := d.logger.Named("databaseService.runExportJob")
+
+	// State of the job must be saved even when it's stopped by server shutdown.
+	stateCtx := context.WithoutCancel(ctx)
+
+	d.removeExpiredExportFiles()
+
+	// Job could be canceled or the server could be stopped while it was queued.
+	canceledInQueue, err := d.isExportJobCanceled(stateCtx, job.ID)
+	if err != nil {
+		logger.Error("check export job cancellation", "err", err, "jobId", job.ID)
+	}
+
+	if canceledInQueue || ctx.Err() != nil {
+		d.finishExportJob(stateCtx, job, ctx.Err(), canceledInQueue)
+		return
+	}
+
+	now := time.Now()
+	job.Status = ExportJobStatusRunning
+	job.StartedAt = &now
+
+	err = d.writeExportJob(stateCtx, job)
+	if err != nil {
+		logger.Error("write export job to cache", "err", err, "jobId", job.ID)
+	}
+	logger.Debug("started export job", "jobId", job.ID)
+
+	err = os.MkdirAll(d.config.Export.Directory, 0o750)
+	if err != nil {
+		logger.Error("create export directory", "err", err, "jobId", job.ID)
+		d.finishExportJob(stateCtx, job, err, false)
+		return
+	}
+
+	// Rows are written into temporary file, so incomplete export could not be downloaded.
+	path := d.exportFilePath(job.ExportJob)
+
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		logger.Error("create export file", "err", err, "jobId", job.ID)
+		d.finishExportJob(stateCtx, job, err, false)
+		return
+	}
+
+	w := &exportWriter{buffer: bufio.NewWriter(file)}
+
+	exportCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		done     = make(chan struct{})
+		canceled atomic.Bool
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		d.saveExportJobProgress(stateCtx, job, w, done, func() {
+			canceled.Store(true)
+			cancel()
+		})
+	}()
+
+	err = d.exportDatabaseResult(exportCtx, options, w)
+	if err == nil {
+		err = w.buffer.Flush()
+	}
+
+	close(done)
+	wg.Wait()
+
+	job.RowsProcessed = w.rows.Load()
+	job.BytesWritten = w.bytes.Load()
+
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		removeErr := os.Remove(path + ".tmp")
+		if removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+			logger.Error("remove export file", "err", removeErr, "jobId", job.ID)
+		}
+	}
+
+	d.finishExportJob(stateCtx, job, err, canceled.Load())
+}
+
+// saveExportJobProgress periodically saves progress of the running job until done is closed.
+// Cancel is called when user cancels the job, after that progress is not saved anymore.
+func (d databaseService) saveExportJobProgress(ctx context.Context, job exportJobState, w *exportWriter, done <-chan struct{}, cancel func()) {
+	logger := d.logger.Named("databaseService.saveExportJobProgress")
+
+	ticker := time.NewTicker(exportJobProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+
+		case <-ticker.C:
+			canceled, err := d.isExportJobCanceled(ctx, job.ID)
+			if err != nil {
+				logger.Error("check export job cancellation", "err", err, "jobId", job.ID)
+			}
+			if canceled {
+				logger.Debug("export job is canceled", "jobId", job.ID)
+				cancel()
+				return
+			}
+
+			job.RowsProcessed = w.rows.Load()
+			job.BytesWritten = w.bytes.Load()
+
+			err = d.writeExportJob(ctx, job)
+			if err != nil {
+				logger.Error("write export job to cache", "err", err, "jobId", job.ID)
+			}
+		}
+	}
+}
+
+// finishExportJob saves the final status of the job, which is stopped by the given error or completed without it.
+func (d databaseService) finishExportJob(ctx context.Context, job exportJobState, err error, canceled bool) {
+	logger := d.logger.Named("databaseService.finishExportJob")
+
+	now := time.Now()
+	job.FinishedAt = &now
+
+	switch {
+	case canceled:
+		job.Status = ExportJobStatusCanceled
+
+	case err == nil:
+		job.Status = ExportJobStatusCompleted
+
+	case errors.Is(err, context.Canceled):
+		job.Status = ExportJobStatusFailed
+		job.Error = "The export was interrupted by server shutdown. Please submit it again."
+
+	case errors.Is(err, ErrConnectionSessionTimeExpired):
+		job.Status = ExportJobStatusFailed
+		job.Error = "Connection session time expired"
+
+	case errs.IsExpected(err):
+		job.Status = ExportJobStatusFailed
+		job.Error = err.Error()
+
+	default:
+		logger.Error("export job failed", "err", err, "jobId", job.ID)
+
+		job.Status = ExportJobStatusFailed
+		job.Error = "The export failed because of an internal error. Please try again later."
+	}
+
+	err = d.writeExportJob(ctx, job)
+	if err != nil {
+		logger.Error("write export job to cache", "err", err, "jobId", job.ID)
+		return
+	}
+
+	logger.Debug("finished export job", "jobId", job.ID, "status", job.Status, "rowsProcessed", job.RowsProcessed)
+}
+
+// exportDatabaseResult streams result of the conversion in the format of export.
+// Options specific to the format are not supported, so defaults of the format are used.
+func (d databaseService) exportDatabaseResult(ctx context.Context, options SubmitExportJobOptions, w io.Writer) error {
+	switch options.Format {
+	case ExportFormatNDJSON, ExportFormatJSON:
+		format := StreamFormatNDJSON
+		if options.Format == ExportFormatJSON {
+			format = StreamFormatArray
+		}
+
+		return d.StreamDatabaseResultToJSON(ctx, StreamDatabaseResultToJSONOptions{
+			ConvertDatabaseResultToJSONOptions: options.ConvertDatabaseResultToJSONOptions,
+			Format:                             format,
+		}, w)
+
+	case ExportFormatCSV, ExportFormatTSV:
+		format := CSVFormatCSV
+		if options.Format == ExportFormatTSV {
+			format = CSVFormatTSV
+		}
+
+		return d.StreamDatabaseResultToCSV(ctx, StreamDatabaseResultToCSVOptions{
+			ConvertDatabaseResultToJSONOptions: options.ConvertDatabaseResultToJSONOptions,
+			Format:                             format,
+		}, w)
+
+	case ExportFormatXML:
+		return d.StreamDatabaseResultToXML(ctx, StreamDatabaseResultToXMLOptions{
+			ConvertDatabaseResultToJSONOptions: options.ConvertDatabaseResultToJSONOptions,
+		}, w)
+
+	case ExportFormatParquet:
+		return d.StreamDatabaseResultToParquet(ctx, StreamDatabaseResultToParquetOptions{
+			ConvertDatabaseResultToJSONOptions: options.ConvertDatabaseResultToJSONOptions,
+		}, w)
+
+	default:
+		return fmt.Errorf("unknown export format %q", options.Format)
+	}
+}
+
+// getExportJob reads the job from cache. Jobs of other database keys are reported as not existing.
+// Database key must belong to an active session, so jobs are not available after disconnecting or revoking it.
+func (d databaseService) getExportJob(ctx context.Context, options ExportJobOptions) (*exportJobState, error) {
+	_, err := d.getConnectionSession(ctx, options.DatabaseKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Job ID is a part of the cache key, so only valid IDs are allowed.
+	_, err = uuid.Parse(options.JobID)
+	if err != nil {
+		return nil, ErrExportJobDoesNotExist
+	}
+
+	data, err := d.cacher.Read(ctx, exportJobKey(options.JobID))
+	if err != nil {
+		if errors.Is(err, caching.ErrResultIsNil) {
+			return nil, ErrExportJobDoesNotExist
+		}
+
+		return nil, fmt.Errorf("read export job from cache: %w", err)
+	}
+
+	var job exportJobState
+	err = json.Unmarshal([]byte(data), &job)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal export job: %w", err)
+	}
+
+	if job.Owner != exportJobOwner(options.DatabaseKey) {
+		return nil, ErrExportJobDoesNotExist
+	}
+
+	return &job, nil
+}
+
+// getExportJobEventsTokenOptions returns options of the job for which the events token was created.
+func (d databaseService) getExportJobEventsTokenOptions(ctx context.Context, token string) (*ExportJobOptions, error) {
+	encryptedOptions, err := d.cacher.Read(ctx, exportJobEventsTokenKey(token))
+	if err != nil {
+		if errors.Is(err, caching.ErrResultIsNil) {
+			return nil, ErrInvalidExportJobEventsToken
+		}
+
+		return nil, fmt.Errorf("read export job events token from cache: %w", err)
+	}
+
+	decryptedOptions, err := d.encryptor.Decrypt(encryption.DecryptOptions{
+		EncryptedData: encryptedOptions,
+		Secret:        d.config.App.EncryptionSecretKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decrypt export job options: %w", err)
+	}
+
+	var options ExportJobOptions
+	err = json.Unmarshal(decryptedOptions, &options)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal export job options: %w", err)
+	}
+
+	return &options, nil
+}
+
+// writeExportJob saves the job in cache. TTL is refreshed on every write, so it's counted from the last update.
+func (d databaseService) writeExportJob(ctx context.Context, job exportJobState) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("marshal export job: %w", err)
+	}
+
+	err = d.cacher.Write(ctx, caching.WriteOptions{
+		Key:   exportJobKey(job.ID),
+		Value: string(data),
+		TTL:   d.config.Export.JobTTL,
+	})
+	if err != nil {
+		return fmt.Errorf("write export job: %w", err)
+	}
+
+	return nil
+}
+
+func (d databaseService) isExportJobCanceled(ctx context.Context, jobID string) (bool, error) {
+	_, err := d.cacher.Read(ctx, exportJobCancelKey(jobID))
+	if err != nil {
+		if errors.Is(err, caching.ErrResultIsNil) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("read export job cancellation from cache: %w", err)
+	}
+
+	return true, nil
+}
+
+// removeExpiredExportFiles removes files that are older than jobs TTL, including files of interrupted jobs.
+func (d databaseService) removeExpiredExportFiles() {
+	logger := d.logger.Named("databaseService.removeExpiredExportFiles")
+
+	entries, err := os.ReadDir(d.config.Export.Directory)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Error("read export directory", "err", err)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || time.Since(info.ModTime()) < d.config.Export.JobTTL {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(d.config.Export.Directory, entry.Name()))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logger.Error("remove expired export file", "err", err)
+			continue
+		}
+
+		logger.Debug("removed expired export file", "name", entry.Name())
+	}
+}
+
+func (d databaseService) exportFilePath(job ExportJob) string {
+	return filepath.Join(d.config.Export.Directory, job.ID+"."+exportFileExtensions[job.Format])
+}
+
+// unsafeFileNameCharacters are replaced in names of downloaded files.
+var unsafeFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// exportFileName returns a name of the downloaded file, which is based on the table name.
+func exportFileName(job ExportJob) string {
+	return unsafeFileNameCharacters.ReplaceAllString(job.TableName, "_") + "." + exportFileExtensions[job.Format]
+}
+
+func exportJobKey(jobID string) string {
+	return "export-jobs:" + jobID
+}
+
+func exportJobCancelKey(jobID string) string {
+	return "export-jobs:" + jobID + ":cancel"
+}
+
+// exportJobEventsTokenKey returns a cache key of the events token. Token is hashed, so it could not be read from cache keys.
+func exportJobEventsTokenKey(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return "export-jobs:events-tokens:" + hex.EncodeToString(hash[:])
+}
+
+// exportJobOwner returns a hash of the database key, which identifies the owner of the job.
+func exportJobOwner(databaseKey string) string {
+	hash := sha256.Sum256([]byte(databaseKey))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// exportWriter writes export file and counts written rows and bytes, which are saved as progress of the job.
+type exportWriter struct {
+	buffer *bufio.Writer
+	rows   atomic.Int64
+	bytes  atomic.Int64
+}
+
+func (w *exportWriter) Write(data []byte) (int, error) {
+	n, err := w.buffer.Write(data)
+	w.bytes.Add(int64(n))
+
+	return n, err
+}
+
+func (w *exportWriter) Flush() error {
+	return w.buffer.Flush()
+}
+
+func (w *exportWriter) countRows(count int) {
+	w.rows.Store(int64(count))
+}
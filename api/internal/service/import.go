@@ -0,0 +1,327 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/VladPetriv/d2j/pkg/database"
+	"github.com/VladPetriv/d2j/pkg/errs"
+)
+
+const (
+	// importBatchSize is a maximum number of rows inserted by a single statement.
+	importBatchSize = 500
+	// maxImportRowErrors is a number of row errors, after which import is stopped, because rows are probably sent to a wrong table.
+	maxImportRowErrors = 100
+)
+
+func (d databaseService) ImportDatabaseRows(ctx context.Context, options ImportDatabaseRowsOptions, r io.Reader) (*ImportReport, error) {
+	logger := d.logger.Named("databaseService.ImportDatabaseRows")
+
+	databaseClient, release, err := d.getDatabaseClient(ctx, options.DatabaseKey)
+	if err != nil {
+		if errs.IsExpected(err) || errors.Is(err, ErrConnectionSessionTimeExpired) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("get database client", "err", err)
+		return nil, fmt.Errorf("get database client: %w", err)
+	}
+	defer release()
+	logger.Debug("got database client")
+
+	importer, err := databaseClient.BeginImport(ctx, database.ImportOptions{
+		TableName:       options.TableName,
+		Upsert:          options.Mode == ImportModeUpsert,
+		ConflictColumns: options.ConflictColumns,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidConflictColumns) {
+			logger.Info(err.Error())
+			return nil, ErrInvalidConflictColumns
+		}
+		if queryErr := d.handleQueryErrors(err); queryErr != nil {
+			logger.Info(err.Error())
+			return nil, queryErr
+		}
+
+		logger.Error("begin import", "err", err)
+		return nil, fmt.Errorf("begin import: %w", err)
+	}
+	// Rollback does nothing after commit, so it's always called to not leave the transaction open on errors.
+	defer importer.Rollback() //nolint:errcheck
+
+	report, err := importRows(ctx, importer, r)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("import rows", "err", err)
+		return nil, fmt.Errorf("import rows: %w", err)
+	}
+	logger.Debug("imported rows", "rowsCount", report.RowsCount, "errorsCount", len(report.Errors))
+
+	if len(report.Errors) != 0 || options.DryRun {
+		return report, nil
+	}
+
+	err = importer.Commit()
+	if err != nil {
+		logger.Error("commit import", "err", err)
+		return nil, fmt.Errorf("commit import: %w", err)
+	}
+	report.Committed = true
+
+	return report, nil
+}
+
+// importBatch represents consecutive rows with the same columns, which are inserted by a single statement.
+type importBatch struct {
+	columns []string
+	rows    [][]json.RawMessage
+	// numbers are positions of the rows in the request body.
+	numbers []int
+}
+
+// importRows reads rows from the request body and inserts them in batches, invalid rows are added to the report.
+func importRows(ctx context.Context, importer database.Importer, r io.Reader) (*ImportReport, error) {
+	report := &ImportReport{Errors: make([]ImportRowError, 0)}
+
+	reader, err := newImportRowsReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch importBatch
+	for {
+		if len(report.Errors) >= maxImportRowErrors {
+			report.Incomplete = true
+			break
+		}
+
+		raw, err := reader.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+		report.RowsCount++
+
+		columns, values, message := parseImportRow(importer.Columns(), raw)
+		if message != "" {
+			report.Errors = append(report.Errors, ImportRowError{Row: report.RowsCount, Message: message})
+			continue
+		}
+
+		if !slices.Equal(batch.columns, columns) || len(batch.rows) == importBatchSize {
+			err = insertImportBatch(ctx, importer, batch, report)
+			if err != nil {
+				return nil, err
+			}
+
+			batch = importBatch{columns: columns}
+		}
+
+		batch.rows = append(batch.rows, values)
+		batch.numbers = append(batch.numbers, report.RowsCount)
+	}
+
+	err = insertImportBatch(ctx, importer, batch, report)
+	if err != nil {
+		return nil, err
+	}
+
+	// Errors of the rows are found when their batch is inserted, which happens after errors of the next invalid rows.
+	slices.SortFunc(report.Errors, func(a, b ImportRowError) int {
+		return a.Row - b.Row
+	})
+
+	if len(report.Errors) > maxImportRowErrors {
+		report.Errors = report.Errors[:maxImportRowErrors]
+		report.Incomplete = true
+	}
+
+	return report, nil
+}
+
+// insertImportBatch inserts rows of the batch by a single statement.
+// When the statement fails, rows are inserted one by one to find the failed ones.
+func insertImportBatch(ctx context.Context, importer database.Importer, batch importBatch, report *ImportReport) error {
+	if len(batch.rows) == 0 {
+		return nil
+	}
+
+	err := importer.InsertRows(ctx, batch.columns, batch.rows)
+	if err == nil {
+		report.WrittenRowsCount += len(batch.rows)
+		return nil
+	}
+
+	queryErr := &database.QueryError{}
+	if !errors.As(err, &queryErr) {
+		return fmt.Errorf("insert rows: %w", err)
+	}
+
+	if len(batch.rows) == 1 {
+		report.Errors = append(report.Errors, ImportRowError{Row: batch.numbers[0], Message: queryErr.Message})
+		return nil
+	}
+
+	for i, row := range batch.rows {
+		err = insertImportBatch(ctx, importer, importBatch{
+			columns: batch.columns,
+			rows:    [][]json.RawMessage{row},
+			numbers: batch.numbers[i : i+1],
+		}, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseImportRow returns sorted columns of the row and their values.
+// Message describes why the row could not be imported, it's empty for valid rows.
+func parseImportRow(tableColumns []string, raw json.RawMessage) ([]string, []json.RawMessage, string) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(raw, &fields)
+	if err != nil || fields == nil {
+		return nil, nil, "Row must be a JSON object."
+	}
+	if len(fields) == 0 {
+		return nil, nil, "Row must contain at least one column."
+	}
+
+	columns := make([]string, 0, len(fields))
+	var unknownColumns []string
+
+	for column := range fields {
+		if !slices.Contains(tableColumns, column) {
+			unknownColumns = append(unknownColumns, column)
+			continue
+		}
+
+		columns = append(columns, column)
+	}
+
+	if len(unknownColumns) != 0 {
+		slices.Sort(unknownColumns)
+		return nil, nil, fmt.Sprintf("Columns %s do not exist in the table.", strings.Join(unknownColumns, ", "))
+	}
+
+	// Columns are sorted, so rows with the same keys in another order are inserted by the same statement.
+	slices.Sort(columns)
+
+	values := make([]json.RawMessage, len(columns))
+	for i, column := range columns {
+		values[i] = fields[column]
+	}
+
+	return columns, values, ""
+}
+
+// importRowsReader reads rows of JSON array or newline-delimited JSON one by one.
+type importRowsReader struct {
+	decoder *json.Decoder
+	array   bool
+	// rowsCount is used for pointing to the row with invalid JSON.
+	rowsCount int
+}
+
+// newImportRowsReader detects format of the rows by the first character, JSON array starts with a bracket.
+func newImportRowsReader(r io.Reader) (*importRowsReader, error) {
+	buffered := bufio.NewReader(r)
+
+	var array bool
+	for {
+		char, _, err := buffered.ReadRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+
+		if unicode.IsSpace(char) {
+			continue
+		}
+
+		array = char == '['
+
+		err = buffered.UnreadRune()
+		if err != nil {
+			return nil, fmt.Errorf("unread rune: %w", err)
+		}
+
+		break
+	}
+
+	reader := &importRowsReader{decoder: json.NewDecoder(buffered), array: array}
+
+	// Opening bracket is read by decoder, so it decodes elements of the array one by one.
+	if array {
+		_, err := reader.decoder.Token()
+		if err != nil {
+			return nil, reader.invalidJSON(err)
+		}
+	}
+
+	return reader, nil
+}
+
+// next returns the next row as it's sent, io.EOF is returned when there are no more rows.
+func (r *importRowsReader) next() (json.RawMessage, error) {
+	if r.array && !r.decoder.More() {
+		token, err := r.decoder.Token()
+		if err != nil || token != json.Delim(']') {
+			return nil, r.invalidJSON(err)
+		}
+
+		// Nothing is allowed after the end of the array.
+		_, err = r.decoder.Token()
+		if !errors.Is(err, io.EOF) {
+			return nil, r.invalidJSON(err)
+		}
+
+		return nil, io.EOF
+	}
+
+	var raw json.RawMessage
+	err := r.decoder.Decode(&raw)
+	if err != nil {
+		if !r.array && errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, r.invalidJSON(err)
+	}
+	r.rowsCount++
+
+	return raw, nil
+}
+
+// invalidJSON returns an expected error pointing to the invalid row, errors of reading the body are returned as is.
+func (r *importRowsReader) invalidJSON(err error) error {
+	syntaxErr := &json.SyntaxError{}
+	if err != nil && !errors.As(err, &syntaxErr) && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("read request body: %w", err)
+	}
+
+	return errs.New(fmt.Sprintf(
+		"Invalid JSON at row %d. Please send a JSON array of objects or objects separated by new lines.", r.rowsCount+1,
+	))
+}
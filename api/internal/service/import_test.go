@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/VladPetriv/d2j/pkg/database"
+	"github.com/VladPetriv/d2j/pkg/errs"
+)
+
+// fakeImporter accepts rows of users table, statements with "invalid" name fail like a constraint violation.
+type fakeImporter struct {
+	// statements contains numbers of rows inserted by every successful statement.
+	statements []int
+}
+
+var _ database.Importer = (*fakeImporter)(nil)
+
+func (f *fakeImporter) Columns() []string {
+	return []string{"id", "name", "email"}
+}
+
+func (f *fakeImporter) InsertRows(_ context.Context, columns []string, rows [][]json.RawMessage) error {
+	name := slices.Index(columns, "name")
+
+	for _, row := range rows {
+		if name != -1 && string(row[name]) == `"invalid"` {
+			return &database.QueryError{Message: "name is invalid"}
+		}
+	}
+
+	f.statements = append(f.statements, len(rows))
+
+	return nil
+}
+
+func (f *fakeImporter) Commit() error {
+	return nil
+}
+
+func (f *fakeImporter) Rollback() error {
+	return nil
+}
+
+func TestImportRows(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		body           string
+		wantReport     ImportReport
+		wantStatements []int
+	}{
+		{
+			name:           "newline-delimited rows",
+			body:           "{\"id\": 1, \"name\": \"a\"}\n\n{\"name\": \"b\", \"id\": 2}\n",
+			wantReport:     ImportReport{RowsCount: 2, WrittenRowsCount: 2},
+			wantStatements: []int{2},
+		},
+		{
+			name:           "array of rows",
+			body:           ` [{"id": 1}, {"id": 2}, {"id": 3, "email": null}] `,
+			wantReport:     ImportReport{RowsCount: 3, WrittenRowsCount: 3},
+			wantStatements: []int{2, 1},
+		},
+		{
+			name:       "empty array",
+			body:       "[]",
+			wantReport: ImportReport{},
+		},
+		{
+			name:       "empty body",
+			body:       "",
+			wantReport: ImportReport{},
+		},
+		{
+			name: "invalid rows",
+			body: `[{"id": 1}, [1], {}, {"id": 2, "age": 3, "city": "x"}, {"id": 3}]`,
+			wantReport: ImportReport{
+				RowsCount:        5,
+				WrittenRowsCount: 2,
+				Errors: []ImportRowError{
+					{Row: 2, Message: "Row must be a JSON object."},
+					{Row: 3, Message: "Row must contain at least one column."},
+					{Row: 4, Message: "Columns age, city do not exist in the table."},
+				},
+			},
+			wantStatements: []int{2},
+		},
+		{
+			name: "failed statement is retried row by row",
+			body: `[{"id": 1, "name": "a"}, {"id": 2, "name": "invalid"}, {"id": 3, "name": "c"}, [], {"id": 4, "name": "invalid"}]`,
+			wantReport: ImportReport{
+				RowsCount:        5,
+				WrittenRowsCount: 2,
+				Errors: []ImportRowError{
+					{Row: 2, Message: "name is invalid"},
+					{Row: 4, Message: "Row must be a JSON object."},
+					{Row: 5, Message: "name is invalid"},
+				},
+			},
+			wantStatements: []int{1, 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			importer := &fakeImporter{}
+
+			report, err := importRows(context.Background(), importer, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("importRows() unexpected error: %v", err)
+			}
+
+			if tc.wantReport.Errors == nil {
+				tc.wantReport.Errors = []ImportRowError{}
+			}
+			if report.RowsCount != tc.wantReport.RowsCount || report.WrittenRowsCount != tc.wantReport.WrittenRowsCount ||
+				report.Incomplete != tc.wantReport.Incomplete || !slices.Equal(report.Errors, tc.wantReport.Errors) {
+				t.Errorf("importRows() = %+v, want %+v", *report, tc.wantReport)
+			}
+			if !slices.Equal(importer.statements, tc.wantStatements) {
+				t.Errorf("inserted statements = %v, want %v", importer.statements, tc.wantStatements)
+			}
+		})
+	}
+}
+
+func TestImportRowsBatches(t *testing.T) {
+	t.Parallel()
+
+	var body strings.Builder
+	for i := 1; i <= importBatchSize+1; i++ {
+		fmt.Fprintf(&body, "{\"id\": %d}\n", i)
+	}
+
+	importer := &fakeImporter{}
+
+	report, err := importRows(context.Background(), importer, strings.NewReader(body.String()))
+	if err != nil {
+		t.Fatalf("importRows() unexpected error: %v", err)
+	}
+
+	if report.WrittenRowsCount != importBatchSize+1 {
+		t.Errorf("written rows = %d, want %d", report.WrittenRowsCount, importBatchSize+1)
+	}
+	if want := []int{importBatchSize, 1}; !slices.Equal(importer.statements, want) {
+		t.Errorf("inserted statements = %v, want %v", importer.statements, want)
+	}
+}
+
+func TestImportRowsTooManyErrors(t *testing.T) {
+	t.Parallel()
+
+	var body strings.Builder
+	for i := 0; i < maxImportRowErrors+10; i++ {
+		body.WriteString("{\"id\": 1, \"name\": \"invalid\"}\n")
+	}
+
+	report, err := importRows(context.Background(), &fakeImporter{}, strings.NewReader(body.String()))
+	if err != nil {
+		t.Fatalf("importRows() unexpected error: %v", err)
+	}
+
+	if !report.Incomplete {
+		t.Errorf("import is not incomplete")
+	}
+	if len(report.Errors) != maxImportRowErrors {
+		t.Fatalf("errors count = %d, want %d", len(report.Errors), maxImportRowErrors)
+	}
+	for i, rowErr := range report.Errors {
+		if rowErr.Row != i+1 {
+			t.Fatalf("error %d is reported for row %d, want %d", i, rowErr.Row, i+1)
+		}
+	}
+}
+
+func TestImportRowsInvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		body        string
+		wantMessage string
+	}{
+		{
+			name:        "invalid row",
+			body:        "{\"id\": 1}\n{\"id\": }\n",
+			wantMessage: "Invalid JSON at row 2.",
+		},
+		{
+			name:        "unterminated array",
+			body:        `[{"id": 1}, {"id": 2}`,
+			wantMessage: "Invalid JSON at row 3.",
+		},
+		{
+			name:        "data after array",
+			body:        `[{"id": 1}] {"id": 2}`,
+			wantMessage: "Invalid JSON at row 2.",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := importRows(context.Background(), &fakeImporter{}, strings.NewReader(tc.body))
+			if !errs.IsExpected(err) || !strings.HasPrefix(err.Error(), tc.wantMessage) {
+				t.Fatalf("importRows() error = %v, want %q", err, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestParseImportRow(t *testing.T) {
+	t.Parallel()
+
+	tableColumns := []string{"id", "name", "email"}
+
+	testCases := []struct {
+		name        string
+		row         string
+		wantColumns []string
+		wantValues  []string
+		wantMessage string
+	}{
+		{
+			name:        "columns are sorted",
+			row:         `{"name": "a", "id": 9007199254740993, "email": null}`,
+			wantColumns: []string{"email", "id", "name"},
+			wantValues:  []string{"null", "9007199254740993", `"a"`},
+		},
+		{
+			name:        "nested value is kept as is",
+			row:         `{"name": {"first": "a"}}`,
+			wantColumns: []string{"name"},
+			wantValues:  []string{`{"first": "a"}`},
+		},
+		{
+			name:        "not an object",
+			row:         `"a"`,
+			wantMessage: "Row must be a JSON object.",
+		},
+		{
+			name:        "null",
+			row:         `null`,
+			wantMessage: "Row must be a JSON object.",
+		},
+		{
+			name:        "empty object",
+			row:         `{}`,
+			wantMessage: "Row must contain at least one column.",
+		},
+		{
+			name:        "unknown columns",
+			row:         `{"id": 1, "Name": "a"}`,
+			wantMessage: "Columns Name do not exist in the table.",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			columns, values, message := parseImportRow(tableColumns, json.RawMessage(tc.row))
+			if message != tc.wantMessage {
+				t.Fatalf("parseImportRow() message = %q, want %q", message, tc.wantMessage)
+			}
+
+			rawValues := make([]string, len(values))
+			for i, value := range values {
+				rawValues[i] = string(value)
+			}
+
+			if !slices.Equal(columns, tc.wantColumns) {
+				t.Errorf("parseImportRow() columns = %v, want %v", columns, tc.wantColumns)
+			}
+			if !slices.Equal(rawValues, tc.wantValues) {
+				t.Errorf("parseImportRow() values = %v, want %v", rawValues, tc.wantValues)
+			}
+		})
+	}
+}
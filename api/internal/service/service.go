@@ -2,15 +2,21 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"time"
 
 	"github.com/VladPetriv/d2j/config"
 	"github.com/VladPetriv/d2j/pkg/caching"
 	"github.com/VladPetriv/d2j/pkg/database"
 	"github.com/VladPetriv/d2j/pkg/encryption"
 	"github.com/VladPetriv/d2j/pkg/errs"
+	"github.com/VladPetriv/d2j/pkg/export"
 	"github.com/VladPetriv/d2j/pkg/hashing"
 	"github.com/VladPetriv/d2j/pkg/logger"
+	"github.com/VladPetriv/d2j/pkg/workerpool"
 )
 
 // Services represents a structure that contains all application services.
@@ -19,30 +25,68 @@ type Services struct {
 }
 
 type serviceContext struct {
-	logger    logger.Logger
-	config    config.Config
-	cacher    caching.Cacher
-	encryptor encryption.Encryptor
-	hasher    hashing.Hasher
-	database  database.Database
+	logger     logger.Logger
+	config     config.Config
+	cacher     caching.Cacher
+	encryptor  encryption.Encryptor
+	hasher     hashing.Hasher
+	databases  map[database.Dialect]database.Database
+	clientPool database.ClientPool
+	exportPool workerpool.Pool
 }
 
 // Options represents a structure that contains all packages that needed for services.
 type Options struct {
-	Logger    logger.Logger
-	Config    config.Config
-	Cacher    caching.Cacher
-	Encryptor encryption.Encryptor
-	Hasher    hashing.Hasher
-	Database  database.Database
+	Logger     logger.Logger
+	Config     config.Config
+	Cacher     caching.Cacher
+	Encryptor  encryption.Encryptor
+	Hasher     hashing.Hasher
+	Databases  map[database.Dialect]database.Database
+	ClientPool database.ClientPool
+	// ExportPool runs export jobs in the background.
+	ExportPool workerpool.Pool
 }
 
 // DatabaseService ...
 type DatabaseService interface {
 	TestDatabaseConnection(ctx context.Context, options DatabaseConnectionOptions) error
 	ConnectToDatabase(ctx context.Context, options ConnectToDatabaseOptions) (string, error)
-	ListDatabaseTables(ctx context.Context, options ListDatabaseTablesOptions) ([]string, error)
-	ConvertDatabaseResultToJSON(ctx context.Context, options ConvertDatabaseResultToJSONOptions) (string, error)
+	DisconnectFromDatabase(ctx context.Context, options DisconnectFromDatabaseOptions) error
+	GetDatabaseSession(ctx context.Context, options GetDatabaseSessionOptions) (*DatabaseSession, error)
+	ExtendDatabaseSession(ctx context.Context, options ExtendDatabaseSessionOptions) (*DatabaseSession, error)
+	RevokeDatabaseSessions(ctx context.Context, options RevokeDatabaseSessionsOptions) (int, error)
+	ListDatabaseSchemas(ctx context.Context, options ListDatabaseSchemasOptions) ([]string, error)
+	ListDatabaseTables(ctx context.Context, options ListDatabaseTablesOptions) ([]DatabaseTable, error)
+	DescribeDatabaseTable(ctx context.Context, options DescribeDatabaseTableOptions) (*database.TableDescription, error)
+	GenerateDatabaseTableJSONSchema(ctx context.Context, options GenerateDatabaseTableJSONSchemaOptions) (json.RawMessage, error)
+	GenerateDatabaseTableCode(ctx context.Context, options GenerateDatabaseTableCodeOptions) (string, error)
+	ConvertDatabaseResultToJSON(ctx context.Context, options ConvertDatabaseResultToJSONOptions) (*ConvertedDatabaseResult, error)
+	ConvertCustomQueryResultToJSON(ctx context.Context, options ConvertCustomQueryResultToJSONOptions) (string, error)
+	ConvertDatabaseResultToYAML(ctx context.Context, options ConvertDatabaseResultToDocumentOptions) (string, error)
+	ConvertDatabaseResultToTOML(ctx context.Context, options ConvertDatabaseResultToDocumentOptions) (string, error)
+	StreamDatabaseResultToJSON(ctx context.Context, options StreamDatabaseResultToJSONOptions, w io.Writer) error
+	StreamDatabaseResultToCSV(ctx context.Context, options StreamDatabaseResultToCSVOptions, w io.Writer) error
+	StreamDatabaseResultToXML(ctx context.Context, options StreamDatabaseResultToXMLOptions, w io.Writer) error
+	StreamDatabaseResultToParquet(ctx context.Context, options StreamDatabaseResultToParquetOptions, w io.Writer) error
+	// ImportDatabaseRows inserts rows of JSON array or newline-delimited JSON objects into the table inside of a transaction.
+	// Changes are committed only when all rows are valid and it's not a dry run. Errors of the rows are returned in the report.
+	ImportDatabaseRows(ctx context.Context, options ImportDatabaseRowsOptions, r io.Reader) (*ImportReport, error)
+	// CreateDatabaseTableFromJSON infers columns of a table from sample rows and returns proposed CREATE TABLE statement.
+	// Table is created only when confirmation of the same statement is sent.
+	CreateDatabaseTableFromJSON(ctx context.Context, options CreateDatabaseTableFromJSONOptions, r io.Reader) (*ProposedTable, error)
+	// DumpDatabase streams ZIP archive with a JSON file of rows for every selected table and a manifest of them.
+	DumpDatabase(ctx context.Context, options DumpDatabaseOptions, w io.Writer) error
+	ListConnectionPoolStats(ctx context.Context) []ConnectionPoolStats
+	SubmitExportJob(ctx context.Context, options SubmitExportJobOptions) (*ExportJob, error)
+	GetExportJob(ctx context.Context, options ExportJobOptions) (*ExportJob, error)
+	CancelExportJob(ctx context.Context, options ExportJobOptions) error
+	OpenExportJobFile(ctx context.Context, options ExportJobOptions) (*ExportJobFile, error)
+	// CreateExportJobEventsToken returns a short-lived token that allows watching events of the job without the database key.
+	CreateExportJobEventsToken(ctx context.Context, options ExportJobOptions) (*ExportJobEventsToken, error)
+	// WatchExportJob calls handleEvent with progress of the job every second until the job is finished.
+	// Watching stops on the first handleEvent error.
+	WatchExportJob(ctx context.Context, options WatchExportJobOptions, handleEvent func(event ExportJobEvent) error) error
 }
 
 // ConnectToDatabaseOptions represents options for ConnectToDatabase method.
@@ -53,32 +97,467 @@ type ConnectToDatabaseOptions struct {
 
 // DatabaseConnectionOptions represents options that required for creating connection with database.
 type DatabaseConnectionOptions struct {
-	Host           string `json:"host" binding:"required"`
-	Port           int    `json:"port" binding:"required"`
-	DatabaseName   string `json:"databaseName" binding:"required"`
-	Username       string `json:"username" binding:"required"`
-	Password       string `json:"password" binding:"required"`
-	SSLModeEnabled bool   `json:"sslModeEnabled"`
+	// Dialect is a kind of database to connect to. PostgreSQL is used when it's empty.
+	Dialect        database.Dialect `json:"dialect" binding:"omitempty,oneof=postgresql mysql sqlite"`
+	Host           string           `json:"host" binding:"required_unless=Dialect sqlite"`
+	Port           int              `json:"port" binding:"required_unless=Dialect sqlite"`
+	DatabaseName   string           `json:"databaseName" binding:"required_unless=Dialect sqlite"`
+	Username       string           `json:"username" binding:"required_unless=Dialect sqlite"`
+	Password       string           `json:"password" binding:"required_unless=Dialect sqlite"`
+	SSLModeEnabled bool             `json:"sslModeEnabled"`
+	// Path is a path to the database file inside configured directory, used only by SQLite.
+	Path string `json:"path" binding:"required_if=Dialect sqlite"`
+}
+
+// connectionSession represents data that is stored encrypted in cache for every connection session.
+// Connection options are embedded to keep sessions that were stored before metadata was added readable.
+type connectionSession struct {
+	DatabaseConnectionOptions
+	CreatedAt   time.Time     `json:"createdAt"`
+	SessionTime time.Duration `json:"sessionTime"`
+}
+
+// GetDatabaseSessionOptions represents options for GetDatabaseSession method.
+type GetDatabaseSessionOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
+}
+
+// ExtendDatabaseSessionOptions represents options for ExtendDatabaseSession method.
+type ExtendDatabaseSessionOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
+	// ExtendBy is a duration that is added to the remaining session time.
+	// When it's empty, the session time is refreshed to its initial value.
+	// Session could not be extended beyond the maximum session time since its creation.
+	ExtendBy string `json:"extendBy"`
+}
+
+// DatabaseSession represents metadata of the connection session. It never contains the password.
+type DatabaseSession struct {
+	Dialect        database.Dialect `json:"dialect"`
+	Host           string           `json:"host,omitempty"`
+	Port           int              `json:"port,omitempty"`
+	DatabaseName   string           `json:"databaseName,omitempty"`
+	Username       string           `json:"username,omitempty"`
+	Path           string           `json:"path,omitempty"`
+	SSLModeEnabled bool             `json:"sslModeEnabled"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	ExpiresAt      time.Time        `json:"expiresAt"`
+	RemainingTime  string           `json:"remainingTime"`
+}
+
+// DisconnectFromDatabaseOptions represents options for DisconnectFromDatabase method.
+type DisconnectFromDatabaseOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
+}
+
+// RevokeDatabaseSessionsOptions represents options for RevokeDatabaseSessions method.
+// Sessions are selected by host and database name, or by path for file based databases.
+type RevokeDatabaseSessionsOptions struct {
+	Host         string `json:"host" binding:"required_without=Path"`
+	DatabaseName string `json:"databaseName" binding:"required_without=Path"`
+	Path         string `json:"path"`
+}
+
+// ListDatabaseSchemasOptions represents options for ListDatabaseSchemas method.
+type ListDatabaseSchemasOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
 }
 
 // ListDatabaseTablesOptions represents options for ListDatabaseTables method.
 type ListDatabaseTablesOptions struct {
 	DatabaseKey string `json:"databaseKey" binding:"required"`
+	// Schema is a schema to list tables from, default schema of the connection is used when it's empty.
+	Schema string `json:"schema"`
+	// Include and Exclude are glob patterns of table names, e.g. "user_*".
+	// Table is listed when it matches any of include patterns (or they are empty) and none of exclude patterns.
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
 }
 
+// DatabaseTable represents a table-like object of the database.
+type DatabaseTable struct {
+	Name string             `json:"name"`
+	Kind database.TableKind `json:"kind"`
+}
+
+// DescribeDatabaseTableOptions represents options for DescribeDatabaseTable method.
+type DescribeDatabaseTableOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
+	TableName   string `json:"tableName" binding:"required"`
+}
+
+// GenerateDatabaseTableJSONSchemaOptions represents options for GenerateDatabaseTableJSONSchema method.
+type GenerateDatabaseTableJSONSchemaOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
+	TableName   string `json:"tableName" binding:"required"`
+	// Fields limits the schema to the selected columns, all columns are described by default.
+	Fields []string `json:"fields"`
+}
+
+// GenerateDatabaseTableCodeOptions represents options for GenerateDatabaseTableCode method.
+type GenerateDatabaseTableCodeOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
+	TableName   string `json:"tableName" binding:"required"`
+	// Fields limits generated type to the selected columns, all columns are used by default.
+	Fields   []string     `json:"fields"`
+	Language CodeLanguage `json:"language" binding:"required,oneof=go typescript"`
+	// TypeName is a name of generated type, it's derived from the table name by default.
+	TypeName string `json:"typeName"`
+	// PackageName is a name of Go package, "models" is used by default.
+	PackageName string `json:"packageName"`
+}
+
+// CodeLanguage represents a language of generated code.
+type CodeLanguage string
+
+const (
+	// CodeLanguageGo - Go struct with json and db tags.
+	CodeLanguageGo CodeLanguage = "go"
+	// CodeLanguageTypeScript - TypeScript interface.
+	CodeLanguageTypeScript CodeLanguage = "typescript"
+)
+
 // ConvertDatabaseResultToJSONOptions represents options for ConvertDatabaseResultToJS method.
 type ConvertDatabaseResultToJSONOptions struct {
 	DatabaseKey string `json:"databaseKey" binding:"required"`
 	TableName   string `json:"tableName" binding:"required"`
 
-	Fields []string `json:"fields"`
-	Limit  int      `json:"limit"`
-	Where  string   `json:"where"`
+	Fields []string         `json:"fields"`
+	Limit  int              `json:"limit" binding:"min=0"`
+	Filter *database.Filter `json:"filter"`
+	// Where is a raw SQL condition, which could be used only when it's enabled in config.
+	Where string `json:"where"`
+	// RefreshMaterializedView refreshes the materialized view before selecting data from it.
+	RefreshMaterializedView bool `json:"refreshMaterializedView"`
+	// Expand is a number of foreign key levels, by which related rows are embedded into every row.
+	// Referenced row is embedded as an object and referencing rows as an array.
+	Expand int `json:"expand" binding:"min=0"`
+	// Sort defines order of rows. Rows are also ordered by primary key when a part of them is selected,
+	// or by all selected fields when table has no primary key.
+	Sort []database.Sort `json:"sort"`
+	// Offset is a number of skipped rows, it's simple but slow for large tables.
+	Offset int `json:"offset" binding:"min=0"`
+	// Cursor is nextCursor of the previous page, it could not be used with offset or for tables without primary key.
+	Cursor string `json:"cursor"`
+	// GroupBy and Aggregates turn rows into summaries, one JSON object per group with its columns and aggregates.
+	GroupBy    []string             `json:"groupBy"`
+	Aggregates []database.Aggregate `json:"aggregates"`
+}
+
+// ConvertedDatabaseResult represents a result of ConvertDatabaseResultToJSON method.
+type ConvertedDatabaseResult struct {
+	Result string
+	// NextCursor points to the last row of the page, it's empty when there are no more rows
+	// or when table has no primary key, so the next page could be selected only by offset.
+	// Paginated fields must contain sort and primary key fields, otherwise ErrOrderNotSelected is returned.
+	NextCursor string
+}
+
+// ConvertCustomQueryResultToJSONOptions represents options for ConvertCustomQueryResultToJSON method.
+type ConvertCustomQueryResultToJSONOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
+	// Query is a single SELECT or WITH statement, it's executed inside of read-only transaction.
+	Query string `json:"query" binding:"required"`
+	Limit int    `json:"limit" binding:"min=0"`
+}
+
+// ConvertDatabaseResultToDocumentOptions represents options for ConvertDatabaseResultToYAML and ConvertDatabaseResultToTOML methods.
+type ConvertDatabaseResultToDocumentOptions struct {
+	ConvertDatabaseResultToJSONOptions
+	// KeyColumn turns rows into a map by the value of this column instead of a list.
+	// Values of the column must be unique and not NULL.
+	KeyColumn string `json:"keyColumn"`
+}
+
+// StreamDatabaseResultToJSONOptions represents options for StreamDatabaseResultToJSON method.
+type StreamDatabaseResultToJSONOptions struct {
+	ConvertDatabaseResultToJSONOptions
+
+	Format StreamFormat `json:"format" binding:"omitempty,oneof=ndjson array"`
+}
+
+// StreamFormat represents a format of streamed JSON rows.
+type StreamFormat string
+
+const (
+	// StreamFormatNDJSON - newline-delimited JSON, one row per line. It's used by default.
+	StreamFormatNDJSON StreamFormat = "ndjson"
+	// StreamFormatArray - rows are streamed as elements of JSON array.
+	StreamFormatArray StreamFormat = "array"
+)
+
+// StreamDatabaseResultToCSVOptions represents options for StreamDatabaseResultToCSV method.
+type StreamDatabaseResultToCSVOptions struct {
+	ConvertDatabaseResultToJSONOptions
+	Format CSVFormat `json:"format" binding:"omitempty,oneof=csv tsv"`
+	// Delimiter is a single character that separates fields, it overrides default delimiter of the format.
+	Delimiter string `json:"delimiter"`
+	// QuoteMode overrides default quoting of the format, which is "minimal" for CSV and "none" for TSV.
+	QuoteMode export.QuoteMode `json:"quoteMode" binding:"omitempty,oneof=minimal all none"`
+	// NullValue is written instead of NULL values. By default it's an empty string for CSV and \N for TSV.
+	NullValue *string `json:"nullValue"`
+}
+
+// CSVFormat represents a flavour of delimiter-separated values.
+type CSVFormat string
+
+const (
+	// CSVFormatCSV - comma-separated values. It's used by default.
+	CSVFormatCSV CSVFormat = "csv"
+	// CSVFormatTSV - tab-separated values.
+	CSVFormatTSV CSVFormat = "tsv"
+)
+
+// StreamDatabaseResultToXMLOptions represents options for StreamDatabaseResultToXML method.
+type StreamDatabaseResultToXMLOptions struct {
+	ConvertDatabaseResultToJSONOptions
+	// RootElement is a name of the element that wraps all rows, "rows" is used by default.
+	RootElement string `json:"rootElement"`
+	// RowElement is a name of the element of every row, "row" is used by default.
+	RowElement string `json:"rowElement"`
+	// AttributeColumns are written as attributes of the row element, other columns are written as child elements.
+	AttributeColumns []string `json:"attributeColumns"`
+}
+
+// StreamDatabaseResultToParquetOptions represents options for StreamDatabaseResultToParquet method.
+type StreamDatabaseResultToParquetOptions struct {
+	ConvertDatabaseResultToJSONOptions
+	// RowGroupSize is a maximum number of rows in a row group, DefaultParquetRowGroupSize is used by default.
+	RowGroupSize int `json:"rowGroupSize" binding:"omitempty,min=1,max=1000000"`
+}
+
+// DefaultParquetRowGroupSize is a number of rows in a parquet row group when it's not set by user.
+const DefaultParquetRowGroupSize = 10000
+
+// DumpDatabaseOptions represents options for DumpDatabase method.
+type DumpDatabaseOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
+	// Schema is a schema to dump tables from, default schema of the connection is used when it's empty.
+	Schema string `json:"schema"`
+	// Include and Exclude are glob patterns of table names, which are matched like in ListDatabaseTablesOptions.
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+	// Format is a format of table files, NDJSON is used by default.
+	Format StreamFormat `json:"format" binding:"omitempty,oneof=ndjson array"`
+}
+
+// ImportDatabaseRowsOptions represents options for ImportDatabaseRows method.
+// They are sent as query parameters, because request body contains the imported rows.
+type ImportDatabaseRowsOptions struct {
+	DatabaseKey string `form:"databaseKey" binding:"required"`
+	TableName   string `form:"tableName" binding:"required"`
+	// Mode defines how rows are written, they are inserted by default.
+	Mode ImportMode `form:"mode" binding:"omitempty,oneof=insert upsert"`
+	// ConflictColumns are columns of primary key or unique index, by which upsert finds existing rows.
+	// Primary key is used when they are empty.
+	ConflictColumns []string `form:"conflictColumns"`
+	// DryRun validates rows by writing them into the table and rolls the changes back.
+	DryRun bool `form:"dryRun"`
+}
+
+// ImportMode represents a way of writing imported rows.
+type ImportMode string
+
+const (
+	// ImportModeInsert - rows are inserted, so rows with existing keys are reported as errors.
+	ImportModeInsert ImportMode = "insert"
+	// ImportModeUpsert - existing rows with the same key are updated by imported ones.
+	ImportModeUpsert ImportMode = "upsert"
+)
+
+// ImportReport represents a result of rows import.
+type ImportReport struct {
+	// RowsCount is a number of read rows, it's less than number of sent rows when import is incomplete.
+	RowsCount int `json:"rowsCount"`
+	// WrittenRowsCount is a number of rows accepted by the database, they are saved only when import is committed.
+	WrittenRowsCount int  `json:"writtenRowsCount"`
+	Committed        bool `json:"committed"`
+	// Incomplete means that import was stopped after maxImportRowErrors errors, so the rest of rows was not checked.
+	Incomplete bool             `json:"incomplete"`
+	Errors     []ImportRowError `json:"errors"`
+}
+
+// ImportRowError represents a reason, why the row could not be imported.
+type ImportRowError struct {
+	// Row is a position of the row in the request body starting from 1.
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// CreateDatabaseTableFromJSONOptions represents options for CreateDatabaseTableFromJSON method.
+// They are sent as query parameters, because request body contains the sample rows.
+type CreateDatabaseTableFromJSONOptions struct {
+	DatabaseKey string `form:"databaseKey" binding:"required"`
+	TableName   string `form:"tableName" binding:"required"`
+	// PrimaryKey replaces inferred primary key, which is "id" column when its values are unique.
+	PrimaryKey []string `form:"primaryKey"`
+	// Confirmation is returned with the proposed table, table is created only when it's sent back.
+	Confirmation string `form:"confirmation"`
+}
+
+// ProposedTable represents a table inferred from the sample rows.
+type ProposedTable struct {
+	Columns    []database.NewColumn `json:"columns"`
+	PrimaryKey []string             `json:"primaryKey"`
+	// DDL is CREATE TABLE statement with types of the session's database.
+	DDL string `json:"ddl"`
+	// Confirmation identifies the statement, so table could not be created by a statement that user has not seen.
+	Confirmation string `json:"confirmation"`
+	Created      bool   `json:"created"`
+}
+
+// ConnectionPoolStats represents statistics of connections opened for a single session.
+type ConnectionPoolStats struct {
+	// SessionFingerprint is a short hash of the database key, which identifies session without revealing the key.
+	SessionFingerprint string    `json:"sessionFingerprint"`
+	CreatedAt          time.Time `json:"createdAt"`
+	LastUsedAt         time.Time `json:"lastUsedAt"`
+	MaxOpenConnections int       `json:"maxOpenConnections"`
+	OpenConnections    int       `json:"openConnections"`
+	InUse              int       `json:"inUse"`
+	Idle               int       `json:"idle"`
+	WaitCount          int64     `json:"waitCount"`
+	WaitDuration       string    `json:"waitDuration"`
+}
+
+// SubmitExportJobOptions represents options for SubmitExportJob method.
+type SubmitExportJobOptions struct {
+	ConvertDatabaseResultToJSONOptions
+	Format ExportFormat `json:"format" binding:"required,oneof=ndjson json csv tsv xml parquet"`
+}
+
+// ExportFormat represents a format of the export file.
+type ExportFormat string
+
+const (
+	// ExportFormatNDJSON - newline-delimited JSON, one row per line.
+	ExportFormatNDJSON ExportFormat = "ndjson"
+	// ExportFormatJSON - JSON array of rows.
+	ExportFormatJSON ExportFormat = "json"
+	// ExportFormatCSV - comma-separated values with a header.
+	ExportFormatCSV ExportFormat = "csv"
+	// ExportFormatTSV - tab-separated values with a header.
+	ExportFormatTSV ExportFormat = "tsv"
+	// ExportFormatXML - XML document with an element per row.
+	ExportFormatXML ExportFormat = "xml"
+	// ExportFormatParquet - Apache Parquet file.
+	ExportFormatParquet ExportFormat = "parquet"
+)
+
+// ExportJobOptions represents options for GetExportJob, CancelExportJob, OpenExportJobFile and CreateExportJobEventsToken methods.
+// Job is available only with the database key that was used for its submission.
+type ExportJobOptions struct {
+	DatabaseKey string `json:"databaseKey" binding:"required"`
+	JobID       string `json:"jobId" binding:"required"`
+}
+
+// WatchExportJobOptions represents options for WatchExportJob method.
+// Browsers receive server-sent events only by GET requests, so options are passed as query parameters.
+// Token is used instead of the database key, because URLs are kept in browser history and access logs.
+type WatchExportJobOptions struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// ExportJobEventsToken represents a token for watching events of a single export job.
+// It could be used until expiration, so EventSource of the browser is able to reconnect with the same URL.
+type ExportJobEventsToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ExportJob represents a state of the export that runs in the background.
+type ExportJob struct {
+	ID        string          `json:"id"`
+	Status    ExportJobStatus `json:"status"`
+	Format    ExportFormat    `json:"format"`
+	TableName string          `json:"tableName"`
+	// RowsProcessed and BytesWritten are updated periodically while the job is running.
+	RowsProcessed int64 `json:"rowsProcessed"`
+	BytesWritten  int64 `json:"bytesWritten"`
+	// EstimatedRows is an approximate total number of rows from database statistics, it's not set when it's unknown.
+	EstimatedRows *int64 `json:"estimatedRows,omitempty"`
+	// Warnings explain why the progress could be inaccurate.
+	Warnings []string `json:"warnings,omitempty"`
+	// Error is a reason of the failure, it's set only for failed jobs.
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// ExportJobStatus represents a stage of the export job.
+type ExportJobStatus string
+
+const (
+	// ExportJobStatusQueued - job waits for a free worker.
+	ExportJobStatusQueued ExportJobStatus = "queued"
+	// ExportJobStatusRunning - rows are being written into the export file.
+	ExportJobStatusRunning ExportJobStatus = "running"
+	// ExportJobStatusCompleted - export file is ready for download.
+	ExportJobStatusCompleted ExportJobStatus = "completed"
+	// ExportJobStatusFailed - job is stopped by an error.
+	ExportJobStatusFailed ExportJobStatus = "failed"
+	// ExportJobStatusCanceled - job is stopped by user.
+	ExportJobStatusCanceled ExportJobStatus = "canceled"
+)
+
+// ExportJobEvent represents a server-sent event about progress of the export job.
+type ExportJobEvent struct {
+	Type ExportJobEventType `json:"-"`
+	// Progress is set for all events except of warnings.
+	Progress *ExportJobProgress `json:"progress,omitempty"`
+	// Message is set for warning and error events.
+	Message string `json:"message,omitempty"`
+}
+
+// ExportJobEventType represents a kind of export job event.
+type ExportJobEventType string
+
+const (
+	// ExportJobEventProgress - current progress of the job, it's sent every second.
+	ExportJobEventProgress ExportJobEventType = "progress"
+	// ExportJobEventWarning - the progress could be inaccurate, every warning is sent once.
+	ExportJobEventWarning ExportJobEventType = "warning"
+	// ExportJobEventCompleted - the final event of the completed job.
+	ExportJobEventCompleted ExportJobEventType = "completed"
+	// ExportJobEventError - the final event of the failed or canceled job.
+	ExportJobEventError ExportJobEventType = "error"
+)
+
+// ExportJobProgress represents progress of the export job at the moment of the event.
+type ExportJobProgress struct {
+	Status        ExportJobStatus `json:"status"`
+	RowsProcessed int64           `json:"rowsProcessed"`
+	BytesWritten  int64           `json:"bytesWritten"`
+	EstimatedRows *int64          `json:"estimatedRows,omitempty"`
+	// Elapsed is a time since the job was started, it's zero for queued jobs.
+	Elapsed string `json:"elapsed"`
+}
+
+// exportJobState represents data that is stored in cache for every export job.
+// Owner is a hash of the database key, so the key itself is not stored next to the job.
+type exportJobState struct {
+	ExportJob
+	Owner string `json:"owner"`
+}
+
+// ExportJobFile represents an export file of the completed job.
+type ExportJobFile struct {
+	// Name is a suggested name of the downloaded file.
+	Name    string
+	Format  ExportFormat
+	Size    int64
+	Content io.ReadCloser
 }
 
 var (
 	// ErrConnectionSessionTimeExpired occurs when entered by user time for connection session is expired.
 	ErrConnectionSessionTimeExpired = errors.New("connection session time expired")
+	// ErrConnectionSessionTimeTooLong occurs when user requests connection session longer than allowed maximum,
+	// or extends the session beyond the maximum time since its creation.
+	ErrConnectionSessionTimeTooLong = errs.New("The connection session time is too long. A session could not last longer than the maximum time since connecting, so please choose a shorter time or connect again.")
+	// ErrInvalidConnectionSessionTime occurs when user enters connection session time in invalid format.
+	ErrInvalidConnectionSessionTime = errs.New("Invalid connection session time. Please use values like 30m or 2h and try again.")
 	// ErrDatabaseDoesNotExists occurs when entered database name does not exists.
 	ErrDatabaseDoesNotExists = errs.New("The entered database does not exist. Please verify the database name and try again")
 	// ErrInvalidUsername occurs when user enters incorrect username for database.
@@ -89,4 +568,72 @@ var (
 	ErrInvalidPort = errs.New("Invalid port number. Please check and try again.")
 	// ErrNoAccessToDatabase occurs when user database config does not allow connection from different IPs.
 	ErrNoAccessToDatabase = errs.New("Access denied. Please verify your credentials and connection settings")
+	// ErrInvalidDatabaseFile occurs when user selects a file that is not a valid database.
+	ErrInvalidDatabaseFile = errs.New("The selected file is not a valid database. Please check the path and try again.")
+	// ErrRawWhereDisabled occurs when user sends raw SQL condition, but it's not allowed by config.
+	ErrRawWhereDisabled = errs.New("Raw WHERE conditions are disabled. Please use filters instead.")
+	// ErrTableDoesNotExist occurs when user selects a table that does not exist.
+	ErrTableDoesNotExist = errs.New("The selected table does not exist. Please check the table name and try again.")
+	// ErrInvalidTableName occurs when user enters table name that could not be parsed as "table" or "schema.table".
+	ErrInvalidTableName = errs.New(`Invalid table name. Please use "table" or "schema.table" format and wrap names with dots into double quotes.`)
+	// ErrInvalidTablePattern occurs when user enters invalid glob pattern for filtering tables.
+	ErrInvalidTablePattern = errs.New("Invalid table name pattern. Please check the include and exclude patterns and try again.")
+	// ErrNoTablesToDump occurs when include and exclude patterns do not match any table of the schema.
+	ErrNoTablesToDump = errs.New("There are no tables that match the include and exclude patterns. Please check them and try again.")
+	// ErrNotMaterializedView occurs when user requests refresh of an object that is not a materialized view.
+	ErrNotMaterializedView = errs.New("Only materialized views could be refreshed. Please disable refresh for this table and try again.")
+	// ErrInvalidDelimiter occurs when user enters delimiter that is not a single character or could not be used in CSV.
+	ErrInvalidDelimiter = errs.New("Invalid delimiter. Please use a single character other than a quote or line break.")
+	// ErrDuplicateKey occurs when rows are keyed by column with not unique values.
+	ErrDuplicateKey = errs.New("Values of the key column are not unique. Please choose another key column and try again.")
+	// ErrInvalidKey occurs when rows are keyed by column with NULL or non-scalar values.
+	ErrInvalidKey = errs.New("Values of the key column must be strings, numbers or booleans. Please choose another key column and try again.")
+	// ErrUnsupportedValue occurs when selected value could not be represented in the chosen output format.
+	ErrUnsupportedValue = errs.New("Some of the selected values could not be represented in the chosen format, e.g. NULL inside of TOML array.")
+	// ErrInvalidXMLName occurs when element name or selected column name could not be used as XML name.
+	ErrInvalidXMLName = errs.New("Element and column names must be valid XML names. Please rename elements or select other fields and try again.")
+	// ErrInvalidIdentifier occurs when user enters type or package name that is not valid in the chosen language.
+	ErrInvalidIdentifier = errs.New("Invalid type or package name. Please use a valid identifier of the chosen language.")
+	// ErrInvalidExpandDepth occurs when user requests expanding of foreign keys deeper than allowed.
+	ErrInvalidExpandDepth = errs.New(fmt.Sprintf("Invalid expand depth. Please use a value from 0 to %d.", database.MaxExpandDepth))
+	// ErrColumnDoesNotExist occurs when user selects or filters by a column that does not exist in the table.
+	ErrColumnDoesNotExist = errs.New("Some of the selected columns do not exist. Please check the field names and try again.")
+	// ErrInvalidFilter occurs when user sends a filter with invalid structure, operator or value.
+	ErrInvalidFilter = errs.New("Invalid filter. Please check the filter operators and values and try again.")
+	// ErrInvalidSort occurs when user sends sort with unknown direction or nulls order, or with duplicated fields.
+	ErrInvalidSort = errs.New(`Invalid sort. Please use "asc" or "desc" direction, "first" or "last" nulls and sort by every field once.`)
+	// ErrInvalidAggregation occurs when user sends unknown aggregate function, invalid or duplicated keys or combines aggregation with fields or expand.
+	ErrInvalidAggregation = errs.New("Invalid aggregation. Please use count, sum, avg, min, max or array_agg with unique aliases of letters, digits and underscores and do not combine them with fields or expand.")
+	// ErrInvalidCursor occurs when cursor is corrupted, was returned for another sort or is used with offset.
+	ErrInvalidCursor = errs.New("Invalid cursor. Please use nextCursor of the previous page with the same sort and without offset.")
+	// ErrOrderNotSelected occurs when user paginates rows and selects fields without sort fields or primary key columns.
+	ErrOrderNotSelected = errs.New("Paginated rows must contain sort fields and primary key columns. Please add them to the fields and try again.")
+	// ErrOrderNotUnique occurs when user paginates rows of the table without primary key by cursor.
+	ErrOrderNotUnique = errs.New("Rows of the table without primary key could not be paginated by cursor. Please use offset instead.")
+	// ErrInvalidCustomQuery occurs when custom query is not a single statement that only selects data.
+	ErrInvalidCustomQuery = errs.New("Only a single SELECT or WITH statement without data changes could be executed. Please check the query and try again.")
+	// ErrCustomQueryTimeout occurs when custom query is running longer than allowed by config.
+	ErrCustomQueryTimeout = errs.New("The query took too long and was canceled. Please simplify it or add a limit and try again.")
+	// ErrExportJobDoesNotExist occurs when user requests export job that does not exist, is expired or was submitted with another database key.
+	ErrExportJobDoesNotExist = errs.New("The export job does not exist or has expired. Please check the job ID and try again.")
+	// ErrExportJobFinished occurs when user cancels export job that is already finished.
+	ErrExportJobFinished = errs.New("The export job is already finished and could not be canceled.")
+	// ErrExportJobNotCompleted occurs when user downloads file of export job that is not completed.
+	ErrExportJobNotCompleted = errs.New("The export file is not ready. Please wait until the job is completed and try again.")
+	// ErrInvalidExportJobEventsToken occurs when user watches export job with token that does not exist or has expired.
+	ErrInvalidExportJobEventsToken = errs.New("The events token is invalid or has expired. Please request a new one and try again.")
+	// ErrTooManyExportJobs occurs when all export workers are busy and no more jobs could be queued.
+	ErrTooManyExportJobs = errs.New("The server is handling too many export jobs right now. Please try again later.")
+	// ErrInvalidConflictColumns occurs when conflict columns do not match primary key or unique index, or they are used without upsert.
+	ErrInvalidConflictColumns = errs.New("Conflict columns must match the primary key or a unique index of the table and could be used only with upsert.")
+	// ErrInvalidSample occurs when sample rows for table inference are not JSON objects.
+	ErrInvalidSample = errs.New("Invalid sample. Please send a JSON array of objects or objects separated by new lines.")
+	// ErrInvalidTableDefinition occurs when sample has no keys or primary key columns do not have values in every row.
+	ErrInvalidTableDefinition = errs.New("The table could not be defined by the sample. Please send objects with non-empty keys and use primary key columns that have values in every row.")
+	// ErrInvalidConfirmation occurs when confirmation does not match the table inferred from the sample.
+	ErrInvalidConfirmation = errs.New("The proposed table has changed. Please review the new DDL and confirm it again.")
+	// ErrTooManyConnections occurs when all pooled connections are busy and no more could be opened.
+	ErrTooManyConnections = errs.New("The server is handling too many database sessions right now. Please try again later.")
+	// ErrUnsupportedDialect occurs when user selects a database dialect that is not supported.
+	ErrUnsupportedDialect = errs.New("Unsupported database type. Please choose another one and try again.")
 )
@@ -11,6 +11,16 @@ type Cacher interface {
 	Write(ctx context.Context, options WriteOptions) error
 	Read(ctx context.Context, key string) (string, error)
 	Delete(ctx context.Context, key string) error
+	// DeleteMany deletes the keys and returns a number of keys that existed.
+	DeleteMany(ctx context.Context, keys []string) (int, error)
+	// TTL returns the remaining time to live of the key.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Expire sets a new time to live for the existing key.
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	// AddToSet adds member to the set stored by key. TTL of the set is extended if it's less than the given one.
+	AddToSet(ctx context.Context, options AddToSetOptions) error
+	ReadSet(ctx context.Context, key string) ([]string, error)
+	RemoveFromSet(ctx context.Context, key string, member string) error
 	Close() error
 }
 
@@ -28,5 +38,12 @@ type WriteOptions struct {
 	TTL   time.Duration
 }
 
+// AddToSetOptions represents an options that used for adding member into set.
+type AddToSetOptions struct {
+	Key    string
+	Member string
+	TTL    time.Duration
+}
+
 // ErrResultIsNil happens when cache returns nothing on read request.
 var ErrResultIsNil = errors.New("result is nil")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -56,6 +57,88 @@ func (r redisCache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+func (r redisCache) DeleteMany(ctx context.Context, keys []string) (int, error) {
+	// Redis does not accept DEL command without keys.
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	deleted, err := r.client.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("delete from redis: %w", err)
+	}
+
+	return int(deleted), nil
+}
+
+func (r redisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("get ttl from redis: %w", err)
+	}
+
+	// Redis returns -2 when key does not exist.
+	if ttl == -2 {
+		return 0, ErrResultIsNil
+	}
+
+	return ttl, nil
+}
+
+func (r redisCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	exists, err := r.client.Expire(ctx, key, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("set expiration in redis: %w", err)
+	}
+
+	if !exists {
+		return ErrResultIsNil
+	}
+
+	return nil
+}
+
+func (r redisCache) AddToSet(ctx context.Context, options AddToSetOptions) error {
+	err := r.client.SAdd(ctx, options.Key, options.Member).Err()
+	if err != nil {
+		return fmt.Errorf("add to set in redis: %w", err)
+	}
+
+	ttl, err := r.client.TTL(ctx, options.Key).Result()
+	if err != nil {
+		return fmt.Errorf("get ttl from redis: %w", err)
+	}
+
+	if ttl >= options.TTL {
+		return nil
+	}
+
+	err = r.client.Expire(ctx, options.Key, options.TTL).Err()
+	if err != nil {
+		return fmt.Errorf("set expiration in redis: %w", err)
+	}
+
+	return nil
+}
+
+func (r redisCache) ReadSet(ctx context.Context, key string) ([]string, error) {
+	members, err := r.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get set members from redis: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r redisCache) RemoveFromSet(ctx context.Context, key string, member string) error {
+	err := r.client.SRem(ctx, key, member).Err()
+	if err != nil {
+		return fmt.Errorf("remove from set in redis: %w", err)
+	}
+
+	return nil
+}
+
 func (r redisCache) Close() error {
 	if r.client != nil {
 		err := r.client.Close()
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// aggregateAliasPattern allows only aliases that could not change the query, whatever quoting rules the database uses.
+var aggregateAliasPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,62}$`)
+
+// Aggregate represents a value computed from rows of every group.
+type Aggregate struct {
+	Function AggregateFunction `json:"function"`
+	// Field is an aggregated column. It's optional for count, which counts rows then.
+	Field string `json:"field"`
+	// Distinct aggregates only distinct values of the field, it could not be used with array_agg.
+	Distinct bool `json:"distinct"`
+	// Alias is a key of the value in JSON object, it's "<function>_<field>" or "count" by default.
+	// It could contain only letters, digits and underscores and must not start with a digit.
+	Alias string `json:"alias"`
+}
+
+// AggregateFunction represents a function that could be used in Aggregate.
+type AggregateFunction string
+
+const (
+	// AggregateCount - number of rows or not NULL values of the field.
+	AggregateCount AggregateFunction = "count"
+	// AggregateSum - sum of the values.
+	AggregateSum AggregateFunction = "sum"
+	// AggregateAvg - average of the values.
+	AggregateAvg AggregateFunction = "avg"
+	// AggregateMin - the smallest value.
+	AggregateMin AggregateFunction = "min"
+	// AggregateMax - the largest value.
+	AggregateMax AggregateFunction = "max"
+	// AggregateArray - JSON array of the values.
+	AggregateArray AggregateFunction = "array_agg"
+)
+
+// buildAggregateQuery builds a query that returns a JSON object with group by columns and aggregates for every group.
+// All rows are a single group, when group by columns are not set.
+func buildAggregateQuery(builder *queryBuilder, table TableName, options BuildQueryOptions) (Query, error) {
+	dialect := builder.dialect
+
+	if len(options.Fields) != 0 || options.ExpandDepth != 0 {
+		return Query{}, fmt.Errorf("%w: fields and expand could not be used with aggregation", ErrInvalidAggregation)
+	}
+	if options.Cursor != "" {
+		return Query{}, fmt.Errorf("%w: cursor could not be used with aggregation", ErrInvalidCursor)
+	}
+
+	err := builder.validateColumns(options.GroupBy...)
+	if err != nil {
+		return Query{}, err
+	}
+
+	// Expressions are used for sorting by the keys of JSON object, because object is the only selected value.
+	expressions := make(map[string]string, len(options.GroupBy)+len(options.Aggregates))
+	for _, column := range options.GroupBy {
+		if _, ok := expressions[column]; ok {
+			return Query{}, fmt.Errorf("%w: column %s is grouped more than once", ErrInvalidAggregation, column)
+		}
+
+		expressions[column] = dialect.quoteIdentifier(column)
+	}
+
+	keys := slices.Clone(options.GroupBy)
+	arguments := make([]string, 0, len(options.Aggregates))
+
+	for _, aggregate := range options.Aggregates {
+		expression, err := builder.buildAggregate(aggregate)
+		if err != nil {
+			return Query{}, err
+		}
+
+		alias := aggregate.Alias
+		if alias != "" && !aggregateAliasPattern.MatchString(alias) {
+			return Query{}, fmt.Errorf("%w: invalid alias %q", ErrInvalidAggregation, alias)
+		}
+		if alias == "" {
+			alias = string(aggregate.Function)
+			if aggregate.Field != "" {
+				alias += "_" + aggregate.Field
+			}
+		}
+
+		if _, ok := expressions[alias]; ok {
+			return Query{}, fmt.Errorf("%w: key %s is used more than once", ErrInvalidAggregation, alias)
+		}
+
+		expressions[alias] = expression
+		arguments = append(arguments, fmt.Sprintf("%s, %s", dialect.quoteString(alias), expression))
+		keys = append(keys, alias)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s AS %s",
+		dialect.fieldsToJSON(options.GroupBy, arguments), quoteTableName(dialect, table), dialect.quoteIdentifier(table.Name),
+	)
+
+	conditions, err := builder.buildConditions(options)
+	if err != nil {
+		return Query{}, err
+	}
+	if len(conditions) != 0 {
+		query += fmt.Sprintf(" WHERE %s", strings.Join(conditions, " AND "))
+	}
+
+	if len(options.GroupBy) != 0 {
+		groupBy := make([]string, len(options.GroupBy))
+		for i, column := range options.GroupBy {
+			groupBy[i] = expressions[column]
+		}
+
+		query += " GROUP BY " + strings.Join(groupBy, ", ")
+	}
+
+	// Groups are unique by group by columns, so they are added to the order to make pages stable.
+	if len(options.Sort) != 0 || options.Limit != 0 || options.Offset != 0 {
+		var order []orderColumn
+
+		for _, s := range options.Sort {
+			expression, ok := expressions[s.Field]
+			if !ok {
+				return Query{}, fmt.Errorf("%w: %s", ErrColumnDoesNotExist, s.Field)
+			}
+
+			column, err := builder.parseSort(s)
+			if err != nil {
+				return Query{}, err
+			}
+			if containsOrderColumn(order, s.Field) {
+				return Query{}, fmt.Errorf("%w: field %s is used more than once", ErrInvalidSort, s.Field)
+			}
+
+			column.expression = expression
+			order = append(order, column)
+		}
+
+		for _, column := range options.GroupBy {
+			if !containsOrderColumn(order, column) {
+				order = append(order, orderColumn{name: column, nullsFirst: dialect.nullsSortFirst()})
+			}
+		}
+
+		if len(order) != 0 {
+			query += " " + builder.buildOrderBy(order)
+		}
+	}
+
+	query += buildLimit(dialect, options.Limit, options.Offset)
+
+	return Query{Text: query, Args: builder.args, Columns: keys}, nil
+}
+
+// buildAggregate returns SQL expression of the aggregate.
+func (b *queryBuilder) buildAggregate(aggregate Aggregate) (string, error) {
+	if aggregate.Field == "" {
+		if aggregate.Function != AggregateCount || aggregate.Distinct {
+			return "", fmt.Errorf("%w: field is required for %s", ErrInvalidAggregation, aggregate.Function)
+		}
+
+		return "COUNT(*)", nil
+	}
+
+	err := b.validateColumns(aggregate.Field)
+	if err != nil {
+		return "", err
+	}
+
+	argument := b.dialect.quoteIdentifier(aggregate.Field)
+	if aggregate.Distinct {
+		argument = "DISTINCT " + argument
+	}
+
+	switch aggregate.Function {
+	case AggregateCount, AggregateSum, AggregateAvg, AggregateMin, AggregateMax:
+		return fmt.Sprintf("%s(%s)", strings.ToUpper(string(aggregate.Function)), argument), nil
+
+	case AggregateArray:
+		// MySQL does not support distinct values in JSON arrays.
+		if aggregate.Distinct {
+			return "", fmt.Errorf("%w: distinct could not be used with %s", ErrInvalidAggregation, aggregate.Function)
+		}
+
+		return b.dialect.aggregateJSON(argument), nil
+
+	default:
+		return "", fmt.Errorf("%w: unknown function %q", ErrInvalidAggregation, aggregate.Function)
+	}
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSQLiteBuildQueryAggregate(t *testing.T) {
+	t.Parallel()
+
+	client := newTestSQLiteClient(t)
+
+	testCases := []struct {
+		name     string
+		options  BuildQueryOptions
+		wantRows []string
+	}{
+		{
+			name: "groups with default aliases",
+			options: BuildQueryOptions{
+				TableName:  "books",
+				GroupBy:    []string{"author_id"},
+				Aggregates: []Aggregate{{Function: AggregateCount}, {Function: AggregateMax, Field: "price"}},
+				Sort:       []Sort{{Field: "author_id"}},
+			},
+			wantRows: []string{
+				`{"author_id":null,"count":1,"max_price":1.0}`,
+				`{"author_id":1,"count":2,"max_price":10.5}`,
+				`{"author_id":2,"count":2,"max_price":10.5}`,
+			},
+		},
+		{
+			name: "all rows with custom alias",
+			options: BuildQueryOptions{
+				TableName:  "sales",
+				Aggregates: []Aggregate{{Function: AggregateCount, Field: "title", Distinct: true, Alias: "_Titles2"}},
+			},
+			wantRows: []string{`{"_Titles2":3}`},
+		},
+		{
+			name: "sort by aggregate",
+			options: BuildQueryOptions{
+				TableName:  "sales",
+				GroupBy:    []string{"title"},
+				Aggregates: []Aggregate{{Function: AggregateSum, Field: "quantity", Alias: "total"}},
+				Sort:       []Sort{{Field: "total", Direction: SortDescending}},
+			},
+			wantRows: []string{`{"title":"Alpha","total":3}`, `{"title":"Beta","total":2}`, `{"title":"Gamma","total":2}`},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			query, err := client.BuildQuery(tc.options)
+			if err != nil {
+				t.Fatalf("BuildQuery() unexpected error: %v", err)
+			}
+
+			rows, err := client.ExecuteQuery(context.Background(), query)
+			if err != nil {
+				t.Fatalf("ExecuteQuery() unexpected error: %v", err)
+			}
+			if !slices.Equal(rows, tc.wantRows) {
+				t.Errorf("selected rows = %v, want %v", rows, tc.wantRows)
+			}
+		})
+	}
+}
+
+func TestSQLiteBuildQueryAggregateErrors(t *testing.T) {
+	t.Parallel()
+
+	client := newTestSQLiteClient(t)
+
+	testCases := []struct {
+		name       string
+		aggregates []Aggregate
+		wantErr    error
+	}{
+		{
+			name:       "alias with backslash",
+			aggregates: []Aggregate{{Function: AggregateCount, Alias: `x\`}},
+			wantErr:    ErrInvalidAggregation,
+		},
+		{
+			name:       "alias with quote",
+			aggregates: []Aggregate{{Function: AggregateCount, Alias: "x', (SELECT 1), 'y"}},
+			wantErr:    ErrInvalidAggregation,
+		},
+		{
+			name:       "alias starting with digit",
+			aggregates: []Aggregate{{Function: AggregateCount, Alias: "1x"}},
+			wantErr:    ErrInvalidAggregation,
+		},
+		{
+			name:       "duplicated alias",
+			aggregates: []Aggregate{{Function: AggregateCount}, {Function: AggregateSum, Field: "price", Alias: "count"}},
+			wantErr:    ErrInvalidAggregation,
+		},
+		{
+			name:       "unknown function",
+			aggregates: []Aggregate{{Function: "median", Field: "price"}},
+			wantErr:    ErrInvalidAggregation,
+		},
+		{
+			name:       "unknown field",
+			aggregates: []Aggregate{{Function: AggregateSum, Field: "missing"}},
+			wantErr:    ErrColumnDoesNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := client.BuildQuery(BuildQueryOptions{TableName: "books", Aggregates: tc.aggregates})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("BuildQuery() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
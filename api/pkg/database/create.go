@@ -0,0 +1,106 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValueKind represents a kind of column values, from which the database chooses type of the column.
+type ValueKind string
+
+const (
+	// ValueKindInteger - whole numbers.
+	ValueKindInteger ValueKind = "integer"
+	// ValueKindNumber - numbers with fractional part.
+	ValueKindNumber ValueKind = "number"
+	// ValueKindBoolean - true or false.
+	ValueKindBoolean ValueKind = "boolean"
+	// ValueKindTimestamp - date and time in RFC 3339 format.
+	ValueKindTimestamp ValueKind = "timestamp"
+	// ValueKindDate - date without time in YYYY-MM-DD format.
+	ValueKindDate ValueKind = "date"
+	// ValueKindUUID - UUID in the canonical format.
+	ValueKindUUID ValueKind = "uuid"
+	// ValueKindText - any other strings.
+	ValueKindText ValueKind = "text"
+	// ValueKindJSON - JSON objects and arrays.
+	ValueKindJSON ValueKind = "json"
+)
+
+// CreateTableOptions represents an options that used for creating a table.
+type CreateTableOptions struct {
+	// TableName is a table name in the format of ParseTableName.
+	TableName string
+	Columns   []NewColumn
+	// PrimaryKey contains names of primary key columns, table has no primary key when it's empty.
+	PrimaryKey []string
+}
+
+// NewColumn represents a column of the created table.
+type NewColumn struct {
+	Name     string    `json:"name"`
+	Kind     ValueKind `json:"kind"`
+	Nullable bool      `json:"nullable"`
+}
+
+// buildCreateTableQuery builds CREATE TABLE statement with a column per line, so it could be shown to user as is.
+func buildCreateTableQuery(dialect sqlDialect, options CreateTableOptions) (Query, error) {
+	table, err := ParseTableName(options.TableName)
+	if err != nil {
+		return Query{}, err
+	}
+
+	if len(options.Columns) == 0 {
+		return Query{}, fmt.Errorf("%w: table has no columns", ErrInvalidTableDefinition)
+	}
+
+	names := make([]string, 0, len(options.Columns))
+	definitions := make([]string, 0, len(options.Columns)+1)
+
+	for _, column := range options.Columns {
+		if column.Name == "" {
+			return Query{}, fmt.Errorf("%w: column name is empty", ErrInvalidTableDefinition)
+		}
+		if slices.Contains(names, column.Name) {
+			return Query{}, fmt.Errorf("%w: column %s is defined more than once", ErrInvalidTableDefinition, column.Name)
+		}
+
+		key := slices.Contains(options.PrimaryKey, column.Name)
+		if key && column.Nullable {
+			return Query{}, fmt.Errorf("%w: primary key column %s is nullable", ErrInvalidTableDefinition, column.Name)
+		}
+
+		dataType, err := dialect.columnType(column.Kind, key)
+		if err != nil {
+			return Query{}, err
+		}
+
+		definition := fmt.Sprintf("%s %s", dialect.quoteIdentifier(column.Name), dataType)
+		if !column.Nullable {
+			definition += " NOT NULL"
+		}
+
+		names = append(names, column.Name)
+		definitions = append(definitions, definition)
+	}
+
+	if len(options.PrimaryKey) != 0 {
+		quoted := make([]string, len(options.PrimaryKey))
+		for i, name := range options.PrimaryKey {
+			if !slices.Contains(names, name) {
+				return Query{}, fmt.Errorf("%w: primary key column %s is not defined", ErrInvalidTableDefinition, name)
+			}
+
+			quoted[i] = dialect.quoteIdentifier(name)
+		}
+
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(quoted, ", ")))
+	}
+
+	text := fmt.Sprintf(
+		"CREATE TABLE %s (\n    %s\n);", quoteTableName(dialect, table), strings.Join(definitions, ",\n    "),
+	)
+
+	return Query{Text: text}, nil
+}
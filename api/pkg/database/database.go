@@ -1,11 +1,34 @@
 package database
 
-import "github.com/VladPetriv/d2j/pkg/errs"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/VladPetriv/d2j/pkg/errs"
+	"github.com/VladPetriv/d2j/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialect represents a kind of SQL database that could be used as a data source.
+type Dialect string
+
+const (
+	// DialectPostgreSQL - PostgreSQL database.
+	DialectPostgreSQL Dialect = "postgresql"
+	// DialectMySQL - MySQL or MariaDB database.
+	DialectMySQL Dialect = "mysql"
+	// DialectSQLite - SQLite database file.
+	DialectSQLite Dialect = "sqlite"
+)
 
 // Database ...
 type Database interface {
+	// Connect opens a new client with its own set of connections, which must be closed by the caller.
 	Connect(options ConnectionOptions) (DBClient, error)
-	Close() error
 }
 
 // ConnectionOptions represents an options that used for connecting to the database.
@@ -16,29 +39,101 @@ type ConnectionOptions struct {
 	Password       string
 	DatabaseName   string
 	SSLModeEnabled bool
+	// Path is a path to the database file, used by file based databases instead of host and port.
+	Path string
+
+	// MaxOpenConnections and MaxIdleConnections limit connections of the client, zero means default limits.
+	MaxOpenConnections int
+	MaxIdleConnections int
 }
 
 // DBClient ...
 type DBClient interface {
-	BuildQuery(options BuildQueryOptions) string
-	ListTables() ([]Table, error)
-	ExecuteQuery(query string) ([]string, error)
+	BuildQuery(options BuildQueryOptions) (Query, error)
+	// ListSchemas returns names of all user schemas, system schemas are excluded.
+	ListSchemas(ctx context.Context) ([]string, error)
+	// ListTables returns tables, views, materialized views and foreign tables of the schema.
+	// Default schema of the connection is used when schema name is empty.
+	ListTables(ctx context.Context, schemaName string) ([]Table, error)
+	// RefreshMaterializedView updates data of the materialized view, name of which is in the format of ParseTableName.
+	RefreshMaterializedView(ctx context.Context, tableName string) error
+	// DescribeTable returns columns, keys and indexes of the table, name of which is in the format of ParseTableName.
+	DescribeTable(ctx context.Context, tableName string) (*TableDescription, error)
+	// EstimateRowsCount returns approximate number of rows of the table from database statistics without scanning it.
+	// ErrRowsCountUnknown is returned when there are no statistics for the table.
+	EstimateRowsCount(ctx context.Context, tableName string) (int64, error)
+	ExecuteQuery(ctx context.Context, query Query) ([]string, error)
+	// ExecuteReadOnlyQuery runs a single SELECT or WITH statement written by user inside of read-only transaction
+	// and returns its rows as JSON objects. Errors of the statement are returned as *QueryError.
+	ExecuteReadOnlyQuery(ctx context.Context, options ReadOnlyQueryOptions) ([]string, error)
+	// StreamQuery runs query which returns a single JSON column and calls handleRow for every row.
+	// The row is valid only until handleRow returns. Streaming stops on the first handleRow error.
+	StreamQuery(ctx context.Context, query Query, handleRow func(row []byte) error) error
+	// BeginSnapshot starts a read-only transaction, queries of which see the state of the database at its first query.
+	BeginSnapshot(ctx context.Context) (Snapshot, error)
+	// BeginImport validates the options against the table, name of which is in the format of ParseTableName,
+	// and starts a transaction, in which rows are inserted into the table.
+	BeginImport(ctx context.Context, options ImportOptions) (Importer, error)
+	// BuildCreateTableQuery builds CREATE TABLE statement, types of the columns are chosen by the database from kinds of their values.
+	BuildCreateTableQuery(options CreateTableOptions) (Query, error)
+	// CreateTable runs the statement of BuildCreateTableQuery. Errors of the statement are returned as *QueryError.
+	CreateTable(ctx context.Context, options CreateTableOptions) error
+	Stats() DBStats
+	Close() error
 }
 
 // BuildQueryOptions represents an options that used for building query.
 type BuildQueryOptions struct {
+	// TableName is a table name in the format of ParseTableName.
 	TableName string
-	Fields    []string
-	Limit     int
-	Where     string
+	// Fields are selected columns, all columns are selected when it's empty.
+	// With Limit or Cursor they must contain sort fields and primary key columns, from which the next cursor is built.
+	Fields []string
+	Limit  int
+	// Filter is a structured WHERE condition, values of which are passed as bind parameters.
+	Filter *Filter
+	// RawWhere is an SQL condition that is added into query as is.
+	// It's not safe against SQL injections, so it must be used only when it's explicitly allowed.
+	RawWhere string
+	// ExpandDepth is a number of foreign key levels, by which related rows are embedded into the row.
+	// Referenced row is embedded as an object and referencing rows as an array. Zero disables expanding.
+	ExpandDepth int
+	// Sort defines order of rows. Primary key is added to it, when rows are paginated, to make pages stable.
+	// Rows of tables without primary key are ordered by all selected columns instead, so they could not use Cursor.
+	Sort []Sort
+	// Offset is a number of rows skipped from the beginning, it could not be used with Cursor.
+	Offset int
+	// GroupBy are columns by which rows are grouped. Every group is returned as a JSON object with these columns
+	// and aggregates, so Fields and ExpandDepth could not be used with them.
+	GroupBy []string
+	// Aggregates are values computed for every group, or for all rows when GroupBy is empty.
+	Aggregates []Aggregate
+	// Cursor is a value returned by Query.NextCursor for the last row of the previous page.
+	// Rows after it are selected by their values, so it's faster than offset for large tables.
+	Cursor string
 }
 
-// Table represents a database table.
+// Table represents a database table or other object, rows of which could be selected like from a table.
 type Table struct {
-	SchemaName string `db:"schemaname"`
-	TableName  string `db:"tablename"`
+	SchemaName string    `db:"schemaname"`
+	TableName  string    `db:"tablename"`
+	Kind       TableKind `db:"kind"`
 }
 
+// TableKind represents a kind of table-like database object.
+type TableKind string
+
+const (
+	// TableKindTable - regular table.
+	TableKindTable TableKind = "table"
+	// TableKindView - view.
+	TableKindView TableKind = "view"
+	// TableKindMaterializedView - materialized view, which returns data from the last refresh.
+	TableKindMaterializedView TableKind = "materialized_view"
+	// TableKindForeignTable - table, data of which is stored in external server.
+	TableKindForeignTable TableKind = "foreign_table"
+)
+
 var (
 	// ErrDatabaseDoesNotExists - database does not exists.
 	ErrDatabaseDoesNotExists = errs.New("database does not exists")
@@ -50,4 +145,147 @@ var (
 	ErrInvalidPort = errs.New("invalid port")
 	// ErrNoAccess - no access to database.
 	ErrNoAccess = errs.New("no access")
+	// ErrInvalidDatabaseFile - database file is corrupted or has unknown format.
+	ErrInvalidDatabaseFile = errs.New("invalid database file")
+	// ErrTableDoesNotExist - table does not exist.
+	ErrTableDoesNotExist = errs.New("table does not exist")
+	// ErrInvalidExpandDepth - depth of foreign keys expanding is negative or greater than MaxExpandDepth.
+	ErrInvalidExpandDepth = errs.New("invalid expand depth")
+	// ErrInvalidTableName - table name could not be parsed.
+	ErrInvalidTableName = errs.New("invalid table name")
+	// ErrNotMaterializedView - object is not a materialized view, so it could not be refreshed.
+	ErrNotMaterializedView = errs.New("not a materialized view")
+	// ErrRowsCountUnknown - database has no statistics, from which number of rows could be estimated.
+	ErrRowsCountUnknown = errs.New("rows count unknown")
+	// ErrColumnDoesNotExist - column does not exist in the table.
+	ErrColumnDoesNotExist = errs.New("column does not exist")
+	// ErrInvalidFilter - filter has invalid structure, operator or value.
+	ErrInvalidFilter = errs.New("invalid filter")
+	// ErrInvalidSort - sort has unknown direction or nulls order, or it has duplicated fields.
+	ErrInvalidSort = errs.New("invalid sort")
+	// ErrInvalidAggregation - aggregate has unknown function, invalid or duplicated key, or aggregation is used with fields or expand.
+	ErrInvalidAggregation = errs.New("invalid aggregation")
+	// ErrInvalidCursor - cursor is corrupted, was created for another order or is used with offset.
+	ErrInvalidCursor = errs.New("invalid cursor")
+	// ErrOrderNotSelected - paginated rows do not contain columns by which they are ordered, so cursor could not be built.
+	ErrOrderNotSelected = errs.New("order not selected")
+	// ErrOrderNotUnique - rows are paginated by cursor, but their order is not unique, because table has no primary key.
+	ErrOrderNotUnique = errs.New("order not unique")
+	// ErrInvalidQuery - query written by user is not a single statement that only selects data.
+	ErrInvalidQuery = errs.New("invalid query")
+	// ErrQueryTimeout - query was canceled, because it was running longer than allowed.
+	ErrQueryTimeout = errs.New("query timeout")
+	// ErrInvalidConflictColumns - conflict columns of upsert do not match primary key or unique index of the table.
+	ErrInvalidConflictColumns = errs.New("invalid conflict columns")
+	// ErrInvalidImport - imported rows have no columns or values could not be converted into bind parameters.
+	ErrInvalidImport = errs.New("invalid import")
+	// ErrInvalidTableDefinition - created table has no columns, duplicated or unknown columns, or unknown kind of values.
+	ErrInvalidTableDefinition = errs.New("invalid table definition")
+	// ErrTooManyClients - client pool is full and all clients are busy.
+	ErrTooManyClients = errs.New("too many clients")
 )
+
+// handleNetworkError converts network errors that could occur during connection to the database into expected errors.
+func handleNetworkError(err error) error {
+	opErr := &net.OpError{}
+	if errors.As(err, &opErr) {
+		if strings.Contains(opErr.Error(), "no such host") {
+			return ErrInvalidHost
+		}
+		if strings.Contains(opErr.Error(), "connect: connection refused") {
+			return ErrInvalidPort
+		}
+	}
+
+	return nil
+}
+
+// applyConnectionLimits applies connection limits from options to the database handle.
+func applyConnectionLimits(db *sqlx.DB, options ConnectionOptions) {
+	if options.MaxOpenConnections != 0 {
+		db.SetMaxOpenConns(options.MaxOpenConnections)
+	}
+	if options.MaxIdleConnections != 0 {
+		db.SetMaxIdleConns(options.MaxIdleConnections)
+	}
+}
+
+// closeDB closes the database handle with all its connections.
+func closeDB(logger logger.Logger, db *sqlx.DB) error {
+	err := db.Close()
+	if err != nil {
+		logger.Error("close database connection", "err", err)
+		return fmt.Errorf("close database connection: %w", err)
+	}
+
+	logger.Debug("closed database connection")
+	return nil
+}
+
+// getDBStats returns statistics of the database handle connections.
+func getDBStats(db *sqlx.DB) DBStats {
+	stats := db.Stats()
+
+	return DBStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+	}
+}
+
+// queryer runs queries, it's implemented by database handle and transaction.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// executeQuery runs a query which returns a single JSON column and collects all rows.
+func executeQuery(ctx context.Context, logger logger.Logger, db queryer, query Query) ([]string, error) {
+	var result []string
+	err := streamQuery(ctx, logger, db, query, func(row []byte) error {
+		result = append(result, string(row))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("got result", "result", result)
+
+	return result, nil
+}
+
+// streamQuery runs a query which returns a single JSON column and passes rows to handleRow one by one.
+func streamQuery(ctx context.Context, logger logger.Logger, db queryer, query Query, handleRow func(row []byte) error) error {
+	rows, err := db.QueryContext(ctx, query.Text, query.Args...)
+	if err != nil {
+		logger.Error("run query", "err", err)
+		return fmt.Errorf("run query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row sql.RawBytes
+
+		// Stream must not be finished successfully with missing rows, so scan error stops it.
+		err := rows.Scan(&row)
+		if err != nil {
+			logger.Error("scan row", "err", err)
+			return fmt.Errorf("scan row: %w", err)
+		}
+
+		err = handleRow(row)
+		if err != nil {
+			logger.Error("handle row", "err", err)
+			return fmt.Errorf("handle row: %w", err)
+		}
+	}
+	if rows.Err() != nil {
+		logger.Error("got sql rows error", "rows.Err", rows.Err())
+		return fmt.Errorf("got sql rows error: %w", rows.Err())
+	}
+
+	return nil
+}
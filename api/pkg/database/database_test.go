@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/VladPetriv/d2j/pkg/logger"
+)
+
+func TestStreamQueryScanError(t *testing.T) {
+	t.Parallel()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+
+	// Rows of two columns could not be scanned into a single JSON value.
+	query := Query{Text: "SELECT '{}', 1 UNION ALL SELECT '{}', 2"}
+
+	var handledRows int
+	err = streamQuery(context.Background(), logger.NewSlog("error"), db, query, func(row []byte) error {
+		handledRows++
+
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("streamQuery() succeeded, want scan error")
+	}
+	if handledRows != 0 {
+		t.Errorf("handled %d rows, want 0", handledRows)
+	}
+}
@@ -0,0 +1,156 @@
+package database
+
+// TableDescription represents structure of a database table.
+type TableDescription struct {
+	// Dialect is a kind of the database, which defines how column types should be interpreted.
+	Dialect     Dialect           `json:"dialect"`
+	SchemaName  string            `json:"schemaName"`
+	TableName   string            `json:"tableName"`
+	Columns     []Column          `json:"columns"`
+	PrimaryKey  []string          `json:"primaryKey"`
+	ForeignKeys []ForeignKey      `json:"foreignKeys"`
+	Indexes     []Index           `json:"indexes"`
+	Checks      []CheckConstraint `json:"checks"`
+}
+
+// Column represents a table column.
+type Column struct {
+	Name string `json:"name" db:"name"`
+	// DataType is a type of the column as it's reported by the database, e.g. "character varying(255)".
+	DataType string `json:"dataType" db:"data_type"`
+	Nullable bool   `json:"nullable" db:"nullable"`
+	// Default is an SQL expression of the column default value, it's nil when column has no default.
+	Default *string `json:"default" db:"default_value"`
+	Comment *string `json:"comment" db:"comment"`
+	// EnumValues contains allowed values of enum column in their declaration order, it's empty for other columns.
+	EnumValues []string `json:"enumValues,omitempty" db:"-"`
+}
+
+// CheckConstraint represents a check constraint of the table.
+type CheckConstraint struct {
+	// Name is a constraint name, it's empty when constraint is not named.
+	Name string `json:"name" db:"name"`
+	// Expression is a boolean SQL expression of the constraint without CHECK keyword.
+	Expression string `json:"expression" db:"expression"`
+}
+
+// ForeignKey represents a foreign key constraint of the table.
+type ForeignKey struct {
+	// Name is a constraint name, it could be empty for databases that do not name constraints.
+	Name              string   `json:"name"`
+	Columns           []string `json:"columns"`
+	ReferencedSchema  string   `json:"referencedSchema"`
+	ReferencedTable   string   `json:"referencedTable"`
+	ReferencedColumns []string `json:"referencedColumns"`
+	OnUpdate          string   `json:"onUpdate"`
+	OnDelete          string   `json:"onDelete"`
+}
+
+// Index represents a table index.
+type Index struct {
+	Name string `json:"name"`
+	// Columns contains indexed columns in index order. Expression is used instead of column when database exposes it.
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+	Primary bool     `json:"primary"`
+}
+
+// indexColumn represents a single column of the index as it's returned by introspection queries.
+type indexColumn struct {
+	IndexName  string `db:"index_name"`
+	ColumnName string `db:"column_name"`
+	IsUnique   bool   `db:"is_unique"`
+	IsPrimary  bool   `db:"is_primary"`
+}
+
+// foreignKeyColumn represents a single column pair of the foreign key as it's returned by introspection queries.
+type foreignKeyColumn struct {
+	// ConstraintKey identifies constraint, because not all databases have constraint names.
+	ConstraintKey    string  `db:"constraint_key"`
+	Name             string  `db:"name"`
+	ColumnName       string  `db:"column_name"`
+	ReferencedSchema string  `db:"referenced_schema"`
+	ReferencedTable  string  `db:"referenced_table"`
+	ReferencedColumn *string `db:"referenced_column"`
+	OnUpdate         string  `db:"on_update"`
+	OnDelete         string  `db:"on_delete"`
+}
+
+// enumValue represents a single value of enum column as it's returned by introspection queries.
+type enumValue struct {
+	ColumnName string `db:"column_name"`
+	Value      string `db:"value"`
+}
+
+// setEnumValues sets enum values, which must be ordered by declaration order, to the columns.
+func setEnumValues(columns []Column, values []enumValue) {
+	for _, value := range values {
+		for i := range columns {
+			if columns[i].Name == value.ColumnName {
+				columns[i].EnumValues = append(columns[i].EnumValues, value.Value)
+			}
+		}
+	}
+}
+
+// groupIndexes groups index columns, which must be ordered by index and position, into indexes.
+func groupIndexes(columns []indexColumn) []Index {
+	indexes := make([]Index, 0)
+
+	for _, column := range columns {
+		last := len(indexes) - 1
+		if last < 0 || indexes[last].Name != column.IndexName {
+			indexes = append(indexes, Index{
+				Name:    column.IndexName,
+				Unique:  column.IsUnique,
+				Primary: column.IsPrimary,
+			})
+			last++
+		}
+
+		indexes[last].Columns = append(indexes[last].Columns, column.ColumnName)
+	}
+
+	return indexes
+}
+
+// groupForeignKeys groups foreign key columns, which must be ordered by constraint and position, into foreign keys.
+func groupForeignKeys(columns []foreignKeyColumn) []ForeignKey {
+	foreignKeys := make([]ForeignKey, 0)
+
+	var lastKey string
+
+	for i, column := range columns {
+		if i == 0 || column.ConstraintKey != lastKey {
+			foreignKeys = append(foreignKeys, ForeignKey{
+				Name:             column.Name,
+				ReferencedSchema: column.ReferencedSchema,
+				ReferencedTable:  column.ReferencedTable,
+				OnUpdate:         column.OnUpdate,
+				OnDelete:         column.OnDelete,
+			})
+			lastKey = column.ConstraintKey
+		}
+
+		foreignKey := &foreignKeys[len(foreignKeys)-1]
+		foreignKey.Columns = append(foreignKey.Columns, column.ColumnName)
+
+		// Referenced column is unknown when foreign key implicitly references primary key of the table.
+		if column.ReferencedColumn != nil {
+			foreignKey.ReferencedColumns = append(foreignKey.ReferencedColumns, *column.ReferencedColumn)
+		}
+	}
+
+	return foreignKeys
+}
+
+// primaryKeyFromIndexes returns columns of the primary index.
+func primaryKeyFromIndexes(indexes []Index) []string {
+	for _, index := range indexes {
+		if index.Primary {
+			return index.Columns
+		}
+	}
+
+	return make([]string, 0)
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxExpandDepth is a maximum number of foreign key levels that could be expanded.
+const MaxExpandDepth = 3
+
+// relation represents a foreign key from the point of view of one of the tables it connects.
+type relation struct {
+	// ConstraintKey identifies foreign key, it's the same for both sides of the relation.
+	ConstraintKey string
+	// Many is true when related table references the table, so there could be many related rows.
+	Many bool
+	// Table is a related table.
+	Table TableName
+	// Columns are columns of the table, which are compared with RelatedColumns of the related table.
+	Columns        []string
+	RelatedColumns []string
+}
+
+// relationColumn represents a single column pair of the relation as it's returned by introspection queries.
+type relationColumn struct {
+	ConstraintKey string  `db:"constraint_key"`
+	Many          bool    `db:"many"`
+	RelatedSchema string  `db:"related_schema"`
+	RelatedTable  string  `db:"related_table"`
+	ColumnName    *string `db:"column_name"`
+	RelatedColumn *string `db:"related_column"`
+	Position      int     `db:"position"`
+}
+
+// groupRelations groups relation columns, which must be ordered by relation and position, into relations.
+func groupRelations(columns []relationColumn) []relation {
+	relations := make([]relation, 0)
+
+	for i, column := range columns {
+		if i == 0 || column.ConstraintKey != columns[i-1].ConstraintKey || column.Many != columns[i-1].Many {
+			relations = append(relations, relation{
+				ConstraintKey: column.ConstraintKey,
+				Many:          column.Many,
+				Table:         TableName{Schema: column.RelatedSchema, Name: column.RelatedTable},
+			})
+		}
+
+		last := &relations[len(relations)-1]
+		if column.ColumnName != nil {
+			last.Columns = append(last.Columns, *column.ColumnName)
+		}
+		if column.RelatedColumn != nil {
+			last.RelatedColumns = append(last.RelatedColumns, *column.RelatedColumn)
+		}
+	}
+
+	return relations
+}
+
+// expandBuilder builds expressions that embed related rows into JSON objects of the rows.
+type expandBuilder struct {
+	dialect sqlDialect
+	lister  structureLister
+
+	aliasesCount int
+}
+
+// relationArguments returns JSON object arguments with related rows of the table row, which is available by alias,
+// and names of their keys. Keys do not conflict with columns of the table.
+// Relation, by which the table is reached, is not expanded back to avoid repeating the parent row.
+func (b *expandBuilder) relationArguments(
+	table TableName, alias string, columns []string, depth int, parent *relation,
+) ([]string, []string, error) {
+	relations, err := b.lister.listRelations(table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list relations of %s: %w", table, err)
+	}
+
+	var (
+		arguments []string
+		keys      []string
+	)
+
+	for _, rel := range relations {
+		if parent != nil && rel.ConstraintKey == parent.ConstraintKey && rel.Many != parent.Many {
+			continue
+		}
+		if len(rel.Columns) == 0 || len(rel.Columns) != len(rel.RelatedColumns) {
+			continue
+		}
+
+		key := relationKey(rel, append(slices.Clip(columns), keys...))
+
+		expression, err := b.relationExpression(rel, alias, depth)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		arguments = append(arguments, fmt.Sprintf("%s, %s", b.dialect.quoteString(key), expression))
+		keys = append(keys, key)
+	}
+
+	return arguments, keys, nil
+}
+
+// relationExpression returns JSON expression with the related row or with array of related rows.
+func (b *expandBuilder) relationExpression(rel relation, parentAlias string, depth int) (string, error) {
+	columns, err := b.lister.listColumns(rel.Table)
+	if err != nil {
+		return "", fmt.Errorf("list columns of %s: %w", rel.Table, err)
+	}
+
+	b.aliasesCount++
+	alias := b.dialect.quoteIdentifier("d2j_expand_" + strconv.Itoa(b.aliasesCount))
+
+	var nestedArguments []string
+	if depth > 1 {
+		nestedArguments, _, err = b.relationArguments(rel.Table, alias, columns, depth-1, &rel)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	conditions := make([]string, len(rel.Columns))
+	for i := range rel.Columns {
+		conditions[i] = fmt.Sprintf(
+			"%s.%s = %s.%s",
+			alias, b.dialect.quoteIdentifier(rel.RelatedColumns[i]), parentAlias, b.dialect.quoteIdentifier(rel.Columns[i]),
+		)
+	}
+
+	row := b.dialect.rowToJSON(alias, columns, nestedArguments)
+	from := fmt.Sprintf("%s AS %s WHERE %s", quoteTableName(b.dialect, rel.Table), alias, strings.Join(conditions, " AND "))
+
+	if rel.Many {
+		return b.dialect.nestedJSON(fmt.Sprintf("SELECT %s FROM %s", b.dialect.aggregateJSON(row), from)), nil
+	}
+
+	return b.dialect.nestedJSON(fmt.Sprintf("SELECT %s FROM %s LIMIT 1", row, from)), nil
+}
+
+// relationKey returns a key of related rows, which does not conflict with used keys.
+// Single row is named by the foreign key column without "_id" suffix or by the related table,
+// and list of rows is named by the related table.
+func relationKey(rel relation, usedKeys []string) string {
+	key := rel.Table.Name
+
+	if !rel.Many && len(rel.Columns) == 1 {
+		column := rel.Columns[0]
+		if len(column) > len("_id") && strings.EqualFold(column[len(column)-len("_id"):], "_id") {
+			key = column[:len(column)-len("_id")]
+		}
+	}
+
+	if !slices.Contains(usedKeys, key) {
+		return key
+	}
+
+	// Columns of the foreign key distinguish relations with the same table.
+	columns := rel.RelatedColumns
+	if !rel.Many {
+		columns = rel.Columns
+	}
+	key = rel.Table.Name + "_by_" + strings.Join(columns, "_")
+
+	unique := key
+	for i := 2; slices.Contains(usedKeys, unique); i++ {
+		unique = key + "_" + strconv.Itoa(i)
+	}
+
+	return unique
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSQLiteBuildQueryExpand(t *testing.T) {
+	t.Parallel()
+
+	client := newTestSQLiteClient(t)
+
+	t.Run("referenced row", func(t *testing.T) {
+		t.Parallel()
+
+		query, rows := selectRows(t, client, BuildQueryOptions{
+			TableName:   "books",
+			ExpandDepth: 1,
+			Sort:        []Sort{{Field: "id"}},
+		})
+
+		if !slices.Contains(query.Columns, "author") {
+			t.Errorf("query columns = %v, want author key", query.Columns)
+		}
+		if got := string(rows[0]["author"]); got != `{"id":1,"name":"Ann"}` {
+			t.Errorf("author of the first book = %s, want Ann", got)
+		}
+		// Book without author has no referenced row.
+		if got := string(rows[4]["author"]); got != "null" {
+			t.Errorf("author of the last book = %s, want null", got)
+		}
+	})
+
+	t.Run("referencing rows with nested row", func(t *testing.T) {
+		t.Parallel()
+
+		_, rows := selectRows(t, client, BuildQueryOptions{
+			TableName:   "authors",
+			Fields:      []string{"id"},
+			ExpandDepth: 2,
+			Sort:        []Sort{{Field: "id"}},
+		})
+		if len(rows) != 2 {
+			t.Fatalf("selected %d authors, want 2", len(rows))
+		}
+
+		var books []map[string]json.RawMessage
+		err := json.Unmarshal(rows[1]["books"], &books)
+		if err != nil {
+			t.Fatalf("unmarshal books %s: %v", rows[1]["books"], err)
+		}
+
+		ids := rowIDs(books)
+		slices.Sort(ids)
+		if !slices.Equal(ids, []string{"3", "4"}) {
+			t.Errorf("books of the second author = %v, want [3 4]", ids)
+		}
+		// Parent row is not expanded back from its books.
+		if _, ok := books[0]["author"]; ok {
+			t.Errorf("book contains expanded author %s", books[0]["author"])
+		}
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := client.BuildQuery(BuildQueryOptions{TableName: "books", ExpandDepth: MaxExpandDepth + 1})
+		if !errors.Is(err, ErrInvalidExpandDepth) {
+			t.Fatalf("BuildQuery() error = %v, want %v", err, ErrInvalidExpandDepth)
+		}
+	})
+}
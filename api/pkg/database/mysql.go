@@ -0,0 +1,723 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/VladPetriv/d2j/pkg/errs"
+	"github.com/VladPetriv/d2j/pkg/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+type mySQL struct {
+	logger logger.Logger
+}
+
+var _ Database = (*mySQL)(nil)
+
+// NewMySQL is used to create an instance of mysql database.
+// It could be used for both MySQL and MariaDB servers.
+func NewMySQL(logger logger.Logger) *mySQL {
+	return &mySQL{
+		logger: logger,
+	}
+}
+
+type mySQLClient struct {
+	logger logger.Logger
+
+	db *sqlx.DB
+}
+
+var _ DBClient = (*mySQLClient)(nil)
+
+func newMySQLClient(logger logger.Logger, db *sqlx.DB) *mySQLClient {
+	return &mySQLClient{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (m *mySQL) Connect(options ConnectionOptions) (DBClient, error) {
+	logger := m.logger.Named("mySQL.Connect")
+
+	db, err := sqlx.Connect("mysql", buildMySQLDataSourceName(options))
+	if err != nil {
+		if mysqlErr := handleMySQLError(err); mysqlErr != nil {
+			logger.Info(mysqlErr.Error())
+			return nil, mysqlErr
+		}
+
+		logger.Error("connect to mysql", "err", err)
+		return nil, fmt.Errorf("connect to mysql: %w", err)
+	}
+
+	applyConnectionLimits(db, options)
+
+	logger.Info("connected to mysql")
+	return newMySQLClient(logger, db), nil
+}
+
+func buildMySQLDataSourceName(options ConnectionOptions) string {
+	config := mysql.NewConfig()
+	config.User = options.Username
+	config.Passwd = options.Password
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(options.Host, strconv.Itoa(options.Port))
+	config.DBName = options.DatabaseName
+
+	// Use TLS without certificate verification, which matches "sslmode=require" of PostgreSQL.
+	if options.SSLModeEnabled {
+		config.TLSConfig = "skip-verify"
+	}
+
+	return config.FormatDSN()
+}
+
+func (m *mySQLClient) BuildQuery(options BuildQueryOptions) (Query, error) {
+	logger := m.logger.Named("mySQLClient.BuildQuery")
+
+	table, err := ParseTableName(options.TableName)
+	if err != nil {
+		logger.Info(err.Error())
+		return Query{}, err
+	}
+
+	columns, err := m.listColumns(table)
+	if err != nil {
+		logger.Error("list table columns", "err", err)
+		return Query{}, fmt.Errorf("list table columns: %w", err)
+	}
+	logger.Debug("got table columns", "columns", columns)
+
+	return buildSelectQuery(mySQLDialect{}, table, options, columns, m)
+}
+
+func (m *mySQLClient) listColumns(table TableName) ([]string, error) {
+	var columns []string
+	err := m.db.Select(
+		&columns,
+		`SELECT column_name FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?
+		ORDER BY ordinal_position;`,
+		table.Schema, table.Name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select mysql column names: %w", err)
+	}
+
+	return columns, nil
+}
+
+func (m *mySQLClient) listRelations(table TableName) ([]relation, error) {
+	var columns []relationColumn
+	err := m.db.Select(
+		&columns,
+		`SELECT CONCAT_WS('.', table_schema, table_name, constraint_name) AS constraint_key,
+			false AS many,
+			referenced_table_schema AS related_schema,
+			referenced_table_name AS related_table,
+			column_name AS column_name,
+			referenced_column_name AS related_column,
+			ordinal_position AS position
+		FROM information_schema.key_column_usage
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ? AND referenced_table_name IS NOT NULL
+		UNION ALL
+		SELECT CONCAT_WS('.', table_schema, table_name, constraint_name) AS constraint_key,
+			true AS many,
+			table_schema AS related_schema,
+			table_name AS related_table,
+			referenced_column_name AS column_name,
+			column_name AS related_column,
+			ordinal_position AS position
+		FROM information_schema.key_column_usage
+		WHERE referenced_table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND referenced_table_name = ?
+		ORDER BY many, related_schema, related_table, constraint_key, position;`,
+		table.Schema, table.Name, table.Schema, table.Name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select mysql foreign keys: %w", err)
+	}
+
+	return groupRelations(columns), nil
+}
+
+func (m *mySQLClient) listPrimaryKey(table TableName) ([]string, error) {
+	var columns []string
+	err := m.db.Select(
+		&columns,
+		`SELECT column_name FROM information_schema.key_column_usage
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ? AND constraint_name = 'PRIMARY'
+		ORDER BY ordinal_position;`,
+		table.Schema, table.Name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select mysql primary key: %w", err)
+	}
+
+	return columns, nil
+}
+
+// ListSchemas returns databases of the server, because schema is a synonym of database in MySQL.
+func (m *mySQLClient) ListSchemas(ctx context.Context) ([]string, error) {
+	logger := m.logger.Named("mySQLClient.ListSchemas")
+
+	var schemas []string
+	err := m.db.SelectContext(
+		ctx,
+		&schemas,
+		`SELECT schema_name FROM information_schema.schemata
+		WHERE schema_name NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys')
+		ORDER BY schema_name;`,
+	)
+	if err != nil {
+		logger.Error("select mysql schema names", "err", err)
+		return nil, fmt.Errorf("select mysql schema names: %w", err)
+	}
+	logger.Debug("got all mysql schemas", "schemas", schemas)
+
+	return schemas, nil
+}
+
+func (m *mySQLClient) ListTables(ctx context.Context, schemaName string) ([]Table, error) {
+	logger := m.logger.Named("mySQLClient.ListTables")
+
+	var tables []Table
+	err := m.db.SelectContext(
+		ctx,
+		&tables,
+		`SELECT table_schema AS schemaname, table_name AS tablename,
+			CASE table_type WHEN 'VIEW' THEN 'view' ELSE 'table' END AS kind
+		FROM information_schema.tables
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_type IN ('BASE TABLE', 'VIEW')
+		ORDER BY table_name;`,
+		schemaName,
+	)
+	if err != nil {
+		logger.Error("select mysql table names", "err", err)
+		return nil, fmt.Errorf("select mysql table names: %w", err)
+	}
+	logger.Debug("got mysql tables", "tables", tables)
+
+	return tables, nil
+}
+
+// RefreshMaterializedView always fails, because MySQL does not have materialized views.
+func (m *mySQLClient) RefreshMaterializedView(_ context.Context, tableName string) error {
+	return fmt.Errorf("%w: %s", ErrNotMaterializedView, tableName)
+}
+
+func (m *mySQLClient) DescribeTable(ctx context.Context, tableName string) (*TableDescription, error) {
+	logger := m.logger.Named("mySQLClient.DescribeTable")
+
+	table, err := ParseTableName(tableName)
+	if err != nil {
+		logger.Info(err.Error())
+		return nil, err
+	}
+	tableName = table.Name
+
+	var schemaName string
+	err = m.db.GetContext(ctx, &schemaName, "SELECT COALESCE(NULLIF(?, ''), DATABASE());", table.Schema)
+	if err != nil {
+		logger.Error("get mysql schema name", "err", err)
+		return nil, fmt.Errorf("get mysql schema name: %w", err)
+	}
+
+	var columns []Column
+	err = m.db.SelectContext(
+		ctx,
+		&columns,
+		`SELECT column_name AS name,
+			column_type AS data_type,
+			is_nullable = 'YES' AS nullable,
+			column_default AS default_value,
+			NULLIF(column_comment, '') AS comment
+		FROM information_schema.columns
+		WHERE table_schema = ? AND table_name = ?
+		ORDER BY ordinal_position;`,
+		schemaName, tableName,
+	)
+	if err != nil {
+		logger.Error("select mysql columns", "err", err)
+		return nil, fmt.Errorf("select mysql columns: %w", err)
+	}
+	if len(columns) == 0 {
+		logger.Info(ErrTableDoesNotExist.Error(), "tableName", tableName)
+		return nil, fmt.Errorf("%w: %s", ErrTableDoesNotExist, tableName)
+	}
+	logger.Debug("got table columns", "columns", columns)
+
+	for i, column := range columns {
+		columns[i].EnumValues = parseMySQLEnumValues(column.DataType)
+	}
+
+	// Functional indexes do not have column name, so an empty name is returned for them.
+	var indexColumns []indexColumn
+	err = m.db.SelectContext(
+		ctx,
+		&indexColumns,
+		`SELECT index_name AS index_name,
+			COALESCE(column_name, '') AS column_name,
+			non_unique = 0 AS is_unique,
+			index_name = 'PRIMARY' AS is_primary
+		FROM information_schema.statistics
+		WHERE table_schema = ? AND table_name = ?
+		ORDER BY index_name, seq_in_index;`,
+		schemaName, tableName,
+	)
+	if err != nil {
+		logger.Error("select mysql indexes", "err", err)
+		return nil, fmt.Errorf("select mysql indexes: %w", err)
+	}
+	logger.Debug("got table indexes", "indexColumns", indexColumns)
+
+	var foreignKeyColumns []foreignKeyColumn
+	err = m.db.SelectContext(
+		ctx,
+		&foreignKeyColumns,
+		`SELECT kcu.constraint_name AS constraint_key,
+			kcu.constraint_name AS name,
+			kcu.column_name AS column_name,
+			kcu.referenced_table_schema AS referenced_schema,
+			kcu.referenced_table_name AS referenced_table,
+			kcu.referenced_column_name AS referenced_column,
+			rc.update_rule AS on_update,
+			rc.delete_rule AS on_delete
+		FROM information_schema.key_column_usage kcu
+		JOIN information_schema.referential_constraints rc
+			ON rc.constraint_schema = kcu.constraint_schema
+			AND rc.constraint_name = kcu.constraint_name
+			AND rc.table_name = kcu.table_name
+		WHERE kcu.table_schema = ? AND kcu.table_name = ? AND kcu.referenced_table_name IS NOT NULL
+		ORDER BY kcu.constraint_name, kcu.ordinal_position;`,
+		schemaName, tableName,
+	)
+	if err != nil {
+		logger.Error("select mysql foreign keys", "err", err)
+		return nil, fmt.Errorf("select mysql foreign keys: %w", err)
+	}
+	logger.Debug("got table foreign keys", "foreignKeyColumns", foreignKeyColumns)
+
+	checks, err := m.listCheckConstraints(ctx, schemaName, tableName)
+	if err != nil {
+		logger.Error("list mysql check constraints", "err", err)
+		return nil, fmt.Errorf("list mysql check constraints: %w", err)
+	}
+	logger.Debug("got table check constraints", "checks", checks)
+
+	indexes := groupIndexes(indexColumns)
+
+	return &TableDescription{
+		Dialect:     DialectMySQL,
+		SchemaName:  schemaName,
+		TableName:   tableName,
+		Columns:     columns,
+		PrimaryKey:  primaryKeyFromIndexes(indexes),
+		ForeignKeys: groupForeignKeys(foreignKeyColumns),
+		Indexes:     indexes,
+		Checks:      checks,
+	}, nil
+}
+
+// listCheckConstraints returns check constraints of the table.
+// Constraints are not returned by servers that do not support them, e.g. MySQL before 8.0.16.
+func (m *mySQLClient) listCheckConstraints(ctx context.Context, schemaName, tableName string) ([]CheckConstraint, error) {
+	checks := make([]CheckConstraint, 0)
+
+	var supported bool
+	err := m.db.GetContext(
+		ctx,
+		&supported,
+		`SELECT COUNT(*) > 0
+		FROM information_schema.tables
+		WHERE table_schema = 'information_schema' AND table_name = 'CHECK_CONSTRAINTS';`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("check support of check constraints: %w", err)
+	}
+	if !supported {
+		return checks, nil
+	}
+
+	err = m.db.SelectContext(
+		ctx,
+		&checks,
+		`SELECT tc.constraint_name AS name, cc.check_clause AS expression
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.check_constraints cc
+			ON cc.constraint_schema = tc.constraint_schema
+			AND cc.constraint_name = tc.constraint_name
+		WHERE tc.constraint_type = 'CHECK' AND tc.table_schema = ? AND tc.table_name = ?
+		ORDER BY tc.constraint_name;`,
+		schemaName, tableName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select check constraints: %w", err)
+	}
+
+	return checks, nil
+}
+
+// parseMySQLEnumValues returns values of enum column type like "enum('a','b')", it returns nil for other types.
+func parseMySQLEnumValues(columnType string) []string {
+	if !strings.HasPrefix(strings.ToLower(columnType), "enum(") || !strings.HasSuffix(columnType, ")") {
+		return nil
+	}
+
+	var values []string
+	var value strings.Builder
+	quoted := false
+
+	list := columnType[len("enum(") : len(columnType)-1]
+	for i := 0; i < len(list); i++ {
+		char := list[i]
+
+		switch {
+		case char == '\'' && quoted && i+1 < len(list) && list[i+1] == '\'':
+			// Quotes inside of values are escaped by doubling.
+			value.WriteByte('\'')
+			i++
+
+		case char == '\'':
+			quoted = !quoted
+			if !quoted {
+				values = append(values, value.String())
+				value.Reset()
+			}
+
+		case quoted:
+			value.WriteByte(char)
+		}
+	}
+
+	return values
+}
+
+// EstimateRowsCount returns number of rows from table statistics, which is approximate for InnoDB tables.
+func (m *mySQLClient) EstimateRowsCount(ctx context.Context, tableName string) (int64, error) {
+	logger := m.logger.Named("mySQLClient.EstimateRowsCount")
+
+	table, err := ParseTableName(tableName)
+	if err != nil {
+		logger.Info(err.Error())
+		return 0, err
+	}
+
+	var estimates []sql.NullInt64
+	err = m.db.SelectContext(
+		ctx,
+		&estimates,
+		`SELECT table_rows FROM information_schema.tables
+		WHERE table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND table_name = ?;`,
+		table.Schema, table.Name,
+	)
+	if err != nil {
+		logger.Error("select mysql table rows", "err", err)
+		return 0, fmt.Errorf("select mysql table rows: %w", err)
+	}
+	if len(estimates) == 0 {
+		logger.Info(ErrTableDoesNotExist.Error(), "tableName", tableName)
+		return 0, fmt.Errorf("%w: %s", ErrTableDoesNotExist, tableName)
+	}
+
+	// Rows are unknown for views.
+	if !estimates[0].Valid {
+		logger.Debug("mysql table has no statistics", "tableName", tableName)
+		return 0, ErrRowsCountUnknown
+	}
+
+	return estimates[0].Int64, nil
+}
+
+func (m *mySQLClient) ExecuteQuery(ctx context.Context, query Query) ([]string, error) {
+	return executeQuery(ctx, m.logger.Named("mySQLClient.ExecuteQuery"), m.db, query)
+}
+
+func (m *mySQLClient) ExecuteReadOnlyQuery(ctx context.Context, options ReadOnlyQueryOptions) ([]string, error) {
+	logger := m.logger.Named("mySQLClient.ExecuteReadOnlyQuery")
+
+	text, err := validateReadOnlyQuery(mySQLDialect{}, options.Text)
+	if err != nil {
+		logger.Info(err.Error())
+		return nil, err
+	}
+
+	result, err := executeReadOnlyQuery(ctx, logger, m.db, options, func(ctx context.Context, tx *sqlx.Tx) (Query, error) {
+		// MySQL does not have an analog of PostgreSQL to_jsonb(row), so object is built from the columns of the query.
+		columns, err := listReadOnlyQueryColumns(ctx, tx, text)
+		if err != nil {
+			return Query{}, fmt.Errorf("list query columns: %w", err)
+		}
+
+		rowToJSON := mySQLDialect{}.fieldsToJSON(columns, nil)
+
+		// Timeout is also set on the server by optimizer hint, which is ignored by MariaDB.
+		if options.Timeout != 0 {
+			rowToJSON = fmt.Sprintf("/*+ MAX_EXECUTION_TIME(%d) */ %s", options.Timeout.Milliseconds(), rowToJSON)
+		}
+
+		return wrapReadOnlyQuery(rowToJSON, text, options.Limit), nil
+	})
+	if err != nil {
+		mysqlErr := &mysql.MySQLError{}
+		switch {
+		case errors.Is(err, ErrQueryTimeout), errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrQueryTimeout:
+			logger.Info(ErrQueryTimeout.Error())
+			return nil, ErrQueryTimeout
+
+		case errors.As(err, &mysqlErr):
+			logger.Info(mysqlErr.Message)
+			return nil, mySQLQueryError(err)
+		}
+
+		logger.Error("execute read-only query", "err", err)
+		return nil, fmt.Errorf("execute read-only query: %w", err)
+	}
+
+	return result, nil
+}
+
+func (m *mySQLClient) StreamQuery(ctx context.Context, query Query, handleRow func(row []byte) error) error {
+	return streamQuery(ctx, m.logger.Named("mySQLClient.StreamQuery"), m.db, query, handleRow)
+}
+
+// BeginSnapshot uses REPEATABLE READ isolation, in which consistent reads see the snapshot of the first one.
+func (m *mySQLClient) BeginSnapshot(ctx context.Context) (Snapshot, error) {
+	logger := m.logger.Named("mySQLClient.BeginSnapshot")
+
+	snapshot, err := beginSnapshot(ctx, m.logger.Named("mySQLClient.snapshot"), m.db, sql.LevelRepeatableRead)
+	if err != nil {
+		logger.Error("begin snapshot", "err", err)
+		return nil, fmt.Errorf("begin snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+func (m *mySQLClient) BeginImport(ctx context.Context, options ImportOptions) (Importer, error) {
+	logger := m.logger.Named("mySQLClient.BeginImport")
+
+	description, err := m.DescribeTable(ctx, options.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	importer, err := beginImport(ctx, m.db, mySQLDialect{}, description, options, mySQLQueryError)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("begin import", "err", err)
+		return nil, fmt.Errorf("begin import: %w", err)
+	}
+
+	return importer, nil
+}
+
+// mySQLQueryError converts an error reported by the database into *QueryError, nil is returned for other errors.
+func mySQLQueryError(err error) *QueryError {
+	mysqlErr := &mysql.MySQLError{}
+	if !errors.As(err, &mysqlErr) {
+		return nil
+	}
+
+	return &QueryError{Message: mysqlErr.Message}
+}
+
+func (m *mySQLClient) BuildCreateTableQuery(options CreateTableOptions) (Query, error) {
+	logger := m.logger.Named("mySQLClient.BuildCreateTableQuery")
+
+	query, err := buildCreateTableQuery(mySQLDialect{}, options)
+	if err != nil {
+		logger.Info(err.Error())
+		return Query{}, err
+	}
+
+	return query, nil
+}
+
+func (m *mySQLClient) CreateTable(ctx context.Context, options CreateTableOptions) error {
+	logger := m.logger.Named("mySQLClient.CreateTable")
+
+	query, err := buildCreateTableQuery(mySQLDialect{}, options)
+	if err != nil {
+		logger.Info(err.Error())
+		return err
+	}
+
+	_, err = m.db.ExecContext(ctx, query.Text)
+	if err != nil {
+		if queryErr := mySQLQueryError(err); queryErr != nil {
+			logger.Info(queryErr.Message)
+			return queryErr
+		}
+
+		logger.Error("create table", "err", err)
+		return fmt.Errorf("create table: %w", err)
+	}
+
+	return nil
+}
+
+func (m *mySQLClient) Stats() DBStats {
+	return getDBStats(m.db)
+}
+
+func (m *mySQLClient) Close() error {
+	return closeDB(m.logger.Named("mySQLClient.Close"), m.db)
+}
+
+type mySQLDialect struct{}
+
+var _ sqlDialect = (*mySQLDialect)(nil)
+
+// mySQLStringReplacer escapes characters that could close MySQL string literal.
+var mySQLStringReplacer = strings.NewReplacer(`\`, `\\`, "'", "''")
+
+func (mySQLDialect) quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// quoteString escapes backslashes too, because they are escape characters in MySQL strings by default.
+// Quotes are doubled instead of escaped, so the literal is closed correctly even with NO_BACKSLASH_ESCAPES mode.
+func (mySQLDialect) quoteString(value string) string {
+	return "'" + mySQLStringReplacer.Replace(value) + "'"
+}
+
+func (mySQLDialect) placeholder(_ int) string {
+	return "?"
+}
+
+// rowToJSON builds JSON object from all columns, because MySQL does not have an analog of PostgreSQL to_jsonb(row).
+func (d mySQLDialect) rowToJSON(_ string, columns []string, arguments []string) string {
+	return d.fieldsToJSON(columns, arguments)
+}
+
+func (d mySQLDialect) fieldsToJSON(fields []string, arguments []string) string {
+	return fmt.Sprintf("JSON_OBJECT(%s)", buildJSONObjectArguments(d, fields, arguments))
+}
+
+// nestedJSON extracts the whole document from the subquery result, because MariaDB embeds results of subqueries as strings.
+func (mySQLDialect) nestedJSON(subquery string) string {
+	return fmt.Sprintf("JSON_EXTRACT((%s), '$')", subquery)
+}
+
+func (mySQLDialect) aggregateJSON(value string) string {
+	return fmt.Sprintf("COALESCE(JSON_ARRAYAGG(%s), JSON_ARRAY())", value)
+}
+
+func (mySQLDialect) iLike(column, placeholder string) string {
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, placeholder)
+}
+
+// orderByAny returns the column as is, because MySQL orders values of all types.
+func (mySQLDialect) orderByAny(column string) string {
+	return column
+}
+
+// nullsSortFirst returns true, because NULL values are smaller than any other value in MySQL.
+func (mySQLDialect) nullsSortFirst() bool {
+	return true
+}
+
+// orderByNulls orders rows by NULL check first, because MySQL does not support NULLS FIRST/LAST.
+func (mySQLDialect) orderByNulls(column, direction string, nullsFirst bool) string {
+	if nullsFirst {
+		return fmt.Sprintf("%s IS NULL DESC, %s %s", column, column, direction)
+	}
+
+	return fmt.Sprintf("%s IS NULL ASC, %s %s", column, column, direction)
+}
+
+// noLimit returns the maximum number of rows, because MySQL does not allow OFFSET without LIMIT.
+func (mySQLDialect) noLimit() string {
+	return "18446744073709551615"
+}
+
+// columnType uses varchar for text keys, because MySQL could not index text columns without prefix length.
+func (mySQLDialect) columnType(kind ValueKind, key bool) (string, error) {
+	switch kind {
+	case ValueKindInteger:
+		return "bigint", nil
+	case ValueKindNumber:
+		return "double", nil
+	case ValueKindBoolean:
+		return "boolean", nil
+	case ValueKindTimestamp:
+		return "datetime(6)", nil
+	case ValueKindDate:
+		return "date", nil
+	case ValueKindUUID:
+		return "char(36)", nil
+	case ValueKindText:
+		if key {
+			return "varchar(255)", nil
+		}
+
+		return "text", nil
+	case ValueKindJSON:
+		return "json", nil
+	default:
+		return "", fmt.Errorf("%w: unknown kind of values %q", ErrInvalidTableDefinition, kind)
+	}
+}
+
+// upsert updates rows that conflict by any unique key, because MySQL does not allow to choose it.
+// VALUES function is used instead of row alias to support MariaDB.
+func (d mySQLDialect) upsert(conflictColumns, updateColumns []string) string {
+	if len(updateColumns) == 0 {
+		column := d.quoteIdentifier(conflictColumns[0])
+		return fmt.Sprintf("ON DUPLICATE KEY UPDATE %s = %s", column, column)
+	}
+
+	assignments := make([]string, len(updateColumns))
+	for i, name := range updateColumns {
+		column := d.quoteIdentifier(name)
+		assignments[i] = fmt.Sprintf("%s = VALUES(%s)", column, column)
+	}
+
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+}
+
+func (mySQLDialect) lexicon() queryLexicon {
+	return queryLexicon{backslashEscapes: true, hashComments: true, spacedDashComments: true, executableComments: true}
+}
+
+// MySQL server error codes, see https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	mysqlErrDatabaseAccessDenied = 1044
+	mysqlErrAccessDenied         = 1045
+	mysqlErrBadDatabase          = 1049
+	mysqlErrHostNotPrivileged    = 1130
+	mysqlErrQueryTimeout         = 3024
+)
+
+func handleMySQLError(err error) error {
+	mysqlErr := &mysql.MySQLError{}
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrBadDatabase:
+			return ErrDatabaseDoesNotExists
+
+		case mysqlErrAccessDenied:
+			return ErrInvalidUsername
+
+		case mysqlErrDatabaseAccessDenied, mysqlErrHostNotPrivileged:
+			return ErrNoAccess
+
+		default:
+			return nil
+		}
+	}
+
+	return handleNetworkError(err)
+}
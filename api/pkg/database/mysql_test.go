@@ -0,0 +1,37 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMySQLEnumValues(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		columnType string
+		want       []string
+	}{
+		{name: "values", columnType: "enum('new','done')", want: []string{"new", "done"}},
+		{name: "uppercase type", columnType: "ENUM('a','b')", want: []string{"a", "b"}},
+		{name: "escaped quote", columnType: "enum('it''s','x')", want: []string{"it's", "x"}},
+		{name: "comma and parenthesis inside of value", columnType: "enum('a,b','(c)')", want: []string{"a,b", "(c)"}},
+		{name: "empty value", columnType: "enum('','a')", want: []string{"", "a"}},
+		{name: "not enum", columnType: "varchar(255)", want: nil},
+		{name: "set is not enum", columnType: "set('a','b')", want: nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := parseMySQLEnumValues(tc.columnType)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("parseMySQLEnumValues(%q) = %q, want %q", tc.columnType, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Sort represents ordering of rows by a single field.
+type Sort struct {
+	Field string `json:"field"`
+	// Direction is ascending when it's empty.
+	Direction SortDirection `json:"direction"`
+	// Nulls defines position of NULL values, default position of the database is used when it's empty.
+	Nulls NullsOrder `json:"nulls"`
+}
+
+// SortDirection represents a direction of rows ordering.
+type SortDirection string
+
+const (
+	// SortAscending - from the smallest value to the largest one.
+	SortAscending SortDirection = "asc"
+	// SortDescending - from the largest value to the smallest one.
+	SortDescending SortDirection = "desc"
+)
+
+// NullsOrder represents a position of NULL values in ordered rows.
+type NullsOrder string
+
+const (
+	// NullsFirst - NULL values are placed before other values.
+	NullsFirst NullsOrder = "first"
+	// NullsLast - NULL values are placed after other values.
+	NullsLast NullsOrder = "last"
+)
+
+// orderColumn represents a column of ORDER BY clause with resolved direction and position of NULL values.
+type orderColumn struct {
+	name string
+	// expression is used instead of the column, when rows are ordered by computed value.
+	expression string
+	descending bool
+	nullsFirst bool
+}
+
+// cursor is a content of an opaque cursor, which points to the last row of the previous page.
+type cursor struct {
+	// Order is a signature of the rows order, cursor could not be used with another order.
+	Order  string            `json:"order"`
+	Values []json.RawMessage `json:"values"`
+}
+
+// buildOrder returns columns by which rows are ordered and reports whether the order is unique.
+// Primary key columns are added after the sort fields to make the order unique, so it could be used for keyset pagination.
+// Tables without primary key, e.g. views, are ordered by all selected columns after the sort fields instead.
+// Rows with equal values of them are not distinguishable, so offset pages are stable, but cursor could not be built.
+func (b *queryBuilder) buildOrder(
+	table TableName, sort []Sort, selectedColumns []string, lister structureLister,
+) ([]orderColumn, bool, error) {
+	order := make([]orderColumn, 0, len(sort))
+
+	for _, s := range sort {
+		err := b.validateColumns(s.Field)
+		if err != nil {
+			return nil, false, err
+		}
+
+		column, err := b.parseSort(s)
+		if err != nil {
+			return nil, false, err
+		}
+		if containsOrderColumn(order, s.Field) {
+			return nil, false, fmt.Errorf("%w: field %s is used more than once", ErrInvalidSort, s.Field)
+		}
+
+		order = append(order, column)
+	}
+
+	primaryKey, err := lister.listPrimaryKey(table)
+	if err != nil {
+		return nil, false, fmt.Errorf("list primary key of %s: %w", table, err)
+	}
+
+	if len(primaryKey) != 0 {
+		for _, name := range primaryKey {
+			if !containsOrderColumn(order, name) {
+				order = append(order, orderColumn{name: name, nullsFirst: b.dialect.nullsSortFirst()})
+			}
+		}
+
+		return order, true, nil
+	}
+
+	for _, name := range selectedColumns {
+		if !containsOrderColumn(order, name) {
+			order = append(order, orderColumn{
+				name:       name,
+				expression: b.dialect.orderByAny(b.dialect.quoteIdentifier(name)),
+				nullsFirst: b.dialect.nullsSortFirst(),
+			})
+		}
+	}
+
+	return order, false, nil
+}
+
+// parseSort resolves direction and position of NULL values of the sort field.
+func (b *queryBuilder) parseSort(s Sort) (orderColumn, error) {
+	var descending bool
+	switch s.Direction {
+	case "", SortAscending:
+	case SortDescending:
+		descending = true
+	default:
+		return orderColumn{}, fmt.Errorf("%w: unknown direction %q", ErrInvalidSort, s.Direction)
+	}
+
+	// NULL values are the smallest or the largest ones depending on the database.
+	nullsFirst := b.dialect.nullsSortFirst() != descending
+	switch s.Nulls {
+	case "":
+	case NullsFirst:
+		nullsFirst = true
+	case NullsLast:
+		nullsFirst = false
+	default:
+		return orderColumn{}, fmt.Errorf("%w: unknown nulls order %q", ErrInvalidSort, s.Nulls)
+	}
+
+	return orderColumn{name: s.Field, descending: descending, nullsFirst: nullsFirst}, nil
+}
+
+// buildOrderBy returns ORDER BY clause of the columns.
+// Position of NULL values is specified only when it differs from the default one.
+func (b *queryBuilder) buildOrderBy(order []orderColumn) string {
+	expressions := make([]string, len(order))
+
+	for i, column := range order {
+		direction := "ASC"
+		if column.descending {
+			direction = "DESC"
+		}
+
+		name := column.expression
+		if name == "" {
+			name = b.dialect.quoteIdentifier(column.name)
+		}
+
+		if column.nullsFirst == (b.dialect.nullsSortFirst() != column.descending) {
+			expressions[i] = fmt.Sprintf("%s %s", name, direction)
+			continue
+		}
+
+		expressions[i] = b.dialect.orderByNulls(name, direction, column.nullsFirst)
+	}
+
+	return "ORDER BY " + strings.Join(expressions, ", ")
+}
+
+// buildKeysetCondition returns a condition that selects rows after the row with the given values of order columns.
+// Row is after another one when they have equal values of the first columns and the next column value is after.
+func (b *queryBuilder) buildKeysetCondition(order []orderColumn, values []interface{}) string {
+	var alternatives []string
+
+	for i, column := range order {
+		// Nothing follows NULL value, when NULL values are placed last.
+		if values[i] == nil && !column.nullsFirst {
+			continue
+		}
+
+		// Conditions are built in the order of their placeholders, because some databases use positional ones.
+		conditions := make([]string, 0, i+1)
+		for j, previous := range order[:i] {
+			name := b.dialect.quoteIdentifier(previous.name)
+
+			if values[j] == nil {
+				conditions = append(conditions, fmt.Sprintf("%s IS NULL", name))
+				continue
+			}
+
+			conditions = append(conditions, fmt.Sprintf("%s = %s", name, b.bind(values[j])))
+		}
+
+		conditions = append(conditions, b.buildAfterCondition(column, values[i]))
+		alternatives = append(alternatives, "("+strings.Join(conditions, " AND ")+")")
+	}
+
+	// The last row is already selected, when nothing could follow it.
+	if len(alternatives) == 0 {
+		return "1 = 0"
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// buildAfterCondition returns a condition that selects values of the column that follow the given value.
+func (b *queryBuilder) buildAfterCondition(column orderColumn, value interface{}) string {
+	name := b.dialect.quoteIdentifier(column.name)
+
+	if value == nil {
+		return fmt.Sprintf("%s IS NOT NULL", name)
+	}
+
+	operator := ">"
+	if column.descending {
+		operator = "<"
+	}
+
+	condition := fmt.Sprintf("%s %s %s", name, operator, b.bind(value))
+	if !column.nullsFirst {
+		condition = fmt.Sprintf("(%s OR %s IS NULL)", condition, name)
+	}
+
+	return condition
+}
+
+// orderSignature returns a text that identifies the order, so cursor could not be applied to another one.
+func orderSignature(order []orderColumn) string {
+	columns := make([]string, len(order))
+
+	for i, column := range order {
+		direction := SortAscending
+		if column.descending {
+			direction = SortDescending
+		}
+
+		nulls := NullsLast
+		if column.nullsFirst {
+			nulls = NullsFirst
+		}
+
+		columns[i] = fmt.Sprintf("%q %s nulls %s", column.name, direction, nulls)
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// decodeCursor returns values of the order columns from the cursor.
+func decodeCursor(encoded string, order []orderColumn) ([]interface{}, error) {
+	if len(order) == 0 {
+		return nil, fmt.Errorf("%w: rows of the table have no order, please sort them", ErrInvalidCursor)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var c cursor
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if c.Order != orderSignature(order) || len(c.Values) != len(order) {
+		return nil, fmt.Errorf("%w: cursor was created for another order", ErrInvalidCursor)
+	}
+
+	values := make([]interface{}, len(c.Values))
+	for i, raw := range c.Values {
+		values[i], err = jsonBindValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+		}
+	}
+
+	return values, nil
+}
+
+// jsonBindValue converts JSON value into bind parameter, it is used for values of cursors and imported rows.
+// Decimals are passed as strings to keep their precision, objects and arrays are passed as JSON text.
+func jsonBindValue(raw json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, fmt.Errorf("decode value: %w", err)
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		if integer, err := v.Int64(); err == nil {
+			return integer, nil
+		}
+
+		return v.String(), nil
+	case map[string]interface{}, []interface{}:
+		return string(raw), nil
+	default:
+		return v, nil
+	}
+}
+
+// NextCursor returns an opaque cursor that could be used for selecting rows after the given one.
+// Empty cursor is returned when rows are not ordered or their order is not unique.
+func (q Query) NextCursor(row []byte) (string, error) {
+	if len(q.order) == 0 {
+		return "", nil
+	}
+
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(row, &fields)
+	if err != nil {
+		return "", fmt.Errorf("unmarshal row: %w", err)
+	}
+
+	c := cursor{
+		Order:  orderSignature(q.order),
+		Values: make([]json.RawMessage, len(q.order)),
+	}
+
+	for i, column := range q.order {
+		value, ok := fields[column.name]
+		if !ok {
+			return "", fmt.Errorf("row does not contain order column %s", column.name)
+		}
+
+		c.Values[i] = value
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("marshal cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func containsOrderColumn(order []orderColumn, name string) bool {
+	for _, column := range order {
+		if column.name == name {
+			return true
+		}
+	}
+
+	return false
+}
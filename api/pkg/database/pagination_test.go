@@ -0,0 +1,247 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSQLiteBuildQueryKeysetPagination(t *testing.T) {
+	t.Parallel()
+
+	client := newTestSQLiteClient(t)
+
+	testCases := []struct {
+		name    string
+		sort    []Sort
+		fields  []string
+		wantIDs []string
+	}{
+		{
+			name:    "primary key",
+			wantIDs: []string{"1", "2", "3", "4", "9007199254740993"},
+		},
+		{
+			name:    "descending with ties and nulls last by default",
+			sort:    []Sort{{Field: "price", Direction: SortDescending}},
+			wantIDs: []string{"1", "4", "3", "9007199254740993", "2"},
+		},
+		{
+			name:    "ascending with nulls last",
+			sort:    []Sort{{Field: "price", Nulls: NullsLast}},
+			wantIDs: []string{"9007199254740993", "3", "1", "4", "2"},
+		},
+		{
+			name:    "ascending with nulls first by default",
+			sort:    []Sort{{Field: "price"}, {Field: "id", Direction: SortDescending}},
+			fields:  []string{"id", "price"},
+			wantIDs: []string{"2", "9007199254740993", "3", "4", "1"},
+		},
+		{
+			name:    "nullable column with nulls first",
+			sort:    []Sort{{Field: "author_id", Direction: SortDescending, Nulls: NullsFirst}},
+			fields:  []string{"id", "author_id"},
+			wantIDs: []string{"9007199254740993", "3", "4", "1", "2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			const pageSize = 2
+
+			var (
+				ids    []string
+				cursor string
+			)
+			for page := 0; ; page++ {
+				if page > 5 {
+					t.Fatalf("pagination did not stop, selected ids %v", ids)
+				}
+
+				query, rows := selectRows(t, client, BuildQueryOptions{
+					TableName: "books",
+					Fields:    tc.fields,
+					Sort:      tc.sort,
+					Limit:     pageSize,
+					Cursor:    cursor,
+				})
+				ids = append(ids, rowIDs(rows)...)
+
+				if len(rows) < pageSize {
+					break
+				}
+
+				last, err := json.Marshal(rows[len(rows)-1])
+				if err != nil {
+					t.Fatalf("marshal row: %v", err)
+				}
+
+				cursor, err = query.NextCursor(last)
+				if err != nil {
+					t.Fatalf("NextCursor() unexpected error: %v", err)
+				}
+				if cursor == "" {
+					t.Fatalf("NextCursor() is empty")
+				}
+			}
+
+			if !slices.Equal(ids, tc.wantIDs) {
+				t.Errorf("selected ids = %v, want %v", ids, tc.wantIDs)
+			}
+		})
+	}
+}
+
+func TestSQLiteBuildQueryPaginationErrors(t *testing.T) {
+	t.Parallel()
+
+	client := newTestSQLiteClient(t)
+
+	query, rows := selectRows(t, client, BuildQueryOptions{TableName: "books", Limit: 1})
+	last, err := json.Marshal(rows[0])
+	if err != nil {
+		t.Fatalf("marshal row: %v", err)
+	}
+	cursor, err := query.NextCursor(last)
+	if err != nil {
+		t.Fatalf("NextCursor() unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		options BuildQueryOptions
+		wantErr error
+	}{
+		{
+			name:    "cursor of another order",
+			options: BuildQueryOptions{TableName: "books", Limit: 1, Cursor: cursor, Sort: []Sort{{Field: "title"}}},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "cursor with offset",
+			options: BuildQueryOptions{TableName: "books", Limit: 1, Cursor: cursor, Offset: 1},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "corrupted cursor",
+			options: BuildQueryOptions{TableName: "books", Limit: 1, Cursor: "not a cursor"},
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "fields without primary key",
+			options: BuildQueryOptions{TableName: "books", Limit: 1, Fields: []string{"title"}},
+			wantErr: ErrOrderNotSelected,
+		},
+		{
+			name:    "fields without sort field",
+			options: BuildQueryOptions{TableName: "books", Limit: 1, Fields: []string{"id"}, Sort: []Sort{{Field: "title"}}},
+			wantErr: ErrOrderNotSelected,
+		},
+		{
+			name:    "cursor of table without primary key",
+			options: BuildQueryOptions{TableName: "sales", Limit: 1, Cursor: cursor},
+			wantErr: ErrOrderNotUnique,
+		},
+		{
+			name:    "unknown direction",
+			options: BuildQueryOptions{TableName: "books", Sort: []Sort{{Field: "title", Direction: "up"}}},
+			wantErr: ErrInvalidSort,
+		},
+		{
+			name:    "duplicated sort field",
+			options: BuildQueryOptions{TableName: "books", Sort: []Sort{{Field: "title"}, {Field: "title"}}},
+			wantErr: ErrInvalidSort,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := client.BuildQuery(tc.options)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("BuildQuery() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSQLiteBuildQueryWithoutPrimaryKey(t *testing.T) {
+	t.Parallel()
+
+	client := newTestSQLiteClient(t)
+
+	testCases := []struct {
+		name     string
+		sort     []Sort
+		fields   []string
+		wantRows []string
+	}{
+		{
+			name:     "all columns without sort",
+			wantRows: []string{`"Alpha" 1`, `"Alpha" 2`, `"Beta" 1`, `"Beta" 1`, `"Gamma" 2`},
+		},
+		{
+			name:     "ties of sort field are ordered by other columns",
+			sort:     []Sort{{Field: "quantity", Direction: SortDescending}},
+			wantRows: []string{`"Alpha" 2`, `"Gamma" 2`, `"Alpha" 1`, `"Beta" 1`, `"Beta" 1`},
+		},
+		{
+			name:     "sort field is not selected",
+			sort:     []Sort{{Field: "quantity"}},
+			fields:   []string{"title"},
+			wantRows: []string{`"Alpha" `, `"Beta" `, `"Beta" `, `"Alpha" `, `"Gamma" `},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			const pageSize = 2
+
+			var selected []string
+			for offset := 0; offset < 6; offset += pageSize {
+				query, rows := selectRows(t, client, BuildQueryOptions{
+					TableName: "sales",
+					Fields:    tc.fields,
+					Sort:      tc.sort,
+					Limit:     pageSize,
+					Offset:    offset,
+				})
+
+				for _, row := range rows {
+					selected = append(selected, string(row["title"])+" "+string(row["quantity"]))
+				}
+
+				if len(rows) == 0 {
+					continue
+				}
+
+				last, err := json.Marshal(rows[len(rows)-1])
+				if err != nil {
+					t.Fatalf("marshal row: %v", err)
+				}
+
+				// Rows could not be paginated by cursor without unique order.
+				cursor, err := query.NextCursor(last)
+				if err != nil || cursor != "" {
+					t.Fatalf("NextCursor() = %q, %v, want empty cursor", cursor, err)
+				}
+			}
+
+			if !slices.Equal(selected, tc.wantRows) {
+				t.Errorf("selected rows = %v, want %v", selected, tc.wantRows)
+			}
+		})
+	}
+}
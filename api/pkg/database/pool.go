@@ -0,0 +1,295 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/VladPetriv/d2j/pkg/logger"
+)
+
+// ClientPool represents a set of database clients that are shared between requests by the session key.
+type ClientPool interface {
+	// Get returns a client for the given key. When there is no client, it's created with connect function.
+	// Client is leased until release is called, leased clients are not closed by the pool.
+	Get(key string, connect func() (DBClient, error)) (client DBClient, release func(), err error)
+	// Remove removes the client for the given key, if it exists. Client is closed when all its leases are released.
+	Remove(key string) error
+	Stats() []ClientStats
+	Close() error
+}
+
+// ClientPoolOptions represents an options that used for creating client pool.
+type ClientPoolOptions struct {
+	// MaxClients is a maximum number of clients that could be opened at the same time, zero means no limit.
+	MaxClients int
+	// IdleTimeout is a time after which unused client is closed, zero means that clients are never closed.
+	IdleTimeout time.Duration
+}
+
+// ClientStats represents statistics of a pooled client.
+type ClientStats struct {
+	// KeyFingerprint is a short hash of the client key, which is safe to show instead of the key itself.
+	KeyFingerprint string
+	CreatedAt      time.Time
+	LastUsedAt     time.Time
+	DBStats        DBStats
+}
+
+// DBStats represents statistics of connections opened by a client.
+type DBStats struct {
+	MaxOpenConnections int
+	OpenConnections    int
+	InUse              int
+	Idle               int
+	WaitCount          int64
+	WaitDuration       time.Duration
+}
+
+type pooledClient struct {
+	client     DBClient
+	createdAt  time.Time
+	lastUsedAt time.Time
+	// leases is a number of callers that use the client, it could not be closed until they release it.
+	leases int
+	// removed means that client is not in the pool anymore and must be closed by the last release.
+	removed bool
+}
+
+type clientPool struct {
+	logger  logger.Logger
+	options ClientPoolOptions
+
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+
+	done chan struct{}
+}
+
+var _ ClientPool = (*clientPool)(nil)
+
+// NewClientPool is used to create an instance of client pool.
+// It starts a background routine that closes idle clients until pool is closed.
+func NewClientPool(logger logger.Logger, options ClientPoolOptions) *clientPool {
+	pool := &clientPool{
+		logger:  logger,
+		options: options,
+		clients: make(map[string]*pooledClient),
+		done:    make(chan struct{}),
+	}
+
+	if options.IdleTimeout > 0 {
+		go pool.closeIdleClients()
+	}
+
+	return pool
+}
+
+func (p *clientPool) Get(key string, connect func() (DBClient, error)) (DBClient, func(), error) {
+	logger := p.logger.Named("clientPool.Get")
+
+	p.mu.Lock()
+	if pooled, ok := p.clients[key]; ok {
+		release := p.lease(pooled)
+		p.mu.Unlock()
+
+		logger.Debug("reused pooled client")
+		return pooled.client, release, nil
+	}
+	p.mu.Unlock()
+
+	// Connect without holding the lock to not block other sessions by a slow database.
+	client, err := connect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Another request could connect with the same key in the meantime, so prefer the existing client.
+	if pooled, ok := p.clients[key]; ok {
+		p.closeClient(client)
+
+		logger.Debug("reused client created by concurrent request")
+		return pooled.client, p.lease(pooled), nil
+	}
+
+	if p.options.MaxClients != 0 && len(p.clients) >= p.options.MaxClients {
+		err := p.evictLeastRecentlyUsed()
+		if err != nil {
+			p.closeClient(client)
+
+			logger.Info(err.Error())
+			return nil, nil, err
+		}
+	}
+
+	now := time.Now()
+	pooled := &pooledClient{
+		client:    client,
+		createdAt: now,
+	}
+	p.clients[key] = pooled
+
+	logger.Debug("added new client to the pool", "clientsCount", len(p.clients))
+	return client, p.lease(pooled), nil
+}
+
+// lease marks the client as used and returns a function that releases it, the function could be called more than once.
+// Caller must hold the lock.
+func (p *clientPool) lease(pooled *pooledClient) func() {
+	pooled.leases++
+	pooled.lastUsedAt = time.Now()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			pooled.leases--
+			pooled.lastUsedAt = time.Now()
+
+			if pooled.removed && pooled.leases == 0 {
+				p.closeClient(pooled.client)
+			}
+		})
+	}
+}
+
+// evictLeastRecentlyUsed closes the least recently used client, which is not leased.
+// Caller must hold the lock.
+func (p *clientPool) evictLeastRecentlyUsed() error {
+	var (
+		evictKey     string
+		evictLastUse time.Time
+	)
+
+	for key, pooled := range p.clients {
+		if pooled.leases != 0 {
+			continue
+		}
+
+		if evictKey == "" || pooled.lastUsedAt.Before(evictLastUse) {
+			evictKey = key
+			evictLastUse = pooled.lastUsedAt
+		}
+	}
+
+	if evictKey == "" {
+		return ErrTooManyClients
+	}
+
+	p.closeClient(p.clients[evictKey].client)
+	delete(p.clients, evictKey)
+
+	return nil
+}
+
+func (p *clientPool) Remove(key string) error {
+	p.mu.Lock()
+	pooled, ok := p.clients[key]
+	delete(p.clients, key)
+
+	// Leased client is closed by the last release, so requests in progress are not interrupted.
+	if ok && pooled.leases != 0 {
+		pooled.removed = true
+		ok = false
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	err := pooled.client.Close()
+	if err != nil {
+		return fmt.Errorf("close client: %w", err)
+	}
+
+	return nil
+}
+
+func (p *clientPool) Stats() []ClientStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]ClientStats, 0, len(p.clients))
+
+	for key, pooled := range p.clients {
+		stats = append(stats, ClientStats{
+			KeyFingerprint: fingerprint(key),
+			CreatedAt:      pooled.createdAt,
+			LastUsedAt:     pooled.lastUsedAt,
+			DBStats:        pooled.client.Stats(),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].CreatedAt.Before(stats[j].CreatedAt)
+	})
+
+	return stats
+}
+
+func (p *clientPool) Close() error {
+	logger := p.logger.Named("clientPool.Close")
+
+	close(p.done)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, pooled := range p.clients {
+		p.closeClient(pooled.client)
+		delete(p.clients, key)
+	}
+
+	logger.Info("closed all pooled clients")
+	return nil
+}
+
+func (p *clientPool) closeIdleClients() {
+	logger := p.logger.Named("clientPool.closeIdleClients")
+
+	ticker := time.NewTicker(p.options.IdleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+
+		case <-ticker.C:
+			p.mu.Lock()
+			for key, pooled := range p.clients {
+				if time.Since(pooled.lastUsedAt) < p.options.IdleTimeout || pooled.leases != 0 {
+					continue
+				}
+
+				p.closeClient(pooled.client)
+				delete(p.clients, key)
+
+				logger.Debug("closed idle client", "keyFingerprint", fingerprint(key))
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (p *clientPool) closeClient(client DBClient) {
+	err := client.Close()
+	if err != nil {
+		p.logger.Named("clientPool.closeClient").Error("close client", "err", err)
+	}
+}
+
+func fingerprint(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])[:12]
+}
@@ -1,12 +1,14 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"net"
 	"slices"
 	"strings"
 
+	"github.com/VladPetriv/d2j/pkg/errs"
 	"github.com/VladPetriv/d2j/pkg/logger"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -14,8 +16,6 @@ import (
 
 type postgreSQL struct {
 	logger logger.Logger
-
-	db *sqlx.DB
 }
 
 var _ Database = (*postgreSQL)(nil)
@@ -59,7 +59,7 @@ func (p *postgreSQL) Connect(options ConnectionOptions) (DBClient, error) {
 		return nil, fmt.Errorf("connect to postgresql: %w", err)
 	}
 
-	p.db = db
+	applyConnectionLimits(db, options)
 
 	logger.Info("connected to postgresql")
 	return newPostgreSQLClient(logger, db), nil
@@ -78,103 +78,631 @@ func buildConnectionString(options ConnectionOptions) string {
 	return connectionString
 }
 
-func (p *postgreSQL) Close() error {
-	logger := p.logger.Named("postgreSQL.Close")
+func (p *postgreSQLClient) BuildQuery(options BuildQueryOptions) (Query, error) {
+	logger := p.logger.Named("postgreSQLClient.BuildQuery")
 
-	err := p.db.DB.Close()
+	table, err := ParseTableName(options.TableName)
 	if err != nil {
-		logger.Error("close postgresql connection", err)
-		return fmt.Errorf("close postgresql connection: %w", err)
+		logger.Info(err.Error())
+		return Query{}, err
 	}
 
-	logger.Info("closed postgresql connection")
-	return nil
-}
+	columns, err := p.listColumns(table)
+	if err != nil {
+		logger.Error("list table columns", "err", err)
+		return Query{}, fmt.Errorf("list table columns: %w", err)
+	}
+	logger.Debug("got table columns", "columns", columns)
 
-func (p *postgreSQLClient) BuildQuery(options BuildQueryOptions) string {
-	var query string
+	return buildSelectQuery(postgreSQLDialect{}, table, options, columns, p)
+}
 
-	if len(options.Fields) == 0 {
-		query = fmt.Sprintf("SELECT to_jsonb(%s) FROM %s", options.TableName, options.TableName)
+// listColumns reads columns from the catalog, because information_schema does not contain materialized views.
+func (p *postgreSQLClient) listColumns(table TableName) ([]string, error) {
+	var columns []string
+	err := p.db.Select(
+		&columns,
+		`SELECT a.attname FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relname = $2
+			AND c.relkind IN ('r', 'p', 'v', 'm', 'f') AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum;`,
+		table.Schema, table.Name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select postgresql column names: %w", err)
 	}
 
-	if len(options.Fields) != 0 {
-		query += "SELECT jsonb_agg(jsonb_build_object("
-
-		for i, f := range options.Fields {
-			if i == len(options.Fields)-1 {
-				query += fmt.Sprintf("'%s', %s))", f, f)
+	return columns, nil
+}
 
-				continue
-			}
+func (p *postgreSQLClient) listRelations(table TableName) ([]relation, error) {
+	var columns []relationColumn
+	err := p.db.Select(
+		&columns,
+		`SELECT con.oid::text AS constraint_key,
+			false AS many,
+			rn.nspname AS related_schema,
+			rt.relname AS related_table,
+			a.attname AS column_name,
+			ra.attname AS related_column,
+			k.position
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_catalog.pg_class rt ON rt.oid = con.confrelid
+		JOIN pg_catalog.pg_namespace rn ON rn.oid = rt.relnamespace
+		JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, position) ON true
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+		JOIN pg_catalog.pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+		WHERE con.contype = 'f' AND n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND t.relname = $2
+		UNION ALL
+		SELECT con.oid::text AS constraint_key,
+			true AS many,
+			n.nspname AS related_schema,
+			t.relname AS related_table,
+			ra.attname AS column_name,
+			a.attname AS related_column,
+			k.position
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_catalog.pg_class rt ON rt.oid = con.confrelid
+		JOIN pg_catalog.pg_namespace rn ON rn.oid = rt.relnamespace
+		JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, position) ON true
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+		JOIN pg_catalog.pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+		WHERE con.contype = 'f' AND rn.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND rt.relname = $2
+		ORDER BY many, related_schema, related_table, constraint_key, position;`,
+		table.Schema, table.Name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select postgresql foreign keys: %w", err)
+	}
 
-			query += fmt.Sprintf("'%s', %s, ", f, f)
-		}
+	return groupRelations(columns), nil
+}
 
-		query += fmt.Sprintf("FROM %s", options.TableName)
+func (p *postgreSQLClient) listPrimaryKey(table TableName) ([]string, error) {
+	var columns []string
+	err := p.db.Select(
+		&columns,
+		`SELECT a.attname FROM pg_catalog.pg_index i
+		JOIN pg_catalog.pg_class c ON c.oid = i.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, position) ON true
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+		WHERE i.indisprimary AND n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relname = $2
+		ORDER BY k.position;`,
+		table.Schema, table.Name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select postgresql primary key: %w", err)
 	}
 
-	if len(options.Where) != 0 {
-		query += fmt.Sprintf(" WHERE %s", options.Where)
-	}
-	if options.Limit != 0 {
-		query += fmt.Sprintf(" LIMIT %d", options.Limit)
+	return columns, nil
+}
+
+func (p *postgreSQLClient) ListSchemas(ctx context.Context) ([]string, error) {
+	logger := p.logger.Named("postgreSQLClient.ListSchemas")
+
+	var schemas []string
+	err := p.db.SelectContext(
+		ctx,
+		&schemas,
+		`SELECT nspname FROM pg_catalog.pg_namespace
+		WHERE nspname <> 'information_schema' AND nspname NOT LIKE 'pg\_%'
+		ORDER BY nspname;`,
+	)
+	if err != nil {
+		logger.Error("select postgresql schema names", "err", err)
+		return nil, fmt.Errorf("select postgresql schema names: %w", err)
 	}
+	logger.Debug("got all postgresql schemas", "schemas", schemas)
 
-	return query
+	return schemas, nil
 }
 
-func (p *postgreSQLClient) ListTables() ([]Table, error) {
+func (p *postgreSQLClient) ListTables(ctx context.Context, schemaName string) ([]Table, error) {
 	logger := p.logger.Named("postgreSQLClient.ListTables")
 
 	var tables []Table
-	err := p.db.Select(
+	err := p.db.SelectContext(
+		ctx,
 		&tables,
-		"SELECT schemaname, tablename FROM pg_catalog.pg_tables;",
+		`SELECT n.nspname AS schemaname, c.relname AS tablename,
+			CASE c.relkind
+				WHEN 'v' THEN 'view'
+				WHEN 'm' THEN 'materialized_view'
+				WHEN 'f' THEN 'foreign_table'
+				ELSE 'table'
+			END AS kind
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relkind IN ('r', 'p', 'v', 'm', 'f')
+		ORDER BY c.relname;`,
+		schemaName,
 	)
 	if err != nil {
 		logger.Error("select postgresql table names", "err", err)
 		return nil, fmt.Errorf("select postgresql table names: %w", err)
 	}
-	logger.Debug("got all postgresql tables", "tables", tables)
+	logger.Debug("got postgresql tables", "tables", tables)
+
+	return tables, nil
+}
+
+func (p *postgreSQLClient) RefreshMaterializedView(ctx context.Context, tableName string) error {
+	logger := p.logger.Named("postgreSQLClient.RefreshMaterializedView")
+
+	table, err := ParseTableName(tableName)
+	if err != nil {
+		logger.Info(err.Error())
+		return err
+	}
+
+	var kinds []string
+	err = p.db.SelectContext(
+		ctx,
+		&kinds,
+		`SELECT c.relkind FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relname = $2;`,
+		table.Schema, table.Name,
+	)
+	if err != nil {
+		logger.Error("select postgresql relation kind", "err", err)
+		return fmt.Errorf("select postgresql relation kind: %w", err)
+	}
+	if len(kinds) == 0 {
+		logger.Info(ErrTableDoesNotExist.Error(), "tableName", tableName)
+		return fmt.Errorf("%w: %s", ErrTableDoesNotExist, tableName)
+	}
+	if kinds[0] != "m" {
+		logger.Info(ErrNotMaterializedView.Error(), "tableName", tableName)
+		return fmt.Errorf("%w: %s", ErrNotMaterializedView, tableName)
+	}
+
+	_, err = p.db.ExecContext(ctx, fmt.Sprintf("REFRESH MATERIALIZED VIEW %s;", quoteTableName(postgreSQLDialect{}, table)))
+	if err != nil {
+		logger.Error("refresh materialized view", "err", err)
+		return fmt.Errorf("refresh materialized view: %w", err)
+	}
+
+	logger.Info("refreshed materialized view", "tableName", tableName)
+	return nil
+}
+
+func (p *postgreSQLClient) DescribeTable(ctx context.Context, tableName string) (*TableDescription, error) {
+	logger := p.logger.Named("postgreSQLClient.DescribeTable")
+
+	table, err := ParseTableName(tableName)
+	if err != nil {
+		logger.Info(err.Error())
+		return nil, err
+	}
+	tableName = table.Name
+
+	var schemaName string
+	err = p.db.GetContext(ctx, &schemaName, "SELECT COALESCE(NULLIF($1, ''), current_schema());", table.Schema)
+	if err != nil {
+		logger.Error("get postgresql schema name", "err", err)
+		return nil, fmt.Errorf("get postgresql schema name: %w", err)
+	}
+
+	var columns []Column
+	err = p.db.SelectContext(
+		ctx,
+		&columns,
+		`SELECT a.attname AS name,
+			format_type(a.atttypid, a.atttypmod) AS data_type,
+			NOT a.attnotnull AS nullable,
+			pg_get_expr(d.adbin, d.adrelid) AS default_value,
+			col_description(a.attrelid, a.attnum) AS comment
+		FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE n.nspname = $1 AND c.relname = $2 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum;`,
+		schemaName, tableName,
+	)
+	if err != nil {
+		logger.Error("select postgresql columns", "err", err)
+		return nil, fmt.Errorf("select postgresql columns: %w", err)
+	}
+	if len(columns) == 0 {
+		logger.Info(ErrTableDoesNotExist.Error(), "tableName", tableName)
+		return nil, fmt.Errorf("%w: %s", ErrTableDoesNotExist, tableName)
+	}
+	logger.Debug("got table columns", "columns", columns)
+
+	// Values of enum arrays are taken from the element type.
+	var enumValues []enumValue
+	err = p.db.SelectContext(
+		ctx,
+		&enumValues,
+		`SELECT a.attname AS column_name, e.enumlabel AS value
+		FROM pg_catalog.pg_attribute a
+		JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_catalog.pg_type t ON t.oid = a.atttypid
+		JOIN pg_catalog.pg_enum e ON e.enumtypid = t.oid OR e.enumtypid = t.typelem
+		WHERE n.nspname = $1 AND c.relname = $2 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum, e.enumsortorder;`,
+		schemaName, tableName,
+	)
+	if err != nil {
+		logger.Error("select postgresql enum values", "err", err)
+		return nil, fmt.Errorf("select postgresql enum values: %w", err)
+	}
+	setEnumValues(columns, enumValues)
+	logger.Debug("got enum values", "enumValues", enumValues)
+
+	// Expression indexes have zero column number, so their expression is used instead of the column name.
+	var indexColumns []indexColumn
+	err = p.db.SelectContext(
+		ctx,
+		&indexColumns,
+		`SELECT i.relname AS index_name,
+			COALESCE(a.attname, pg_get_indexdef(ix.indexrelid, k.position::int, true)) AS column_name,
+			ix.indisunique AS is_unique,
+			ix.indisprimary AS is_primary
+		FROM pg_catalog.pg_index ix
+		JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+		JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, position) ON true
+		LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = $1 AND t.relname = $2
+		ORDER BY i.relname, k.position;`,
+		schemaName, tableName,
+	)
+	if err != nil {
+		logger.Error("select postgresql indexes", "err", err)
+		return nil, fmt.Errorf("select postgresql indexes: %w", err)
+	}
+	logger.Debug("got table indexes", "indexColumns", indexColumns)
+
+	var foreignKeyColumns []foreignKeyColumn
+	err = p.db.SelectContext(
+		ctx,
+		&foreignKeyColumns,
+		`SELECT con.conname AS constraint_key,
+			con.conname AS name,
+			a.attname AS column_name,
+			rn.nspname AS referenced_schema,
+			rt.relname AS referenced_table,
+			ra.attname AS referenced_column,
+			`+postgresForeignKeyAction("con.confupdtype")+` AS on_update,
+			`+postgresForeignKeyAction("con.confdeltype")+` AS on_delete
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_catalog.pg_class rt ON rt.oid = con.confrelid
+		JOIN pg_catalog.pg_namespace rn ON rn.oid = rt.relnamespace
+		JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, position) ON true
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+		JOIN pg_catalog.pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+		WHERE con.contype = 'f' AND n.nspname = $1 AND t.relname = $2
+		ORDER BY con.conname, k.position;`,
+		schemaName, tableName,
+	)
+	if err != nil {
+		logger.Error("select postgresql foreign keys", "err", err)
+		return nil, fmt.Errorf("select postgresql foreign keys: %w", err)
+	}
+	logger.Debug("got table foreign keys", "foreignKeyColumns", foreignKeyColumns)
+
+	checks := make([]CheckConstraint, 0)
+	err = p.db.SelectContext(
+		ctx,
+		&checks,
+		`SELECT con.conname AS name, pg_get_expr(con.conbin, con.conrelid) AS expression
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE con.contype = 'c' AND n.nspname = $1 AND t.relname = $2
+		ORDER BY con.conname;`,
+		schemaName, tableName,
+	)
+	if err != nil {
+		logger.Error("select postgresql check constraints", "err", err)
+		return nil, fmt.Errorf("select postgresql check constraints: %w", err)
+	}
+	logger.Debug("got table check constraints", "checks", checks)
+
+	indexes := groupIndexes(indexColumns)
+
+	return &TableDescription{
+		Dialect:     DialectPostgreSQL,
+		SchemaName:  schemaName,
+		TableName:   tableName,
+		Columns:     columns,
+		PrimaryKey:  primaryKeyFromIndexes(indexes),
+		ForeignKeys: groupForeignKeys(foreignKeyColumns),
+		Indexes:     indexes,
+		Checks:      checks,
+	}, nil
+}
+
+// postgresForeignKeyAction returns an expression that converts foreign key action code into its SQL name.
+func postgresForeignKeyAction(column string) string {
+	return fmt.Sprintf(
+		`CASE %s WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END`,
+		column,
+	)
+}
+
+// EstimateRowsCount returns number of rows that was computed by the last VACUUM or ANALYZE of the table.
+func (p *postgreSQLClient) EstimateRowsCount(ctx context.Context, tableName string) (int64, error) {
+	logger := p.logger.Named("postgreSQLClient.EstimateRowsCount")
+
+	table, err := ParseTableName(tableName)
+	if err != nil {
+		logger.Info(err.Error())
+		return 0, err
+	}
+
+	var estimates []float64
+	err = p.db.SelectContext(
+		ctx,
+		&estimates,
+		`SELECT c.reltuples FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relname = $2;`,
+		table.Schema, table.Name,
+	)
+	if err != nil {
+		logger.Error("select postgresql relation tuples", "err", err)
+		return 0, fmt.Errorf("select postgresql relation tuples: %w", err)
+	}
+	if len(estimates) == 0 {
+		logger.Info(ErrTableDoesNotExist.Error(), "tableName", tableName)
+		return 0, fmt.Errorf("%w: %s", ErrTableDoesNotExist, tableName)
+	}
+
+	// Tuples are -1 for views and for tables that were never analyzed.
+	if estimates[0] < 0 {
+		logger.Debug("postgresql relation has no statistics", "tableName", tableName)
+		return 0, ErrRowsCountUnknown
+	}
+
+	return int64(estimates[0]), nil
+}
+
+func (p *postgreSQLClient) ExecuteQuery(ctx context.Context, query Query) ([]string, error) {
+	return executeQuery(ctx, p.logger.Named("postgreSQLClient.ExecuteQuery"), p.db, query)
+}
 
-	tables = slices.DeleteFunc(tables, func(t Table) bool {
-		return t.SchemaName != "public"
+func (p *postgreSQLClient) ExecuteReadOnlyQuery(ctx context.Context, options ReadOnlyQueryOptions) ([]string, error) {
+	logger := p.logger.Named("postgreSQLClient.ExecuteReadOnlyQuery")
+
+	text, err := validateReadOnlyQuery(postgreSQLDialect{}, options.Text)
+	if err != nil {
+		logger.Info(err.Error())
+		return nil, err
+	}
+
+	result, err := executeReadOnlyQuery(ctx, logger, p.db, options, func(ctx context.Context, tx *sqlx.Tx) (Query, error) {
+		// Timeout is also set on the server, so the query is canceled even when connection to it is lost.
+		if options.Timeout != 0 {
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d;", options.Timeout.Milliseconds()))
+			if err != nil {
+				return Query{}, fmt.Errorf("set statement timeout: %w", err)
+			}
+		}
+
+		return wrapReadOnlyQuery("to_jsonb(q)", text, options.Limit), nil
 	})
-	logger.Debug("removed not public tables from the result", "tables", tables)
+	if err != nil {
+		pqErr := &pq.Error{}
+		queryErr := postgreSQLQueryError(err)
+		switch {
+		case errors.Is(err, ErrQueryTimeout), errors.As(err, &pqErr) && pqErr.Code.Name() == "query_canceled":
+			logger.Info(ErrQueryTimeout.Error())
+			return nil, ErrQueryTimeout
+
+		case queryErr != nil:
+			logger.Info(queryErr.Message)
+			return nil, queryErr
+		}
 
-	return tables, nil
+		logger.Error("execute read-only query", "err", err)
+		return nil, fmt.Errorf("execute read-only query: %w", err)
+	}
+
+	return result, nil
+}
+
+func (p *postgreSQLClient) StreamQuery(ctx context.Context, query Query, handleRow func(row []byte) error) error {
+	return streamQuery(ctx, p.logger.Named("postgreSQLClient.StreamQuery"), p.db, query, handleRow)
 }
 
-func (p *postgreSQLClient) ExecuteQuery(query string) ([]string, error) {
-	logger := p.logger.Named("postgreSQLClient.ExecuteQuery")
+// BeginSnapshot uses REPEATABLE READ isolation, in which all queries of the transaction see the same snapshot.
+func (p *postgreSQLClient) BeginSnapshot(ctx context.Context) (Snapshot, error) {
+	logger := p.logger.Named("postgreSQLClient.BeginSnapshot")
 
-	rows, err := p.db.Query(query)
+	snapshot, err := beginSnapshot(ctx, p.logger.Named("postgreSQLClient.snapshot"), p.db, sql.LevelRepeatableRead)
 	if err != nil {
-		logger.Error("run query", "err", err)
-		return nil, fmt.Errorf("run query: %w", err)
+		logger.Error("begin snapshot", "err", err)
+		return nil, fmt.Errorf("begin snapshot: %w", err)
 	}
-	defer rows.Close()
 
-	var result []string
-	for rows.Next() {
-		var row string
+	return snapshot, nil
+}
+
+func (p *postgreSQLClient) BeginImport(ctx context.Context, options ImportOptions) (Importer, error) {
+	logger := p.logger.Named("postgreSQLClient.BeginImport")
 
-		err := rows.Scan(&row)
-		if err != nil {
-			logger.Error("scan row", "err", err)
-			continue
+	description, err := p.DescribeTable(ctx, options.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	importer, err := beginImport(ctx, p.db, postgreSQLDialect{}, description, options, postgreSQLQueryError)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, err
 		}
 
-		result = append(result, row)
+		logger.Error("begin import", "err", err)
+		return nil, fmt.Errorf("begin import: %w", err)
 	}
-	if rows.Err() != nil {
-		logger.Error("got sql rows error", "rows.Err", rows.Err())
-		return nil, fmt.Errorf("got sql rows error: %w", rows.Err())
+
+	return importer, nil
+}
+
+// postgreSQLQueryError converts an error reported by the database into *QueryError.
+// Errors of connection, server resources and internal errors are not caused by the query, so nil is returned for them.
+func postgreSQLQueryError(err error) *QueryError {
+	pqErr := &pq.Error{}
+	if !errors.As(err, &pqErr) || slices.Contains([]pq.ErrorClass{"08", "53", "57", "58", "XX"}, pqErr.Code.Class()) {
+		return nil
 	}
-	logger.Debug("got result", "result", result)
 
-	return result, nil
+	return &QueryError{Message: pqErr.Message}
+}
+
+func (p *postgreSQLClient) BuildCreateTableQuery(options CreateTableOptions) (Query, error) {
+	logger := p.logger.Named("postgreSQLClient.BuildCreateTableQuery")
+
+	query, err := buildCreateTableQuery(postgreSQLDialect{}, options)
+	if err != nil {
+		logger.Info(err.Error())
+		return Query{}, err
+	}
+
+	return query, nil
+}
+
+func (p *postgreSQLClient) CreateTable(ctx context.Context, options CreateTableOptions) error {
+	logger := p.logger.Named("postgreSQLClient.CreateTable")
+
+	query, err := buildCreateTableQuery(postgreSQLDialect{}, options)
+	if err != nil {
+		logger.Info(err.Error())
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query.Text)
+	if err != nil {
+		if queryErr := postgreSQLQueryError(err); queryErr != nil {
+			logger.Info(queryErr.Message)
+			return queryErr
+		}
+
+		logger.Error("create table", "err", err)
+		return fmt.Errorf("create table: %w", err)
+	}
+
+	return nil
+}
+
+func (p *postgreSQLClient) Stats() DBStats {
+	return getDBStats(p.db)
+}
+
+func (p *postgreSQLClient) Close() error {
+	return closeDB(p.logger.Named("postgreSQLClient.Close"), p.db)
+}
+
+type postgreSQLDialect struct{}
+
+var _ sqlDialect = (*postgreSQLDialect)(nil)
+
+func (postgreSQLDialect) quoteIdentifier(name string) string {
+	return pq.QuoteIdentifier(name)
+}
+
+// quoteString uses escape string syntax for values with backslashes, so they are escaped regardless of server settings.
+func (postgreSQLDialect) quoteString(value string) string {
+	return pq.QuoteLiteral(value)
+}
+
+func (postgreSQLDialect) placeholder(position int) string {
+	return fmt.Sprintf("$%d", position)
+}
+
+// rowToJSON merges additional arguments into the row instead of listing all columns,
+// because number of jsonb_build_object arguments is limited.
+func (postgreSQLDialect) rowToJSON(tableName string, _ []string, arguments []string) string {
+	if len(arguments) == 0 {
+		return fmt.Sprintf("to_jsonb(%s)", tableName)
+	}
+
+	return fmt.Sprintf("(to_jsonb(%s) || jsonb_build_object(%s))", tableName, strings.Join(arguments, ", "))
+}
+
+func (d postgreSQLDialect) fieldsToJSON(fields []string, arguments []string) string {
+	return fmt.Sprintf("jsonb_build_object(%s)", buildJSONObjectArguments(d, fields, arguments))
+}
+
+func (postgreSQLDialect) nestedJSON(subquery string) string {
+	return fmt.Sprintf("(%s)", subquery)
+}
+
+func (postgreSQLDialect) aggregateJSON(value string) string {
+	return fmt.Sprintf("COALESCE(jsonb_agg(%s), '[]'::jsonb)", value)
+}
+
+func (postgreSQLDialect) iLike(column, placeholder string) string {
+	return fmt.Sprintf("%s ILIKE %s", column, placeholder)
+}
+
+// orderByAny casts the column to text, because json, xml and geometric types have no ordering operator.
+func (postgreSQLDialect) orderByAny(column string) string {
+	return column + "::text"
+}
+
+// nullsSortFirst returns false, because NULL values are larger than any other value in PostgreSQL.
+func (postgreSQLDialect) nullsSortFirst() bool {
+	return false
+}
+
+func (postgreSQLDialect) orderByNulls(column, direction string, nullsFirst bool) string {
+	if nullsFirst {
+		return fmt.Sprintf("%s %s NULLS FIRST", column, direction)
+	}
+
+	return fmt.Sprintf("%s %s NULLS LAST", column, direction)
+}
+
+func (postgreSQLDialect) noLimit() string {
+	return "ALL"
+}
+
+func (postgreSQLDialect) columnType(kind ValueKind, _ bool) (string, error) {
+	switch kind {
+	case ValueKindInteger:
+		return "bigint", nil
+	case ValueKindNumber:
+		return "double precision", nil
+	case ValueKindBoolean:
+		return "boolean", nil
+	case ValueKindTimestamp:
+		return "timestamptz", nil
+	case ValueKindDate:
+		return "date", nil
+	case ValueKindUUID:
+		return "uuid", nil
+	case ValueKindText:
+		return "text", nil
+	case ValueKindJSON:
+		return "jsonb", nil
+	default:
+		return "", fmt.Errorf("%w: unknown kind of values %q", ErrInvalidTableDefinition, kind)
+	}
+}
+
+func (d postgreSQLDialect) upsert(conflictColumns, updateColumns []string) string {
+	return onConflict(d, conflictColumns, updateColumns)
+}
+
+func (postgreSQLDialect) lexicon() queryLexicon {
+	return queryLexicon{escapeStrings: true, dollarQuotes: true, nestedComments: true}
 }
 
 func handlePostgresError(err error) error {
@@ -196,15 +724,5 @@ func handlePostgresError(err error) error {
 		}
 	}
 
-	opErr := &net.OpError{}
-	if errors.As(err, &opErr) {
-		if strings.Contains(opErr.Error(), "no such host") {
-			return ErrInvalidHost
-		}
-		if strings.Contains(opErr.Error(), "connect: connection refused") {
-			return ErrInvalidPort
-		}
-	}
-
-	return nil
+	return handleNetworkError(err)
 }
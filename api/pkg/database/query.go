@@ -0,0 +1,527 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Query represents an SQL query with its bind parameters.
+type Query struct {
+	Text string
+	Args []interface{}
+	// Columns are names of the keys of JSON objects returned by the query, in the order of selection.
+	Columns []string
+
+	// order is used for building cursor of the next page, it's empty when rows are not ordered or order is not unique.
+	order []orderColumn
+}
+
+// Filter represents a structured WHERE condition.
+// It's either a single comparison of the field with value or a group of nested filters joined by AND/OR.
+type Filter struct {
+	Field    string         `json:"field"`
+	Operator FilterOperator `json:"operator"`
+	// Value is decoded from JSON with numbers as json.Number, so large integers keep their precision.
+	Value interface{} `json:"value"`
+
+	// And and Or are groups of nested filters. When any group is set, Field, Operator and Value are ignored.
+	And []Filter `json:"and"`
+	Or  []Filter `json:"or"`
+}
+
+// UnmarshalJSON decodes the filter keeping numbers of the value as json.Number instead of float64.
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	// Alias type has no UnmarshalJSON method, so it does not call itself.
+	type filter Filter
+
+	var decoded struct {
+		filter
+		Value json.RawMessage `json:"value"`
+	}
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+
+	*f = Filter(decoded.filter)
+	f.Value = nil
+
+	if len(decoded.Value) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(decoded.Value))
+	decoder.UseNumber()
+
+	return decoder.Decode(&f.Value)
+}
+
+// FilterOperator represents a comparison operator that could be used in Filter.
+type FilterOperator string
+
+const (
+	// OperatorEqual - field = value.
+	OperatorEqual FilterOperator = "eq"
+	// OperatorNotEqual - field <> value.
+	OperatorNotEqual FilterOperator = "neq"
+	// OperatorGreater - field > value.
+	OperatorGreater FilterOperator = "gt"
+	// OperatorGreaterOrEqual - field >= value.
+	OperatorGreaterOrEqual FilterOperator = "gte"
+	// OperatorLess - field < value.
+	OperatorLess FilterOperator = "lt"
+	// OperatorLessOrEqual - field <= value.
+	OperatorLessOrEqual FilterOperator = "lte"
+	// OperatorLike - field LIKE value.
+	OperatorLike FilterOperator = "like"
+	// OperatorILike - case-insensitive LIKE.
+	OperatorILike FilterOperator = "ilike"
+	// OperatorIn - field IN (values...), value must be a list.
+	OperatorIn FilterOperator = "in"
+	// OperatorNotIn - field NOT IN (values...), value must be a list.
+	OperatorNotIn FilterOperator = "not_in"
+	// OperatorIsNull - field IS NULL, value is ignored.
+	OperatorIsNull FilterOperator = "is_null"
+	// OperatorIsNotNull - field IS NOT NULL, value is ignored.
+	OperatorIsNotNull FilterOperator = "is_not_null"
+)
+
+var comparisonOperators = map[FilterOperator]string{
+	OperatorEqual:          "=",
+	OperatorNotEqual:       "<>",
+	OperatorGreater:        ">",
+	OperatorGreaterOrEqual: ">=",
+	OperatorLess:           "<",
+	OperatorLessOrEqual:    "<=",
+	OperatorLike:           "LIKE",
+}
+
+// TableName represents a table name, optionally qualified by schema.
+type TableName struct {
+	// Schema is empty when table is in the default schema of the connection.
+	Schema string
+	Name   string
+}
+
+// ParseTableName parses "table" or "schema.table" name.
+// Parts that contain dots must be wrapped into double quotes, double quote inside of them is escaped by doubling it.
+func ParseTableName(name string) (TableName, error) {
+	var (
+		parts     []string
+		part      strings.Builder
+		quoted    bool
+		wasQuoted bool
+	)
+
+	for i := 0; i < len(name); i++ {
+		char := name[i]
+
+		switch {
+		case quoted && char == '"' && i+1 < len(name) && name[i+1] == '"':
+			part.WriteByte('"')
+			i++
+
+		case char == '"' && (quoted || part.Len() == 0 && !wasQuoted):
+			quoted = !quoted
+			wasQuoted = true
+
+		case !quoted && char == '.':
+			parts = append(parts, part.String())
+			part.Reset()
+			wasQuoted = false
+
+		case !quoted && (char == '"' || wasQuoted):
+			return TableName{}, fmt.Errorf("%w: %s", ErrInvalidTableName, name)
+
+		default:
+			part.WriteByte(char)
+		}
+	}
+	if quoted {
+		return TableName{}, fmt.Errorf("%w: %s", ErrInvalidTableName, name)
+	}
+	parts = append(parts, part.String())
+
+	if len(parts) > 2 || slices.Contains(parts, "") {
+		return TableName{}, fmt.Errorf("%w: %s", ErrInvalidTableName, name)
+	}
+
+	if len(parts) == 1 {
+		return TableName{Name: parts[0]}, nil
+	}
+
+	return TableName{Schema: parts[0], Name: parts[1]}, nil
+}
+
+// String returns name in the format accepted by ParseTableName.
+func (t TableName) String() string {
+	quote := func(part string) string {
+		if strings.ContainsAny(part, `."`) {
+			return `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+		}
+
+		return part
+	}
+
+	if t.Schema == "" {
+		return quote(t.Name)
+	}
+
+	return quote(t.Schema) + "." + quote(t.Name)
+}
+
+// quoteTableName quotes table name, with schema when it's set.
+func quoteTableName(dialect sqlDialect, table TableName) string {
+	if table.Schema == "" {
+		return dialect.quoteIdentifier(table.Name)
+	}
+
+	return dialect.quoteIdentifier(table.Schema) + "." + dialect.quoteIdentifier(table.Name)
+}
+
+// sqlDialect describes SQL syntax differences between databases.
+type sqlDialect interface {
+	// quoteIdentifier quotes table or column name.
+	quoteIdentifier(name string) string
+	// quoteString quotes value as SQL string literal.
+	quoteString(value string) string
+	// placeholder returns bind parameter placeholder for the given 1-based position.
+	placeholder(position int) string
+	// rowToJSON returns an expression that converts the whole table row into JSON object.
+	// Arguments are additional "'key', expression" pairs of the object.
+	rowToJSON(tableName string, columns []string, arguments []string) string
+	// fieldsToJSON returns an expression that converts selected table fields into JSON object.
+	// Arguments are additional "'key', expression" pairs of the object.
+	fieldsToJSON(fields []string, arguments []string) string
+	// nestedJSON returns an expression that embeds JSON result of the scalar subquery into another JSON value.
+	nestedJSON(subquery string) string
+	// aggregateJSON returns an aggregate expression that collects JSON values into array, which is empty for no rows.
+	aggregateJSON(value string) string
+	// iLike returns a case-insensitive LIKE condition.
+	iLike(column, placeholder string) string
+	// orderByAny returns ORDER BY expression of the column, which could be used for columns of any type.
+	orderByAny(column string) string
+	// nullsSortFirst reports whether NULL values are placed first in ascending order by default.
+	nullsSortFirst() bool
+	// orderByNulls returns ORDER BY expression of the column with explicit position of NULL values.
+	orderByNulls(column, direction string, nullsFirst bool) string
+	// noLimit returns LIMIT value that does not limit rows, it's needed for OFFSET without LIMIT.
+	noLimit() string
+	// upsert returns a clause of INSERT statement, which updates the columns of rows conflicting by the conflict columns.
+	// Conflicting rows are left as is, when there are no columns to update.
+	upsert(conflictColumns, updateColumns []string) string
+	// columnType returns type of the column with values of the kind. Key columns could need a type with limited size.
+	columnType(kind ValueKind, key bool) (string, error)
+	// lexicon returns lexical rules that are used for validating queries written by user.
+	lexicon() queryLexicon
+}
+
+// structureLister provides tables structure, which is needed to build queries with related rows and ordering.
+type structureLister interface {
+	listColumns(table TableName) ([]string, error)
+	// listRelations returns foreign keys of the table and foreign keys of other tables that reference it.
+	listRelations(table TableName) ([]relation, error)
+	// listPrimaryKey returns primary key columns of the table, it's empty when table has no primary key.
+	listPrimaryKey(table TableName) ([]string, error)
+}
+
+// buildJSONObjectArguments returns "'field', "field", ..." list of arguments for JSON object functions,
+// which is followed by additional arguments.
+func buildJSONObjectArguments(dialect sqlDialect, fields []string, additionalArguments []string) string {
+	arguments := make([]string, len(fields), len(fields)+len(additionalArguments))
+
+	for i, f := range fields {
+		arguments[i] = fmt.Sprintf("%s, %s", dialect.quoteString(f), dialect.quoteIdentifier(f))
+	}
+
+	return strings.Join(append(arguments, additionalArguments...), ", ")
+}
+
+// queryBuilder builds select queries, with all identifiers validated against the list of table columns.
+type queryBuilder struct {
+	dialect sqlDialect
+	columns []string
+
+	args []interface{}
+}
+
+func newQueryBuilder(dialect sqlDialect, columns []string) *queryBuilder {
+	return &queryBuilder{
+		dialect: dialect,
+		columns: columns,
+	}
+}
+
+// buildSelectQuery builds a query that returns table rows as JSON.
+// Columns are the real columns of the table which are used for validating all user input identifiers.
+// Lister is used for expanding foreign keys and ordering rows by primary key, when it's requested.
+func buildSelectQuery(
+	dialect sqlDialect, table TableName, options BuildQueryOptions, columns []string, lister structureLister,
+) (Query, error) {
+	if len(columns) == 0 {
+		return Query{}, fmt.Errorf("%w: %s", ErrTableDoesNotExist, options.TableName)
+	}
+
+	builder := newQueryBuilder(dialect, columns)
+
+	if options.Offset < 0 || options.Offset != 0 && options.Cursor != "" {
+		return Query{}, fmt.Errorf("%w: cursor could not be used with offset", ErrInvalidCursor)
+	}
+
+	if len(options.GroupBy) != 0 || len(options.Aggregates) != 0 {
+		return buildAggregateQuery(builder, table, options)
+	}
+
+	err := builder.validateColumns(options.Fields...)
+	if err != nil {
+		return Query{}, err
+	}
+
+	// Table is aliased by its name, so the whole row could be referenced without schema.
+	tableAlias := dialect.quoteIdentifier(table.Name)
+	from := fmt.Sprintf("%s AS %s", quoteTableName(dialect, table), tableAlias)
+
+	if options.ExpandDepth < 0 || options.ExpandDepth > MaxExpandDepth {
+		return Query{}, fmt.Errorf("%w: %d", ErrInvalidExpandDepth, options.ExpandDepth)
+	}
+
+	// Related rows are selected by subqueries, so the whole document is built in a single query.
+	var relationArguments, relationKeys []string
+	if options.ExpandDepth != 0 {
+		expand := &expandBuilder{dialect: dialect, lister: lister}
+
+		var err error
+		relationArguments, relationKeys, err = expand.relationArguments(table, tableAlias, columns, options.ExpandDepth, nil)
+		if err != nil {
+			return Query{}, fmt.Errorf("expand foreign keys: %w", err)
+		}
+	}
+
+	// Every row is converted into a separate JSON object, so rows could be streamed one by one.
+	query := fmt.Sprintf("SELECT %s FROM %s", dialect.rowToJSON(tableAlias, columns, relationArguments), from)
+
+	selectedColumns := columns
+
+	if len(options.Fields) != 0 {
+		query = fmt.Sprintf("SELECT %s FROM %s", dialect.fieldsToJSON(options.Fields, relationArguments), from)
+		selectedColumns = options.Fields
+	}
+
+	selectedColumns = append(slices.Clip(selectedColumns), relationKeys...)
+
+	conditions, err := builder.buildConditions(options)
+	if err != nil {
+		return Query{}, err
+	}
+
+	// Rows are ordered only when it's requested or a part of them is selected, so pages do not overlap.
+	var order []orderColumn
+	var uniqueOrder bool
+	if len(options.Sort) != 0 || options.Limit != 0 || options.Offset != 0 || options.Cursor != "" {
+		orderColumns := columns
+		if len(options.Fields) != 0 {
+			orderColumns = options.Fields
+		}
+
+		order, uniqueOrder, err = builder.buildOrder(table, options.Sort, orderColumns, lister)
+		if err != nil {
+			return Query{}, err
+		}
+	}
+
+	// Rows after the cursor could be skipped or repeated, when other rows have the same values of order columns.
+	if options.Cursor != "" && !uniqueOrder {
+		return Query{}, fmt.Errorf("%w: %s has no primary key", ErrOrderNotUnique, table)
+	}
+
+	// Cursor of the next page is built from the last row, so it must contain values of all order columns.
+	if uniqueOrder && len(options.Fields) != 0 && (options.Limit != 0 || options.Cursor != "") {
+		for _, column := range order {
+			if !slices.Contains(options.Fields, column.name) {
+				return Query{}, fmt.Errorf("%w: fields do not contain %s", ErrOrderNotSelected, column.name)
+			}
+		}
+	}
+
+	if options.Cursor != "" {
+		values, err := decodeCursor(options.Cursor, order)
+		if err != nil {
+			return Query{}, err
+		}
+
+		conditions = append(conditions, builder.buildKeysetCondition(order, values))
+	}
+
+	if len(conditions) != 0 {
+		query += fmt.Sprintf(" WHERE %s", strings.Join(conditions, " AND "))
+	}
+	if len(order) != 0 {
+		query += " " + builder.buildOrderBy(order)
+	}
+
+	query += buildLimit(dialect, options.Limit, options.Offset)
+
+	// Cursor is built only for unique order, so the next page starts right after the last row.
+	var cursorOrder []orderColumn
+	if uniqueOrder {
+		cursorOrder = order
+	}
+
+	return Query{Text: query, Args: builder.args, Columns: selectedColumns, order: cursorOrder}, nil
+}
+
+// buildConditions returns raw and structured WHERE conditions, which must be joined by AND.
+func (b *queryBuilder) buildConditions(options BuildQueryOptions) ([]string, error) {
+	var conditions []string
+
+	if options.RawWhere != "" {
+		conditions = append(conditions, fmt.Sprintf("(%s)", options.RawWhere))
+	}
+
+	if options.Filter != nil {
+		condition, err := b.buildCondition(*options.Filter)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, nil
+}
+
+// buildLimit returns LIMIT and OFFSET clauses, it's empty when both of them are zero.
+func buildLimit(dialect sqlDialect, limit, offset int) string {
+	switch {
+	case limit != 0 && offset != 0:
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	case limit != 0:
+		return fmt.Sprintf(" LIMIT %d", limit)
+	case offset != 0:
+		return fmt.Sprintf(" LIMIT %s OFFSET %d", dialect.noLimit(), offset)
+	default:
+		return ""
+	}
+}
+
+func (b *queryBuilder) validateColumns(columns ...string) error {
+	for _, column := range columns {
+		if !slices.Contains(b.columns, column) {
+			return fmt.Errorf("%w: %s", ErrColumnDoesNotExist, column)
+		}
+	}
+
+	return nil
+}
+
+func (b *queryBuilder) bind(value interface{}) string {
+	b.args = append(b.args, value)
+
+	return b.dialect.placeholder(len(b.args))
+}
+
+func (b *queryBuilder) buildCondition(filter Filter) (string, error) {
+	if len(filter.And) != 0 && len(filter.Or) != 0 {
+		return "", fmt.Errorf("%w: filter could not contain both and/or groups", ErrInvalidFilter)
+	}
+	if len(filter.And) != 0 {
+		return b.buildGroup(filter.And, " AND ")
+	}
+	if len(filter.Or) != 0 {
+		return b.buildGroup(filter.Or, " OR ")
+	}
+
+	err := b.validateColumns(filter.Field)
+	if err != nil {
+		return "", err
+	}
+
+	column := b.dialect.quoteIdentifier(filter.Field)
+
+	switch filter.Operator {
+	case OperatorIsNull:
+		return fmt.Sprintf("%s IS NULL", column), nil
+
+	case OperatorIsNotNull:
+		return fmt.Sprintf("%s IS NOT NULL", column), nil
+
+	case OperatorIn, OperatorNotIn:
+		values, ok := filter.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return "", fmt.Errorf("%w: operator %s requires a non-empty list of values", ErrInvalidFilter, filter.Operator)
+		}
+
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			if !isScalar(value) {
+				return "", fmt.Errorf("%w: list for field %s must contain only scalar values", ErrInvalidFilter, filter.Field)
+			}
+
+			placeholders[i] = b.bind(filterBindValue(value))
+		}
+
+		operator := "IN"
+		if filter.Operator == OperatorNotIn {
+			operator = "NOT IN"
+		}
+
+		return fmt.Sprintf("%s %s (%s)", column, operator, strings.Join(placeholders, ", ")), nil
+	}
+
+	if filter.Value == nil || !isScalar(filter.Value) {
+		return "", fmt.Errorf("%w: operator %s requires a scalar value", ErrInvalidFilter, filter.Operator)
+	}
+
+	if filter.Operator == OperatorILike {
+		return b.dialect.iLike(column, b.bind(filterBindValue(filter.Value))), nil
+	}
+
+	operator, ok := comparisonOperators[filter.Operator]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, filter.Operator)
+	}
+
+	return fmt.Sprintf("%s %s %s", column, operator, b.bind(filterBindValue(filter.Value))), nil
+}
+
+func (b *queryBuilder) buildGroup(filters []Filter, separator string) (string, error) {
+	conditions := make([]string, len(filters))
+
+	for i, filter := range filters {
+		condition, err := b.buildCondition(filter)
+		if err != nil {
+			return "", err
+		}
+
+		conditions[i] = condition
+	}
+
+	return "(" + strings.Join(conditions, separator) + ")", nil
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, float64, int, int64, json.Number:
+		return true
+	default:
+		return false
+	}
+}
+
+// filterBindValue converts a scalar filter value into bind parameter.
+// Like in jsonBindValue, integers are passed as int64 and decimals as strings to keep their precision.
+func filterBindValue(value interface{}) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+
+	if integer, err := number.Int64(); err == nil {
+		return integer
+	}
+
+	return number.String()
+}
@@ -0,0 +1,228 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSQLiteBuildQueryFilter(t *testing.T) {
+	t.Parallel()
+
+	client := newTestSQLiteClient(t)
+
+	testCases := []struct {
+		name    string
+		filter  string
+		wantIDs []string
+		wantErr error
+	}{
+		{
+			name:    "equal",
+			filter:  `{"field": "title", "operator": "eq", "value": "Beta"}`,
+			wantIDs: []string{"2"},
+		},
+		{
+			name:    "equal to integer larger than float64 precision",
+			filter:  `{"field": "id", "operator": "eq", "value": 9007199254740993}`,
+			wantIDs: []string{"9007199254740993"},
+		},
+		{
+			name:    "in integers larger than float64 precision",
+			filter:  `{"field": "id", "operator": "in", "value": [9007199254740992, 9007199254740993]}`,
+			wantIDs: []string{"9007199254740993"},
+		},
+		{
+			name:    "not equal",
+			filter:  `{"field": "author_id", "operator": "neq", "value": 1}`,
+			wantIDs: []string{"3", "4"},
+		},
+		{
+			name:    "greater than decimal",
+			filter:  `{"field": "price", "operator": "gt", "value": 7.5}`,
+			wantIDs: []string{"1", "4"},
+		},
+		{
+			name:    "less or equal",
+			filter:  `{"field": "price", "operator": "lte", "value": 7}`,
+			wantIDs: []string{"3", "9007199254740993"},
+		},
+		{
+			name:    "not in",
+			filter:  `{"field": "id", "operator": "not_in", "value": [1, 2, 3]}`,
+			wantIDs: []string{"4", "9007199254740993"},
+		},
+		{
+			name:    "like",
+			filter:  `{"field": "title", "operator": "like", "value": "%a"}`,
+			wantIDs: []string{"1", "2", "3", "4"},
+		},
+		{
+			name:    "case-insensitive like",
+			filter:  `{"field": "title", "operator": "ilike", "value": "DEL%"}`,
+			wantIDs: []string{"4"},
+		},
+		{
+			name:    "is null",
+			filter:  `{"field": "price", "operator": "is_null"}`,
+			wantIDs: []string{"2"},
+		},
+		{
+			name:    "is not null",
+			filter:  `{"field": "author_id", "operator": "is_not_null"}`,
+			wantIDs: []string{"1", "2", "3", "4"},
+		},
+		{
+			name: "nested groups",
+			filter: `{"or": [
+				{"field": "id", "operator": "eq", "value": 1},
+				{"and": [
+					{"field": "author_id", "operator": "eq", "value": 2},
+					{"field": "price", "operator": "lt", "value": 10}
+				]}
+			]}`,
+			wantIDs: []string{"1", "3"},
+		},
+		{
+			name:    "unknown column",
+			filter:  `{"field": "missing", "operator": "eq", "value": 1}`,
+			wantErr: ErrColumnDoesNotExist,
+		},
+		{
+			name:    "unknown operator",
+			filter:  `{"field": "id", "operator": "between", "value": 1}`,
+			wantErr: ErrInvalidFilter,
+		},
+		{
+			name:    "empty list",
+			filter:  `{"field": "id", "operator": "in", "value": []}`,
+			wantErr: ErrInvalidFilter,
+		},
+		{
+			name:    "list for comparison",
+			filter:  `{"field": "id", "operator": "eq", "value": [1]}`,
+			wantErr: ErrInvalidFilter,
+		},
+		{
+			name:    "both groups",
+			filter:  `{"and": [{"field": "id", "operator": "is_null"}], "or": [{"field": "id", "operator": "is_null"}]}`,
+			wantErr: ErrInvalidFilter,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var filter Filter
+			err := json.Unmarshal([]byte(tc.filter), &filter)
+			if err != nil {
+				t.Fatalf("unmarshal filter: %v", err)
+			}
+
+			options := BuildQueryOptions{
+				TableName: "books",
+				Filter:    &filter,
+				Sort:      []Sort{{Field: "id"}},
+			}
+
+			if tc.wantErr != nil {
+				_, err = client.BuildQuery(options)
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("BuildQuery() error = %v, want %v", err, tc.wantErr)
+				}
+
+				return
+			}
+
+			_, rows := selectRows(t, client, options)
+			if ids := rowIDs(rows); !slices.Equal(ids, tc.wantIDs) {
+				t.Errorf("selected ids = %v, want %v", ids, tc.wantIDs)
+			}
+		})
+	}
+}
+
+func TestParseTableName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		input   string
+		want    TableName
+		wantErr bool
+	}{
+		{name: "table", input: "users", want: TableName{Name: "users"}},
+		{name: "schema and table", input: "public.users", want: TableName{Schema: "public", Name: "users"}},
+		{name: "quoted table with dot", input: `"odd.name"`, want: TableName{Name: "odd.name"}},
+		{name: "quoted schema and table", input: `"my schema"."my.table"`, want: TableName{Schema: "my schema", Name: "my.table"}},
+		{name: "escaped quote", input: `"say ""hi"""`, want: TableName{Name: `say "hi"`}},
+		{name: "empty", input: "", wantErr: true},
+		{name: "empty part", input: "public.", wantErr: true},
+		{name: "too many parts", input: "a.b.c", wantErr: true},
+		{name: "unterminated quote", input: `"users`, wantErr: true},
+		{name: "text after quote", input: `"users"x`, wantErr: true},
+		{name: "quote inside of part", input: `us"ers`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseTableName(tc.input)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidTableName) {
+					t.Fatalf("ParseTableName() error = %v, want %v", err, ErrInvalidTableName)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseTableName() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("ParseTableName() = %+v, want %+v", got, tc.want)
+			}
+			// Name must be parsed back from its string representation.
+			if again, err := ParseTableName(got.String()); err != nil || again != got {
+				t.Errorf("ParseTableName(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestDialectQuoteString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		dialect sqlDialect
+		value   string
+		want    string
+	}{
+		{name: "postgresql", dialect: postgreSQLDialect{}, value: "it's", want: `'it''s'`},
+		{name: "postgresql backslash", dialect: postgreSQLDialect{}, value: `x\`, want: ` E'x\\'`},
+		{name: "mysql", dialect: mySQLDialect{}, value: "it's", want: `'it''s'`},
+		{name: "mysql backslash", dialect: mySQLDialect{}, value: `x\', 1) -- `, want: `'x\\'', 1) -- '`},
+		{name: "sqlite", dialect: sqLiteDialect{}, value: `it's \`, want: `'it''s \'`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tc.dialect.quoteString(tc.value)
+			if got != tc.want {
+				t.Errorf("quoteString(%q) = %s, want %s", tc.value, got, tc.want)
+			}
+		})
+	}
+}
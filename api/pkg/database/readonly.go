@@ -0,0 +1,329 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/VladPetriv/d2j/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// ReadOnlyQueryOptions represents an options that used for running SQL query written by user.
+type ReadOnlyQueryOptions struct {
+	// Text is a single SELECT or WITH statement.
+	Text string
+	// Limit is a maximum number of returned rows, zero means no limit.
+	Limit int
+	// Timeout is a maximum time of query execution, zero means no timeout.
+	Timeout time.Duration
+}
+
+// QueryError is an error of the query written by user, which is reported by the database, e.g. syntax error.
+type QueryError struct {
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return "query failed: " + e.Message
+}
+
+// forbiddenQueryKeywords are keywords of statements that change data or schema.
+// Such statements could be nested into a single WITH statement, so every word of the query is checked.
+var forbiddenQueryKeywords = map[string]struct{}{
+	"INSERT": {}, "UPDATE": {}, "DELETE": {}, "MERGE": {}, "UPSERT": {}, "INTO": {}, "LOCK": {},
+	"CREATE": {}, "ALTER": {}, "DROP": {}, "TRUNCATE": {}, "GRANT": {}, "REVOKE": {},
+}
+
+// queryLexicon describes lexical differences of SQL dialects, which are important for finding statement boundaries.
+type queryLexicon struct {
+	// backslashEscapes - backslash escapes the next character inside of strings.
+	backslashEscapes bool
+	// escapeStrings - strings prefixed by E support backslash escapes.
+	escapeStrings bool
+	// dollarQuotes - strings could be quoted by $tag$.
+	dollarQuotes bool
+	// nestedComments - block comments could be nested.
+	nestedComments bool
+	// hashComments - # starts a comment till the end of line.
+	hashComments bool
+	// spacedDashComments - -- starts a comment only when it's followed by whitespace.
+	spacedDashComments bool
+	// executableComments - /*! ... */ comments are executed as a part of the statement.
+	executableComments bool
+	// bracketIdentifiers - identifiers could be quoted by square brackets.
+	bracketIdentifiers bool
+}
+
+// validateReadOnlyQuery checks that the text is a single statement that only selects data
+// and returns it without the trailing semicolon, so it could be used as a subquery.
+func validateReadOnlyQuery(dialect sqlDialect, text string) (string, error) {
+	lexicon := dialect.lexicon()
+
+	var (
+		words []string
+		end   = -1
+	)
+
+	for i := 0; i < len(text); {
+		char := text[i]
+
+		var (
+			next int
+			err  error
+		)
+
+		switch {
+		case unicode.IsSpace(rune(char)):
+			i++
+			continue
+
+		case isDashComment(text[i:], lexicon.spacedDashComments), lexicon.hashComments && char == '#':
+			next = strings.IndexByte(text[i:], '\n')
+			if next == -1 {
+				next = len(text)
+			} else {
+				next += i + 1
+			}
+
+			i = next
+			continue
+
+		case strings.HasPrefix(text[i:], "/*"):
+			if lexicon.executableComments && strings.HasPrefix(text[i:], "/*!") {
+				return "", fmt.Errorf("%w: executable comments are not allowed", ErrInvalidQuery)
+			}
+
+			i, err = skipBlockComment(text, i, lexicon.nestedComments)
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		// Anything except of comments after the end of statement is another statement.
+		if end != -1 {
+			return "", fmt.Errorf("%w: only a single statement is allowed", ErrInvalidQuery)
+		}
+
+		switch {
+		case char == ';':
+			end = i
+			next = i + 1
+
+		case char == '\'':
+			escapes := lexicon.backslashEscapes ||
+				lexicon.escapeStrings && i > 0 && (text[i-1] == 'E' || text[i-1] == 'e') && (i == 1 || !isWordChar(text[i-2]))
+			next, err = skipQuoted(text, i, '\'', escapes)
+
+		case char == '"':
+			next, err = skipQuoted(text, i, '"', lexicon.backslashEscapes)
+
+		case char == '`':
+			next, err = skipQuoted(text, i, '`', false)
+
+		case char == '[' && lexicon.bracketIdentifiers:
+			next, err = skipQuoted(text, i, ']', false)
+
+		case char == '$' && lexicon.dollarQuotes && dollarQuoteTag(text[i:]) != "":
+			tag := dollarQuoteTag(text[i:])
+
+			closing := strings.Index(text[i+len(tag):], tag)
+			if closing == -1 {
+				return "", fmt.Errorf("%w: unterminated dollar-quoted string", ErrInvalidQuery)
+			}
+			next = i + len(tag) + closing + len(tag)
+
+		case isWordChar(char):
+			// Dollar sign could be used inside of identifiers.
+			next = i
+			for next < len(text) && (isWordChar(text[next]) || text[next] == '$') {
+				next++
+			}
+
+			words = append(words, strings.ToUpper(text[i:next]))
+
+		default:
+			next = i + 1
+		}
+		if err != nil {
+			return "", err
+		}
+
+		i = next
+	}
+
+	if len(words) == 0 {
+		return "", fmt.Errorf("%w: query is empty", ErrInvalidQuery)
+	}
+	if words[0] != "SELECT" && words[0] != "WITH" {
+		return "", fmt.Errorf("%w: only SELECT and WITH statements are allowed", ErrInvalidQuery)
+	}
+
+	for i, word := range words {
+		_, forbidden := forbiddenQueryKeywords[word]
+
+		// Rows locking by FOR SHARE and FOR KEY SHARE requires write access.
+		if word == "SHARE" && (words[i-1] == "FOR" || words[i-1] == "KEY") {
+			forbidden = true
+		}
+
+		if forbidden {
+			return "", fmt.Errorf("%w: %s is not allowed", ErrInvalidQuery, word)
+		}
+	}
+
+	if end == -1 {
+		return text, nil
+	}
+
+	return text[:end], nil
+}
+
+// isDashComment reports whether the text starts with -- comment.
+func isDashComment(text string, spaced bool) bool {
+	if !strings.HasPrefix(text, "--") {
+		return false
+	}
+
+	return !spaced || len(text) == 2 || unicode.IsSpace(rune(text[2]))
+}
+
+// skipBlockComment returns position after the end of block comment that starts at the given position.
+func skipBlockComment(text string, start int, nested bool) (int, error) {
+	depth := 0
+
+	for i := start; i < len(text)-1; i++ {
+		switch {
+		case text[i] == '/' && text[i+1] == '*' && (nested || depth == 0):
+			depth++
+			i++
+
+		case text[i] == '*' && text[i+1] == '/':
+			depth--
+			i++
+
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unterminated comment", ErrInvalidQuery)
+}
+
+// skipQuoted returns position after the end of quoted string or identifier that starts at the given position.
+// Closing quote is escaped by doubling it.
+func skipQuoted(text string, start int, closing byte, backslashEscapes bool) (int, error) {
+	for i := start + 1; i < len(text); i++ {
+		switch {
+		case backslashEscapes && text[i] == '\\':
+			i++
+
+		case text[i] == closing:
+			if i+1 < len(text) && text[i+1] == closing {
+				i++
+				continue
+			}
+
+			return i + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unterminated quoted string or identifier", ErrInvalidQuery)
+}
+
+// dollarQuoteTag returns $tag$ that starts the text or empty string when text does not start with dollar quote.
+// Positional parameters like $1 are not dollar quotes, because tag could not start with a digit.
+func dollarQuoteTag(text string) string {
+	for i := 1; i < len(text); i++ {
+		switch {
+		case text[i] == '$':
+			return text[:i+1]
+		case !isWordChar(text[i]) || i == 1 && text[i] >= '0' && text[i] <= '9':
+			return ""
+		}
+	}
+
+	return ""
+}
+
+func isWordChar(char byte) bool {
+	return char == '_' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || char >= 0x80
+}
+
+// wrapReadOnlyQuery returns the query that converts rows of the validated query into JSON objects.
+// Query is placed on separate lines, because it could end with a line comment.
+func wrapReadOnlyQuery(rowToJSON, text string, limit int) Query {
+	query := fmt.Sprintf("SELECT %s FROM (\n%s\n) AS q", rowToJSON, text)
+	if limit != 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	return Query{Text: query}
+}
+
+// listReadOnlyQueryColumns returns names of the columns of the validated query without selecting its rows.
+func listReadOnlyQueryColumns(ctx context.Context, tx *sqlx.Tx, text string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT * FROM (\n%s\n) AS q LIMIT 0", text))
+	if err != nil {
+		return nil, fmt.Errorf("run query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("get columns: %w", err)
+	}
+
+	return columns, nil
+}
+
+// txBeginner starts transactions, it's implemented by database handle and connection.
+type txBeginner interface {
+	BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error)
+}
+
+// executeReadOnlyQuery runs the query inside of the read-only transaction and collects all rows.
+// Prepare is called at the beginning of the transaction and returns the query that must be executed.
+func executeReadOnlyQuery(
+	ctx context.Context, logger logger.Logger, db txBeginner, options ReadOnlyQueryOptions,
+	prepare func(ctx context.Context, tx *sqlx.Tx) (Query, error),
+) ([]string, error) {
+	if options.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("begin read-only transaction: %w", err)
+	}
+	// Nothing could be changed by the transaction, so it's always rolled back.
+	defer tx.Rollback() //nolint:errcheck
+
+	query, err := prepare(ctx, tx)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrQueryTimeout
+		}
+
+		return nil, err
+	}
+
+	result, err := executeQuery(ctx, logger, tx, query)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrQueryTimeout
+		}
+
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,253 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateReadOnlyQuery(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		dialect sqlDialect
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "select",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT * FROM users",
+			want:    "SELECT * FROM users",
+		},
+		{
+			name:    "trailing semicolon and comment are removed",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT 1; -- done",
+			want:    "SELECT 1",
+		},
+		{
+			name:    "with select",
+			dialect: postgreSQLDialect{},
+			text:    "WITH t AS (SELECT 1 AS id) SELECT id FROM t",
+			want:    "WITH t AS (SELECT 1 AS id) SELECT id FROM t",
+		},
+		{
+			name:    "with insert",
+			dialect: postgreSQLDialect{},
+			text:    "WITH t AS (INSERT INTO users (name) VALUES ('a') RETURNING id) SELECT id FROM t",
+			wantErr: true,
+		},
+		{
+			name:    "with delete",
+			dialect: mySQLDialect{},
+			text:    "WITH t AS (SELECT 1) DELETE FROM users",
+			wantErr: true,
+		},
+		{
+			name:    "update",
+			dialect: sqLiteDialect{},
+			text:    "UPDATE users SET name = 'a'",
+			wantErr: true,
+		},
+		{
+			name:    "select into",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT * INTO copy FROM users",
+			wantErr: true,
+		},
+		{
+			name:    "for share",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT * FROM users FOR KEY SHARE",
+			wantErr: true,
+		},
+		{
+			name:    "multiple statements",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT 1; DROP TABLE users",
+			wantErr: true,
+		},
+		{
+			name:    "multiple selects",
+			dialect: sqLiteDialect{},
+			text:    "SELECT 1; SELECT 2",
+			wantErr: true,
+		},
+		{
+			name:    "semicolon in string",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT ';DROP TABLE users' AS name",
+			want:    "SELECT ';DROP TABLE users' AS name",
+		},
+		{
+			name:    "semicolon in quoted identifier",
+			dialect: postgreSQLDialect{},
+			text:    `SELECT 1 AS "a;b"`,
+			want:    `SELECT 1 AS "a;b"`,
+		},
+		{
+			name:    "keyword in string",
+			dialect: sqLiteDialect{},
+			text:    "SELECT * FROM users WHERE name = 'DELETE'",
+			want:    "SELECT * FROM users WHERE name = 'DELETE'",
+		},
+		{
+			name:    "unterminated string",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT 'a",
+			wantErr: true,
+		},
+		{
+			name:    "dollar quoted string",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT $$; DROP TABLE users$$ AS text",
+			want:    "SELECT $$; DROP TABLE users$$ AS text",
+		},
+		{
+			name:    "tagged dollar quoted string",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT $body$ $$; $body$ AS text",
+			want:    "SELECT $body$ $$; $body$ AS text",
+		},
+		{
+			name:    "unterminated dollar quoted string",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT $tag$; DROP TABLE users",
+			wantErr: true,
+		},
+		{
+			name:    "positional parameter is not dollar quote",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT $1; DROP TABLE users $1",
+			wantErr: true,
+		},
+		{
+			name:    "dollar quotes are not supported by mysql",
+			dialect: mySQLDialect{},
+			text:    "SELECT $$; DELETE FROM users; $$",
+			wantErr: true,
+		},
+		{
+			name:    "escape string",
+			dialect: postgreSQLDialect{},
+			text:    `SELECT E'\'; DROP TABLE users' AS text`,
+			want:    `SELECT E'\'; DROP TABLE users' AS text`,
+		},
+		{
+			name:    "backslash is not escape in standard string",
+			dialect: postgreSQLDialect{},
+			text:    `SELECT '\'; DROP TABLE users; --'`,
+			wantErr: true,
+		},
+		{
+			name:    "backslash escape in mysql string",
+			dialect: mySQLDialect{},
+			text:    `SELECT '\'; DROP TABLE users' AS text`,
+			want:    `SELECT '\'; DROP TABLE users' AS text`,
+		},
+		{
+			name:    "nested comments",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT 1 /* outer /* inner */ ; DROP TABLE users */",
+			want:    "SELECT 1 /* outer /* inner */ ; DROP TABLE users */",
+		},
+		{
+			name:    "comments are not nested in mysql",
+			dialect: mySQLDialect{},
+			text:    "SELECT 1 /* outer /* inner */ ; DROP TABLE users */",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated nested comment",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT 1 /* outer /* inner */",
+			wantErr: true,
+		},
+		{
+			name:    "executable comment",
+			dialect: mySQLDialect{},
+			text:    "SELECT 1 /*! ; DROP TABLE users */",
+			wantErr: true,
+		},
+		{
+			name:    "versioned executable comment",
+			dialect: mySQLDialect{},
+			text:    "SELECT 1 /*!50000 INTO OUTFILE '/tmp/users' */",
+			wantErr: true,
+		},
+		{
+			name:    "exclamation comment is regular comment in postgresql",
+			dialect: postgreSQLDialect{},
+			text:    "SELECT 1 /*! comment */",
+			want:    "SELECT 1 /*! comment */",
+		},
+		{
+			name:    "hash comment",
+			dialect: mySQLDialect{},
+			text:    "SELECT 1 # ; DROP TABLE users\n",
+			want:    "SELECT 1 # ; DROP TABLE users\n",
+		},
+		{
+			name:    "dash without space is not comment in mysql",
+			dialect: mySQLDialect{},
+			text:    "SELECT 1 --1; DROP TABLE users",
+			wantErr: true,
+		},
+		{
+			name:    "bracket identifier",
+			dialect: sqLiteDialect{},
+			text:    "SELECT [a;b] FROM users",
+			want:    "SELECT [a;b] FROM users",
+		},
+		{
+			name:    "bracket identifier with keyword",
+			dialect: sqLiteDialect{},
+			text:    "SELECT [delete] FROM users",
+			want:    "SELECT [delete] FROM users",
+		},
+		{
+			name:    "unterminated bracket identifier",
+			dialect: sqLiteDialect{},
+			text:    "SELECT [a FROM users",
+			wantErr: true,
+		},
+		{
+			name:    "empty query",
+			dialect: postgreSQLDialect{},
+			text:    " ; -- nothing",
+			wantErr: true,
+		},
+		{
+			name:    "not select",
+			dialect: postgreSQLDialect{},
+			text:    "EXPLAIN SELECT 1",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := validateReadOnlyQuery(tc.dialect, tc.text)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidQuery) {
+					t.Fatalf("validateReadOnlyQuery() error = %v, want %v", err, ErrInvalidQuery)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("validateReadOnlyQuery() unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("validateReadOnlyQuery() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
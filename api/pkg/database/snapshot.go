@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/VladPetriv/d2j/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Snapshot runs queries inside of a single read-only transaction, so all of them see the same state of the database.
+// It holds a connection until it's closed, so it must be closed by the caller.
+type Snapshot interface {
+	// StreamQuery works like DBClient.StreamQuery, but reads rows from the snapshot.
+	StreamQuery(ctx context.Context, query Query, handleRow func(row []byte) error) error
+	Close() error
+}
+
+type snapshot struct {
+	logger logger.Logger
+
+	tx *sqlx.Tx
+}
+
+var _ Snapshot = (*snapshot)(nil)
+
+// beginSnapshot starts a read-only transaction with the given isolation level.
+// Isolation level must keep the state of the first query for the whole transaction.
+func beginSnapshot(ctx context.Context, logger logger.Logger, db txBeginner, isolation sql.IsolationLevel) (*snapshot, error) {
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{Isolation: isolation, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("begin read-only transaction: %w", err)
+	}
+
+	return &snapshot{logger: logger, tx: tx}, nil
+}
+
+func (s *snapshot) StreamQuery(ctx context.Context, query Query, handleRow func(row []byte) error) error {
+	return streamQuery(ctx, s.logger, s.tx, query, handleRow)
+}
+
+// Close rolls back the transaction, because nothing could be changed by it.
+func (s *snapshot) Close() error {
+	err := s.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("rollback transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,889 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/VladPetriv/d2j/pkg/errs"
+	"github.com/VladPetriv/d2j/pkg/logger"
+	"github.com/jmoiron/sqlx"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+type sqLite struct {
+	logger logger.Logger
+
+	directory string
+}
+
+var _ Database = (*sqLite)(nil)
+
+// NewSQLite is used to create an instance of sqlite database.
+// Database files could be opened only from the given directory.
+func NewSQLite(logger logger.Logger, directory string) *sqLite {
+	return &sqLite{
+		logger:    logger,
+		directory: directory,
+	}
+}
+
+type sqLiteClient struct {
+	logger logger.Logger
+
+	db *sqlx.DB
+}
+
+var _ DBClient = (*sqLiteClient)(nil)
+
+func newSQLiteClient(logger logger.Logger, db *sqlx.DB) *sqLiteClient {
+	return &sqLiteClient{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (s *sqLite) Connect(options ConnectionOptions) (DBClient, error) {
+	logger := s.logger.Named("sqLite.Connect")
+
+	// Join path with root to prevent escaping from the databases directory via "..".
+	path := filepath.Join(s.directory, filepath.Join("/", options.Path))
+	logger.Debug("built database file path", "path", path)
+
+	_, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Info(ErrDatabaseDoesNotExists.Error(), "path", path)
+			return nil, ErrDatabaseDoesNotExists
+		}
+
+		logger.Error("get database file info", "err", err)
+		return nil, fmt.Errorf("get database file info: %w", err)
+	}
+
+	// Open file in read-write mode to not create a new database when file is missing.
+	db, err := sqlx.Connect("sqlite", fmt.Sprintf("file:%s?mode=rw", escapeSQLitePath(path)))
+	if err != nil {
+		logger.Error("connect to sqlite", "err", err)
+		return nil, fmt.Errorf("connect to sqlite: %w", err)
+	}
+
+	// SQLite opens any file lazily, so read the schema to make sure that file is a valid database.
+	_, err = db.Exec("SELECT count(*) FROM sqlite_master;")
+	if err != nil {
+		db.Close()
+
+		if sqliteErr := handleSQLiteError(err); sqliteErr != nil {
+			logger.Info(sqliteErr.Error())
+			return nil, sqliteErr
+		}
+
+		logger.Error("read sqlite schema", "err", err)
+		return nil, fmt.Errorf("read sqlite schema: %w", err)
+	}
+
+	applyConnectionLimits(db, options)
+
+	logger.Info("connected to sqlite")
+	return newSQLiteClient(logger, db), nil
+}
+
+func (s *sqLiteClient) BuildQuery(options BuildQueryOptions) (Query, error) {
+	logger := s.logger.Named("sqLiteClient.BuildQuery")
+
+	table, err := ParseTableName(options.TableName)
+	if err != nil {
+		logger.Info(err.Error())
+		return Query{}, err
+	}
+
+	columns, err := s.listColumns(table)
+	if err != nil {
+		logger.Error("list table columns", "err", err)
+		return Query{}, fmt.Errorf("list table columns: %w", err)
+	}
+	logger.Debug("got table columns", "columns", columns)
+
+	return buildSelectQuery(sqLiteDialect{}, table, options, columns, s)
+}
+
+func (s *sqLiteClient) listColumns(table TableName) ([]string, error) {
+	// Table functions fail for unknown schema, while missing table must be reported as table without columns.
+	exists, err := s.schemaExists(context.Background(), sqLiteSchemaName(table.Schema))
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var columns []string
+	err = s.db.Select(
+		&columns,
+		"SELECT name FROM pragma_table_info(?, ?) ORDER BY cid;",
+		table.Name, sqLiteSchemaName(table.Schema),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select sqlite column names: %w", err)
+	}
+
+	return columns, nil
+}
+
+// listRelations reads foreign keys of all tables of the schema, because SQLite does not have a catalog of them.
+// Foreign keys could reference only tables of the same schema.
+func (s *sqLiteClient) listRelations(table TableName) ([]relation, error) {
+	schemaName := sqLiteSchemaName(table.Schema)
+
+	// Foreign key ids are unique only within the table, so they are prefixed by the name of referencing table.
+	var columns []relationColumn
+	err := s.db.Select(
+		&columns,
+		fmt.Sprintf(
+			`SELECT ? || '.' || fk.id AS constraint_key,
+				false AS many,
+				? AS related_schema,
+				fk."table" AS related_table,
+				fk."from" AS column_name,
+				fk."to" AS related_column,
+				fk.seq AS position
+			FROM pragma_foreign_key_list(?, ?) AS fk
+			UNION ALL
+			SELECT m.name || '.' || fk.id AS constraint_key,
+				true AS many,
+				? AS related_schema,
+				m.name AS related_table,
+				fk."to" AS column_name,
+				fk."from" AS related_column,
+				fk.seq AS position
+			FROM %s.sqlite_schema AS m, pragma_foreign_key_list(m.name, ?) AS fk
+			WHERE m.type = 'table' AND fk."table" = ? COLLATE NOCASE
+			ORDER BY many, related_table, constraint_key, position;`,
+			sqLiteDialect{}.quoteIdentifier(schemaName),
+		),
+		table.Name, schemaName, table.Name, schemaName, schemaName, schemaName, table.Name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select sqlite foreign keys: %w", err)
+	}
+
+	relations := groupRelations(columns)
+
+	// Foreign key that does not list referenced columns references primary key of the table.
+	for i, rel := range relations {
+		if len(rel.Columns) == len(rel.RelatedColumns) {
+			continue
+		}
+
+		referencedTable := TableName{Schema: schemaName, Name: rel.Table.Name}
+		if rel.Many {
+			referencedTable.Name = table.Name
+		}
+
+		primaryKey, err := s.listPrimaryKey(referencedTable)
+		if err != nil {
+			return nil, err
+		}
+
+		if rel.Many {
+			relations[i].Columns = primaryKey
+		} else {
+			relations[i].RelatedColumns = primaryKey
+		}
+	}
+
+	return relations, nil
+}
+
+func (s *sqLiteClient) listPrimaryKey(table TableName) ([]string, error) {
+	var columns []string
+	err := s.db.Select(
+		&columns,
+		"SELECT name FROM pragma_table_info(?, ?) WHERE pk > 0 ORDER BY pk;",
+		table.Name, sqLiteSchemaName(table.Schema),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select sqlite primary key: %w", err)
+	}
+
+	return columns, nil
+}
+
+// ListSchemas returns names of the attached databases, which play role of schemas in SQLite.
+func (s *sqLiteClient) ListSchemas(ctx context.Context) ([]string, error) {
+	logger := s.logger.Named("sqLiteClient.ListSchemas")
+
+	var schemas []string
+	err := s.db.SelectContext(ctx, &schemas, "SELECT name FROM pragma_database_list ORDER BY seq;")
+	if err != nil {
+		logger.Error("select sqlite schema names", "err", err)
+		return nil, fmt.Errorf("select sqlite schema names: %w", err)
+	}
+	logger.Debug("got all sqlite schemas", "schemas", schemas)
+
+	return schemas, nil
+}
+
+func (s *sqLiteClient) ListTables(ctx context.Context, schemaName string) ([]Table, error) {
+	logger := s.logger.Named("sqLiteClient.ListTables")
+
+	var tables []Table
+	err := s.db.SelectContext(
+		ctx,
+		&tables,
+		`SELECT schema AS schemaname, name AS tablename,
+			CASE type WHEN 'view' THEN 'view' ELSE 'table' END AS kind
+		FROM pragma_table_list
+		WHERE schema = ? AND type IN ('table', 'virtual', 'view') AND name NOT LIKE 'sqlite_%'
+		ORDER BY name;`,
+		sqLiteSchemaName(schemaName),
+	)
+	if err != nil {
+		logger.Error("select sqlite table names", "err", err)
+		return nil, fmt.Errorf("select sqlite table names: %w", err)
+	}
+	logger.Debug("got sqlite tables", "tables", tables)
+
+	return tables, nil
+}
+
+func (s *sqLiteClient) schemaExists(ctx context.Context, schemaName string) (bool, error) {
+	var exists bool
+	err := s.db.GetContext(ctx, &exists, "SELECT count(*) > 0 FROM pragma_database_list WHERE name = ?;", schemaName)
+	if err != nil {
+		return false, fmt.Errorf("check sqlite schema existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// sqLiteSchemaName returns schema name with "main" as a default one.
+func sqLiteSchemaName(schemaName string) string {
+	if schemaName == "" {
+		return "main"
+	}
+
+	return schemaName
+}
+
+// RefreshMaterializedView always fails, because SQLite does not have materialized views.
+func (s *sqLiteClient) RefreshMaterializedView(_ context.Context, tableName string) error {
+	return fmt.Errorf("%w: %s", ErrNotMaterializedView, tableName)
+}
+
+func (s *sqLiteClient) DescribeTable(ctx context.Context, tableName string) (*TableDescription, error) {
+	logger := s.logger.Named("sqLiteClient.DescribeTable")
+
+	table, err := ParseTableName(tableName)
+	if err != nil {
+		logger.Info(err.Error())
+		return nil, err
+	}
+	tableName = table.Name
+	schemaName := sqLiteSchemaName(table.Schema)
+
+	exists, err := s.schemaExists(ctx, schemaName)
+	if err != nil {
+		logger.Error("check schema existence", "err", err)
+		return nil, fmt.Errorf("check schema existence: %w", err)
+	}
+	if !exists {
+		logger.Info(ErrTableDoesNotExist.Error(), "tableName", tableName)
+		return nil, fmt.Errorf("%w: %s", ErrTableDoesNotExist, tableName)
+	}
+
+	var columns []struct {
+		Column
+		PrimaryKeyPosition int `db:"pk"`
+	}
+	err = s.db.SelectContext(
+		ctx,
+		&columns,
+		`SELECT name, type AS data_type, "notnull" = 0 AS nullable, dflt_value AS default_value, pk
+		FROM pragma_table_info(?, ?)
+		ORDER BY cid;`,
+		tableName, schemaName,
+	)
+	if err != nil {
+		logger.Error("select sqlite columns", "err", err)
+		return nil, fmt.Errorf("select sqlite columns: %w", err)
+	}
+	if len(columns) == 0 {
+		logger.Info(ErrTableDoesNotExist.Error(), "tableName", tableName)
+		return nil, fmt.Errorf("%w: %s", ErrTableDoesNotExist, tableName)
+	}
+	logger.Debug("got table columns", "columns", columns)
+
+	description := TableDescription{
+		Dialect:    DialectSQLite,
+		SchemaName: schemaName,
+		TableName:  tableName,
+		Columns:    make([]Column, len(columns)),
+		PrimaryKey: make([]string, 0),
+	}
+
+	// Primary key is taken from columns, because INTEGER PRIMARY KEY is an alias of rowid and has no index.
+	primaryKey := make(map[int]string)
+	for i, column := range columns {
+		description.Columns[i] = column.Column
+
+		if column.PrimaryKeyPosition != 0 {
+			primaryKey[column.PrimaryKeyPosition] = column.Name
+		}
+	}
+	for position := 1; position <= len(primaryKey); position++ {
+		description.PrimaryKey = append(description.PrimaryKey, primaryKey[position])
+	}
+
+	// INTEGER PRIMARY KEY column could not contain NULL even without NOT NULL constraint.
+	if len(primaryKey) == 1 {
+		for i, column := range description.Columns {
+			if column.Name == primaryKey[1] && strings.EqualFold(column.DataType, "INTEGER") {
+				description.Columns[i].Nullable = false
+			}
+		}
+	}
+
+	// Expression indexes do not have column name, so an empty name is returned for them.
+	var indexColumns []indexColumn
+	err = s.db.SelectContext(
+		ctx,
+		&indexColumns,
+		`SELECT il.name AS index_name,
+			COALESCE(ii.name, '') AS column_name,
+			il."unique" AS is_unique,
+			il.origin = 'pk' AS is_primary
+		FROM pragma_index_list(?, ?) AS il, pragma_index_info(il.name, ?) AS ii
+		ORDER BY il.name, ii.seqno;`,
+		tableName, schemaName, schemaName,
+	)
+	if err != nil {
+		logger.Error("select sqlite indexes", "err", err)
+		return nil, fmt.Errorf("select sqlite indexes: %w", err)
+	}
+	logger.Debug("got table indexes", "indexColumns", indexColumns)
+
+	description.Indexes = groupIndexes(indexColumns)
+
+	// SQLite does not name foreign keys, so their ids are used for grouping columns.
+	var foreignKeyColumns []foreignKeyColumn
+	err = s.db.SelectContext(
+		ctx,
+		&foreignKeyColumns,
+		`SELECT CAST(id AS TEXT) AS constraint_key,
+			'' AS name,
+			"from" AS column_name,
+			? AS referenced_schema,
+			"table" AS referenced_table,
+			"to" AS referenced_column,
+			on_update,
+			on_delete
+		FROM pragma_foreign_key_list(?, ?)
+		ORDER BY id, seq;`,
+		schemaName, tableName, schemaName,
+	)
+	if err != nil {
+		logger.Error("select sqlite foreign keys", "err", err)
+		return nil, fmt.Errorf("select sqlite foreign keys: %w", err)
+	}
+	logger.Debug("got table foreign keys", "foreignKeyColumns", foreignKeyColumns)
+
+	description.ForeignKeys = groupForeignKeys(foreignKeyColumns)
+
+	// SQLite does not expose check constraints, so they are parsed from the table definition.
+	var definitions []string
+	err = s.db.SelectContext(
+		ctx,
+		&definitions,
+		fmt.Sprintf(`SELECT COALESCE(sql, '') FROM %s.sqlite_schema WHERE type = 'table' AND name = ?;`, sqLiteDialect{}.quoteIdentifier(schemaName)),
+		tableName,
+	)
+	if err != nil {
+		logger.Error("select sqlite table definition", "err", err)
+		return nil, fmt.Errorf("select sqlite table definition: %w", err)
+	}
+
+	description.Checks = make([]CheckConstraint, 0)
+	for _, definition := range definitions {
+		description.Checks = append(description.Checks, parseSQLiteCheckConstraints(definition)...)
+	}
+	logger.Debug("got table check constraints", "checks", description.Checks)
+
+	return &description, nil
+}
+
+// parseSQLiteCheckConstraints returns check constraints declared in CREATE TABLE statement.
+func parseSQLiteCheckConstraints(definition string) []CheckConstraint {
+	var (
+		checks []CheckConstraint
+		// words contains the last keywords and identifiers, which are used to find constraint names.
+		words []sqLiteWord
+	)
+
+	for i := 0; i < len(definition); {
+		char := definition[i]
+
+		switch {
+		case char == '\'' || char == '"' || char == '`' || char == '[':
+			end := skipSQLiteQuoted(definition, i)
+			words = append(words, sqLiteWord{text: unquoteSQLiteIdentifier(definition[i:end]), quoted: true})
+			i = end
+
+		case char == '-' && strings.HasPrefix(definition[i:], "--"):
+			end := strings.IndexByte(definition[i:], '\n')
+			if end < 0 {
+				return checks
+			}
+			i += end
+
+		case char == '/' && strings.HasPrefix(definition[i:], "/*"):
+			end := strings.Index(definition[i+2:], "*/")
+			if end < 0 {
+				return checks
+			}
+			i += end + 4
+
+		case char == '(' && len(words) > 0 && words[len(words)-1].isKeyword("CHECK"):
+			end := skipSQLiteParentheses(definition, i)
+
+			check := CheckConstraint{Expression: strings.TrimSpace(definition[i+1 : end-1])}
+			if len(words) >= 3 && words[len(words)-3].isKeyword("CONSTRAINT") {
+				check.Name = words[len(words)-2].text
+			}
+			checks = append(checks, check)
+
+			words = words[:0]
+			i = end
+
+		case isSQLiteWordChar(char):
+			start := i
+			for i < len(definition) && isSQLiteWordChar(definition[i]) {
+				i++
+			}
+			words = append(words, sqLiteWord{text: definition[start:i]})
+
+		default:
+			if char == ',' || char == '(' || char == ')' {
+				words = words[:0]
+			}
+			i++
+		}
+	}
+
+	return checks
+}
+
+// sqLiteWord is a keyword or an identifier of SQL statement.
+type sqLiteWord struct {
+	text string
+	// quoted identifiers are never keywords, even when their text is the same.
+	quoted bool
+}
+
+func (w sqLiteWord) isKeyword(keyword string) bool {
+	return !w.quoted && strings.EqualFold(w.text, keyword)
+}
+
+// skipSQLiteQuoted returns position after the quoted string or identifier that starts at the position.
+func skipSQLiteQuoted(definition string, start int) int {
+	closing := definition[start]
+	if closing == '[' {
+		closing = ']'
+	}
+
+	for i := start + 1; i < len(definition); i++ {
+		if definition[i] != closing {
+			continue
+		}
+
+		// Quotes are escaped by doubling, except for square brackets.
+		if closing != ']' && i+1 < len(definition) && definition[i+1] == closing {
+			i++
+			continue
+		}
+
+		return i + 1
+	}
+
+	return len(definition)
+}
+
+// skipSQLiteParentheses returns position after the closing parenthesis of the one that starts at the position.
+func skipSQLiteParentheses(definition string, start int) int {
+	depth := 0
+
+	for i := start; i < len(definition); {
+		switch definition[i] {
+		case '\'', '"', '`', '[':
+			i = skipSQLiteQuoted(definition, i)
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+		i++
+	}
+
+	return len(definition)
+}
+
+// unquoteSQLiteIdentifier removes quotes of the identifier or string.
+func unquoteSQLiteIdentifier(quoted string) string {
+	if len(quoted) < 2 {
+		return quoted
+	}
+
+	quote := quoted[:1]
+	if quote == "[" {
+		return quoted[1 : len(quoted)-1]
+	}
+
+	return strings.ReplaceAll(quoted[1:len(quoted)-1], quote+quote, quote)
+}
+
+func isSQLiteWordChar(char byte) bool {
+	return char == '_' || char == '$' || char >= '0' && char <= '9' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= 0x80
+}
+
+// EstimateRowsCount returns number of rows that was computed by the last ANALYZE of the database.
+// Statistics are stored in sqlite_stat1 table, which does not exist until the database is analyzed.
+func (s *sqLiteClient) EstimateRowsCount(ctx context.Context, tableName string) (int64, error) {
+	logger := s.logger.Named("sqLiteClient.EstimateRowsCount")
+
+	table, err := ParseTableName(tableName)
+	if err != nil {
+		logger.Info(err.Error())
+		return 0, err
+	}
+	schemaName := sqLiteSchemaName(table.Schema)
+
+	exists, err := s.schemaExists(ctx, schemaName)
+	if err != nil {
+		logger.Error("check schema existence", "err", err)
+		return 0, fmt.Errorf("check schema existence: %w", err)
+	}
+	if !exists {
+		logger.Info(ErrTableDoesNotExist.Error(), "tableName", tableName)
+		return 0, fmt.Errorf("%w: %s", ErrTableDoesNotExist, tableName)
+	}
+
+	schema := sqLiteDialect{}.quoteIdentifier(schemaName)
+
+	var analyzed bool
+	err = s.db.GetContext(
+		ctx,
+		&analyzed,
+		fmt.Sprintf("SELECT count(*) > 0 FROM %s.sqlite_schema WHERE type = 'table' AND name = 'sqlite_stat1';", schema),
+	)
+	if err != nil {
+		logger.Error("check sqlite statistics existence", "err", err)
+		return 0, fmt.Errorf("check sqlite statistics existence: %w", err)
+	}
+	if !analyzed {
+		logger.Debug("sqlite database has no statistics", "schemaName", schemaName)
+		return 0, ErrRowsCountUnknown
+	}
+
+	// Every statistics row of the table starts with its number of rows.
+	var stats []string
+	err = s.db.SelectContext(ctx, &stats, fmt.Sprintf("SELECT stat FROM %s.sqlite_stat1 WHERE tbl = ? LIMIT 1;", schema), table.Name)
+	if err != nil {
+		logger.Error("select sqlite table statistics", "err", err)
+		return 0, fmt.Errorf("select sqlite table statistics: %w", err)
+	}
+	if len(stats) == 0 {
+		logger.Debug("sqlite table has no statistics", "tableName", tableName)
+		return 0, ErrRowsCountUnknown
+	}
+
+	rows, _, _ := strings.Cut(stats[0], " ")
+
+	estimate, err := strconv.ParseInt(rows, 10, 64)
+	if err != nil {
+		logger.Error("parse sqlite table statistics", "err", err, "stat", stats[0])
+		return 0, fmt.Errorf("parse sqlite table statistics: %w", err)
+	}
+
+	return estimate, nil
+}
+
+func (s *sqLiteClient) ExecuteQuery(ctx context.Context, query Query) ([]string, error) {
+	return executeQuery(ctx, s.logger.Named("sqLiteClient.ExecuteQuery"), s.db, query)
+}
+
+// ExecuteReadOnlyQuery disables writes for the connection of the query, because SQLite does not have read-only transactions.
+func (s *sqLiteClient) ExecuteReadOnlyQuery(ctx context.Context, options ReadOnlyQueryOptions) ([]string, error) {
+	logger := s.logger.Named("sqLiteClient.ExecuteReadOnlyQuery")
+
+	text, err := validateReadOnlyQuery(sqLiteDialect{}, options.Text)
+	if err != nil {
+		logger.Info(err.Error())
+		return nil, err
+	}
+
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		logger.Error("get connection", "err", err)
+		return nil, fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "PRAGMA query_only = ON;")
+	if err != nil {
+		logger.Error("disable writes", "err", err)
+		return nil, fmt.Errorf("disable writes: %w", err)
+	}
+	defer func() {
+		// Connection returns to the pool, so writes must be enabled even when the request is canceled.
+		_, err := conn.ExecContext(context.Background(), "PRAGMA query_only = OFF;")
+		if err != nil {
+			logger.Error("enable writes", "err", err)
+		}
+	}()
+
+	result, err := executeReadOnlyQuery(ctx, logger, conn, options, func(ctx context.Context, tx *sqlx.Tx) (Query, error) {
+		columns, err := listReadOnlyQueryColumns(ctx, tx, text)
+		if err != nil {
+			return Query{}, fmt.Errorf("list query columns: %w", err)
+		}
+
+		return wrapReadOnlyQuery(sqLiteDialect{}.fieldsToJSON(columns, nil), text, options.Limit), nil
+	})
+	if err != nil {
+		sqliteErr := &sqlite.Error{}
+		switch {
+		case errors.Is(err, ErrQueryTimeout):
+			logger.Info(err.Error())
+			return nil, err
+
+		case errors.As(err, &sqliteErr):
+			logger.Info(sqliteErr.Error())
+			return nil, sqLiteQueryError(err)
+		}
+
+		logger.Error("execute read-only query", "err", err)
+		return nil, fmt.Errorf("execute read-only query: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *sqLiteClient) StreamQuery(ctx context.Context, query Query, handleRow func(row []byte) error) error {
+	return streamQuery(ctx, s.logger.Named("sqLiteClient.StreamQuery"), s.db, query, handleRow)
+}
+
+// BeginSnapshot uses default isolation, because SQLite transactions are always serializable.
+func (s *sqLiteClient) BeginSnapshot(ctx context.Context) (Snapshot, error) {
+	logger := s.logger.Named("sqLiteClient.BeginSnapshot")
+
+	snapshot, err := beginSnapshot(ctx, s.logger.Named("sqLiteClient.snapshot"), s.db, sql.LevelDefault)
+	if err != nil {
+		logger.Error("begin snapshot", "err", err)
+		return nil, fmt.Errorf("begin snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+func (s *sqLiteClient) BeginImport(ctx context.Context, options ImportOptions) (Importer, error) {
+	logger := s.logger.Named("sqLiteClient.BeginImport")
+
+	description, err := s.DescribeTable(ctx, options.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	importer, err := beginImport(ctx, s.db, sqLiteDialect{}, description, options, sqLiteQueryError)
+	if err != nil {
+		if errs.IsExpected(err) {
+			logger.Info(err.Error())
+			return nil, err
+		}
+
+		logger.Error("begin import", "err", err)
+		return nil, fmt.Errorf("begin import: %w", err)
+	}
+
+	return importer, nil
+}
+
+// sqLiteQueryError converts an error reported by the database into *QueryError, nil is returned for other errors.
+func sqLiteQueryError(err error) *QueryError {
+	sqliteErr := &sqlite.Error{}
+	if !errors.As(err, &sqliteErr) {
+		return nil
+	}
+
+	return &QueryError{Message: sqliteErr.Error()}
+}
+
+func (s *sqLiteClient) BuildCreateTableQuery(options CreateTableOptions) (Query, error) {
+	logger := s.logger.Named("sqLiteClient.BuildCreateTableQuery")
+
+	query, err := buildCreateTableQuery(sqLiteDialect{}, options)
+	if err != nil {
+		logger.Info(err.Error())
+		return Query{}, err
+	}
+
+	return query, nil
+}
+
+func (s *sqLiteClient) CreateTable(ctx context.Context, options CreateTableOptions) error {
+	logger := s.logger.Named("sqLiteClient.CreateTable")
+
+	query, err := buildCreateTableQuery(sqLiteDialect{}, options)
+	if err != nil {
+		logger.Info(err.Error())
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, query.Text)
+	if err != nil {
+		if queryErr := sqLiteQueryError(err); queryErr != nil {
+			logger.Info(queryErr.Message)
+			return queryErr
+		}
+
+		logger.Error("create table", "err", err)
+		return fmt.Errorf("create table: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqLiteClient) Stats() DBStats {
+	return getDBStats(s.db)
+}
+
+func (s *sqLiteClient) Close() error {
+	return closeDB(s.logger.Named("sqLiteClient.Close"), s.db)
+}
+
+type sqLiteDialect struct{}
+
+var _ sqlDialect = (*sqLiteDialect)(nil)
+
+func (sqLiteDialect) quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (sqLiteDialect) quoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (sqLiteDialect) placeholder(_ int) string {
+	return "?"
+}
+
+func (d sqLiteDialect) rowToJSON(_ string, columns []string, arguments []string) string {
+	return d.fieldsToJSON(columns, arguments)
+}
+
+func (d sqLiteDialect) fieldsToJSON(fields []string, arguments []string) string {
+	return fmt.Sprintf("json_object(%s)", buildJSONObjectArguments(d, fields, arguments))
+}
+
+// nestedJSON marks the subquery result as JSON, otherwise it's embedded as a string.
+func (sqLiteDialect) nestedJSON(subquery string) string {
+	return fmt.Sprintf("json((%s))", subquery)
+}
+
+func (sqLiteDialect) aggregateJSON(value string) string {
+	return fmt.Sprintf("json_group_array(%s)", value)
+}
+
+// iLike uses plain LIKE, because it's already case-insensitive for ASCII characters in SQLite.
+func (sqLiteDialect) iLike(column, placeholder string) string {
+	return fmt.Sprintf("%s LIKE %s", column, placeholder)
+}
+
+// orderByAny returns the column as is, because SQLite orders values of all types.
+func (sqLiteDialect) orderByAny(column string) string {
+	return column
+}
+
+// nullsSortFirst returns true, because NULL values are smaller than any other value in SQLite.
+func (sqLiteDialect) nullsSortFirst() bool {
+	return true
+}
+
+func (sqLiteDialect) orderByNulls(column, direction string, nullsFirst bool) string {
+	if nullsFirst {
+		return fmt.Sprintf("%s %s NULLS FIRST", column, direction)
+	}
+
+	return fmt.Sprintf("%s %s NULLS LAST", column, direction)
+}
+
+// noLimit returns negative limit, because SQLite does not allow OFFSET without LIMIT.
+func (sqLiteDialect) noLimit() string {
+	return "-1"
+}
+
+// columnType returns names with the type affinity of the values, SQLite does not have dedicated types for most of them.
+func (sqLiteDialect) columnType(kind ValueKind, _ bool) (string, error) {
+	switch kind {
+	case ValueKindInteger:
+		return "INTEGER", nil
+	case ValueKindNumber:
+		return "REAL", nil
+	case ValueKindBoolean:
+		return "BOOLEAN", nil
+	case ValueKindTimestamp:
+		return "DATETIME", nil
+	case ValueKindDate:
+		return "DATE", nil
+	case ValueKindUUID, ValueKindText, ValueKindJSON:
+		return "TEXT", nil
+	default:
+		return "", fmt.Errorf("%w: unknown kind of values %q", ErrInvalidTableDefinition, kind)
+	}
+}
+
+func (d sqLiteDialect) upsert(conflictColumns, updateColumns []string) string {
+	return onConflict(d, conflictColumns, updateColumns)
+}
+
+func (sqLiteDialect) lexicon() queryLexicon {
+	return queryLexicon{bracketIdentifiers: true}
+}
+
+func handleSQLiteError(err error) error {
+	sqliteErr := &sqlite.Error{}
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_NOTADB, sqlite3.SQLITE_CORRUPT:
+			return ErrInvalidDatabaseFile
+
+		case sqlite3.SQLITE_CANTOPEN:
+			return ErrDatabaseDoesNotExists
+
+		case sqlite3.SQLITE_PERM, sqlite3.SQLITE_AUTH:
+			return ErrNoAccess
+
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// escapeSQLitePath escapes every segment of the path, so "?", "#" and "%" in file names are not parsed as parts of URI.
+func escapeSQLitePath(path string) string {
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,308 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/VladPetriv/d2j/pkg/logger"
+)
+
+// testSQLiteSchema contains tables with a foreign key, NULL values and an integer that does not fit into float64,
+// and a table without primary key with duplicated rows.
+const testSQLiteSchema = `
+CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+CREATE TABLE books (
+	id INTEGER PRIMARY KEY,
+	author_id INTEGER REFERENCES authors (id),
+	title TEXT NOT NULL,
+	price REAL
+);
+INSERT INTO authors (id, name) VALUES (1, 'Ann'), (2, 'Bob');
+INSERT INTO books (id, author_id, title, price) VALUES
+	(1, 1, 'Alpha', 10.5),
+	(2, 1, 'Beta', NULL),
+	(3, 2, 'Gamma', 7),
+	(4, 2, 'delta', 10.5),
+	(9007199254740993, NULL, 'Epsilon', 1);
+CREATE TABLE sales (title TEXT NOT NULL, quantity INTEGER NOT NULL);
+INSERT INTO sales (title, quantity) VALUES ('Beta', 1), ('Alpha', 2), ('Gamma', 2), ('Beta', 1), ('Alpha', 1);
+`
+
+// newTestSQLiteClient connects to a new SQLite database with testSQLiteSchema.
+func newTestSQLiteClient(t *testing.T) DBClient {
+	t.Helper()
+
+	directory := t.TempDir()
+	createTestSQLiteDatabase(t, filepath.Join(directory, "test.db"))
+
+	client, err := NewSQLite(logger.NewSlog("error"), directory).Connect(ConnectionOptions{Path: "test.db"})
+	if err != nil {
+		t.Fatalf("connect to sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	return client
+}
+
+// createTestSQLiteDatabase creates a database file with testSQLiteSchema.
+func createTestSQLiteDatabase(t *testing.T, path string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	_, err = db.Exec(testSQLiteSchema)
+	if err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatalf("close sqlite: %v", err)
+	}
+}
+
+// selectRows builds and executes the query, it returns the query and decoded rows.
+func selectRows(t *testing.T, client DBClient, options BuildQueryOptions) (Query, []map[string]json.RawMessage) {
+	t.Helper()
+
+	query, err := client.BuildQuery(options)
+	if err != nil {
+		t.Fatalf("BuildQuery() unexpected error: %v", err)
+	}
+
+	result, err := client.ExecuteQuery(context.Background(), query)
+	if err != nil {
+		t.Fatalf("ExecuteQuery() unexpected error: %v", err)
+	}
+
+	rows := make([]map[string]json.RawMessage, len(result))
+	for i, row := range result {
+		err = json.Unmarshal([]byte(row), &rows[i])
+		if err != nil {
+			t.Fatalf("unmarshal row %s: %v", row, err)
+		}
+	}
+
+	return query, rows
+}
+
+// rowIDs returns raw JSON values of id column, so large integers are compared without losing precision.
+func rowIDs(rows []map[string]json.RawMessage) []string {
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = string(row["id"])
+	}
+
+	return ids
+}
+
+func TestSQLiteBuildQuery(t *testing.T) {
+	t.Parallel()
+
+	client := newTestSQLiteClient(t)
+
+	testCases := []struct {
+		name       string
+		options    BuildQueryOptions
+		wantIDs    []string
+		wantFields []string
+	}{
+		{
+			name:       "all columns",
+			options:    BuildQueryOptions{TableName: "books"},
+			wantIDs:    []string{"1", "2", "3", "4", "9007199254740993"},
+			wantFields: []string{"author_id", "id", "price", "title"},
+		},
+		{
+			name:       "selected fields",
+			options:    BuildQueryOptions{TableName: "books", Fields: []string{"id", "title"}},
+			wantIDs:    []string{"1", "2", "3", "4", "9007199254740993"},
+			wantFields: []string{"id", "title"},
+		},
+		{
+			name:       "limit",
+			options:    BuildQueryOptions{TableName: "main.authors", Limit: 1},
+			wantIDs:    []string{"1"},
+			wantFields: []string{"id", "name"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, rows := selectRows(t, client, tc.options)
+			if ids := rowIDs(rows); !slices.Equal(ids, tc.wantIDs) {
+				t.Errorf("selected ids = %v, want %v", ids, tc.wantIDs)
+			}
+
+			for _, row := range rows {
+				fields := make([]string, 0, len(row))
+				for field := range row {
+					fields = append(fields, field)
+				}
+				slices.Sort(fields)
+
+				if !slices.Equal(fields, tc.wantFields) {
+					t.Fatalf("selected fields = %v, want %v", fields, tc.wantFields)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteBuildQueryUnknownColumn(t *testing.T) {
+	t.Parallel()
+
+	client := newTestSQLiteClient(t)
+
+	_, err := client.BuildQuery(BuildQueryOptions{TableName: "books", Fields: []string{"id", "missing"}})
+	if !errors.Is(err, ErrColumnDoesNotExist) {
+		t.Fatalf("BuildQuery() error = %v, want %v", err, ErrColumnDoesNotExist)
+	}
+}
+
+func TestSQLiteConnect(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	createTestSQLiteDatabase(t, filepath.Join(directory, "test.db"))
+
+	// Driver parses path of the test database as URI too, so the file is renamed after it's created.
+	err := os.Rename(filepath.Join(directory, "test.db"), filepath.Join(directory, "a?b#c%d.db"))
+	if err != nil {
+		t.Fatalf("rename file: %v", err)
+	}
+
+	err = os.WriteFile(filepath.Join(directory, "text.db"), []byte("not a database"), 0o600)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "URI characters in file name", path: "a?b#c%d.db"},
+		{name: "path outside of directory is joined with it", path: "../../a?b#c%d.db"},
+		{name: "missing file", path: "missing.db", wantErr: ErrDatabaseDoesNotExists},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := NewSQLite(logger.NewSlog("error"), directory).Connect(ConnectionOptions{Path: tc.path})
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("Connect() error = %v, want %v", err, tc.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Connect() unexpected error: %v", err)
+			}
+			defer client.Close()
+
+			tables, err := client.ListTables(context.Background(), "")
+			if err != nil {
+				t.Fatalf("ListTables() unexpected error: %v", err)
+			}
+			if len(tables) != 3 {
+				t.Errorf("listed tables = %+v, want authors, books and sales", tables)
+			}
+		})
+	}
+
+	_, err = NewSQLite(logger.NewSlog("error"), directory).Connect(ConnectionOptions{Path: "text.db"})
+	if err == nil {
+		t.Errorf("Connect() to file that is not a database succeeded")
+	}
+}
+
+func TestParseSQLiteCheckConstraints(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		definition string
+		want       []CheckConstraint
+	}{
+		{
+			name:       "no constraints",
+			definition: "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+			want:       nil,
+		},
+		{
+			name:       "column constraint",
+			definition: "CREATE TABLE users (age INT CHECK (age >= 0))",
+			want:       []CheckConstraint{{Expression: "age >= 0"}},
+		},
+		{
+			name:       "named column constraint",
+			definition: "CREATE TABLE users (name TEXT CONSTRAINT name_length CHECK (length(name) <= 50))",
+			want:       []CheckConstraint{{Name: "name_length", Expression: "length(name) <= 50"}},
+		},
+		{
+			name:       "named table constraint with quoted name",
+			definition: `CREATE TABLE users (qty INT, price REAL, CONSTRAINT "positive ""total""" CHECK (qty > 0 OR price > 5))`,
+			want:       []CheckConstraint{{Name: `positive "total"`, Expression: "qty > 0 OR price > 5"}},
+		},
+		{
+			name:       "parentheses and quotes inside of expression",
+			definition: "CREATE TABLE t (status TEXT CHECK (status IN ('new', 'it''s done)')), qty INT CHECK (qty BETWEEN 0 AND 10))",
+			want: []CheckConstraint{
+				{Expression: "status IN ('new', 'it''s done)')"},
+				{Expression: "qty BETWEEN 0 AND 10"},
+			},
+		},
+		{
+			name:       "lowercase keyword",
+			definition: "create table t (a int check(a <> 1))",
+			want:       []CheckConstraint{{Expression: "a <> 1"}},
+		},
+		{
+			name: "keywords in comments and identifiers are ignored",
+			definition: "CREATE TABLE t (\n" +
+				"\t-- CHECK (a > 1)\n" +
+				"\t\"check\" INT, /* CHECK (b > 1) */\n" +
+				"\t[constraint] INT CHECK ([constraint] > 0)\n" +
+				")",
+			want: []CheckConstraint{{Expression: "[constraint] > 0"}},
+		},
+		{
+			name:       "constraint name is not taken from previous column",
+			definition: "CREATE TABLE t (a INT CONSTRAINT a_not_null NOT NULL CHECK (a > 0))",
+			want:       []CheckConstraint{{Expression: "a > 0"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := parseSQLiteCheckConstraints(tc.definition)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("parseSQLiteCheckConstraints() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
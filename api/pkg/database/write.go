@@ -0,0 +1,281 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ImportOptions represents an options that used for importing rows into table.
+type ImportOptions struct {
+	// TableName is a table name in the format of ParseTableName.
+	TableName string
+	// Upsert updates existing rows that conflict with imported ones instead of failing.
+	Upsert bool
+	// ConflictColumns are columns of primary key or unique index, by which conflicting rows are found.
+	// Primary key is used when they are empty. MySQL finds conflicts by all unique keys, so there they are only validated.
+	ConflictColumns []string
+}
+
+// Importer writes rows into the table inside of a transaction, which must be committed or rolled back by the caller.
+type Importer interface {
+	// Columns returns names of the table columns.
+	Columns() []string
+	// InsertRows inserts rows, which contain JSON values of the columns, by a single statement.
+	// Nothing is inserted when the statement fails, but the transaction could be used further.
+	// Errors caused by the rows, e.g. constraint violations, are returned as *QueryError.
+	InsertRows(ctx context.Context, columns []string, rows [][]json.RawMessage) error
+	Commit() error
+	Rollback() error
+}
+
+// maxInsertParameters is a maximum number of bind parameters in a single statement, which is supported by all databases.
+const maxInsertParameters = 32766
+
+type importer struct {
+	tx      *sqlx.Tx
+	dialect sqlDialect
+	table   TableName
+	columns []string
+	upsert  bool
+	// conflictColumns are not updated by upsert, because conflicting rows already have the same values of them.
+	conflictColumns []string
+	// queryError converts an error of the database into *QueryError, it returns nil for errors not caused by the rows.
+	queryError func(err error) *QueryError
+}
+
+var _ Importer = (*importer)(nil)
+
+// beginImport validates the import options against the table description and starts a transaction.
+func beginImport(
+	ctx context.Context, db txBeginner, dialect sqlDialect, description *TableDescription, options ImportOptions,
+	queryError func(err error) *QueryError,
+) (*importer, error) {
+	columns := make([]string, len(description.Columns))
+	for i, column := range description.Columns {
+		columns[i] = column.Name
+	}
+
+	conflictColumns, err := resolveConflictColumns(description, options)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+
+	return &importer{
+		tx:              tx,
+		dialect:         dialect,
+		table:           TableName{Schema: description.SchemaName, Name: description.TableName},
+		columns:         columns,
+		upsert:          options.Upsert,
+		conflictColumns: conflictColumns,
+		queryError:      queryError,
+	}, nil
+}
+
+// resolveConflictColumns returns columns by which conflicting rows are found, they must match primary key or unique index.
+func resolveConflictColumns(description *TableDescription, options ImportOptions) ([]string, error) {
+	if !options.Upsert {
+		if len(options.ConflictColumns) != 0 {
+			return nil, fmt.Errorf("%w: conflict columns could be used only with upsert", ErrInvalidConflictColumns)
+		}
+
+		return nil, nil
+	}
+
+	if len(options.ConflictColumns) == 0 {
+		if len(description.PrimaryKey) == 0 {
+			return nil, fmt.Errorf("%w: table has no primary key", ErrInvalidConflictColumns)
+		}
+
+		return description.PrimaryKey, nil
+	}
+
+	keys := [][]string{description.PrimaryKey}
+	for _, index := range description.Indexes {
+		if index.Unique {
+			keys = append(keys, index.Columns)
+		}
+	}
+
+	for _, key := range keys {
+		if sameColumns(key, options.ConflictColumns) {
+			return options.ConflictColumns, nil
+		}
+	}
+
+	return nil, fmt.Errorf(
+		"%w: %s do not match primary key or unique index", ErrInvalidConflictColumns, strings.Join(options.ConflictColumns, ", "),
+	)
+}
+
+func (i *importer) Columns() []string {
+	return i.columns
+}
+
+func (i *importer) InsertRows(ctx context.Context, columns []string, rows [][]json.RawMessage) error {
+	if len(columns) == 0 || len(rows) == 0 {
+		return fmt.Errorf("%w: rows and columns are required", ErrInvalidImport)
+	}
+
+	for _, column := range columns {
+		if !slices.Contains(i.columns, column) {
+			return fmt.Errorf("%w: %s", ErrColumnDoesNotExist, column)
+		}
+	}
+
+	// Savepoint keeps the transaction usable after the failed statement, which is required by PostgreSQL.
+	_, err := i.tx.ExecContext(ctx, "SAVEPOINT d2j_import;")
+	if err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	err = i.insertRows(ctx, columns, rows)
+	if err != nil {
+		_, rollbackErr := i.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT d2j_import;")
+		if rollbackErr != nil {
+			return fmt.Errorf("rollback to savepoint: %w", rollbackErr)
+		}
+
+		if queryErr := i.queryError(err); queryErr != nil {
+			return queryErr
+		}
+
+		return err
+	}
+
+	_, err = i.tx.ExecContext(ctx, "RELEASE SAVEPOINT d2j_import;")
+	if err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// insertRows inserts rows by as few statements as allowed by the limit of bind parameters.
+func (i *importer) insertRows(ctx context.Context, columns []string, rows [][]json.RawMessage) error {
+	batchSize := max(maxInsertParameters/len(columns), 1)
+
+	for start := 0; start < len(rows); start += batchSize {
+		query, err := i.buildInsertQuery(columns, rows[start:min(start+batchSize, len(rows))])
+		if err != nil {
+			return err
+		}
+
+		_, err = i.tx.ExecContext(ctx, query.Text, query.Args...)
+		if err != nil {
+			return fmt.Errorf("insert rows: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// buildInsertQuery builds a multi-row INSERT statement, which updates conflicting rows in upsert mode.
+func (i *importer) buildInsertQuery(columns []string, rows [][]json.RawMessage) (Query, error) {
+	builder := newQueryBuilder(i.dialect, nil)
+
+	quoted := make([]string, len(columns))
+	for j, column := range columns {
+		quoted[j] = i.dialect.quoteIdentifier(column)
+	}
+
+	values := make([]string, len(rows))
+	for j, row := range rows {
+		if len(row) != len(columns) {
+			return Query{}, fmt.Errorf("%w: row has %d values instead of %d", ErrInvalidImport, len(row), len(columns))
+		}
+
+		placeholders := make([]string, len(row))
+		for k, raw := range row {
+			value, err := jsonBindValue(raw)
+			if err != nil {
+				return Query{}, fmt.Errorf("%w: value of %s: %w", ErrInvalidImport, columns[k], err)
+			}
+
+			placeholders[k] = builder.bind(value)
+		}
+
+		values[j] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s",
+		quoteTableName(i.dialect, i.table), strings.Join(quoted, ", "), strings.Join(values, ", "),
+	)
+
+	if i.upsert {
+		var updateColumns []string
+		for _, column := range columns {
+			if !slices.Contains(i.conflictColumns, column) {
+				updateColumns = append(updateColumns, column)
+			}
+		}
+
+		query += " " + i.dialect.upsert(i.conflictColumns, updateColumns)
+	}
+
+	return Query{Text: query, Args: builder.args}, nil
+}
+
+func (i *importer) Commit() error {
+	err := i.tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (i *importer) Rollback() error {
+	err := i.tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("rollback transaction: %w", err)
+	}
+
+	return nil
+}
+
+// onConflict returns ON CONFLICT clause, which is supported by PostgreSQL and SQLite.
+func onConflict(dialect sqlDialect, conflictColumns, updateColumns []string) string {
+	quoted := make([]string, len(conflictColumns))
+	for i, column := range conflictColumns {
+		quoted[i] = dialect.quoteIdentifier(column)
+	}
+
+	clause := fmt.Sprintf("ON CONFLICT (%s) DO ", strings.Join(quoted, ", "))
+	if len(updateColumns) == 0 {
+		return clause + "NOTHING"
+	}
+
+	assignments := make([]string, len(updateColumns))
+	for i, name := range updateColumns {
+		column := dialect.quoteIdentifier(name)
+		assignments[i] = fmt.Sprintf("%s = excluded.%s", column, column)
+	}
+
+	return clause + "UPDATE SET " + strings.Join(assignments, ", ")
+}
+
+// sameColumns reports whether both lists contain the same columns regardless of their order.
+func sameColumns(a, b []string) bool {
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+
+	for _, column := range b {
+		if !slices.Contains(a, column) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,331 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/VladPetriv/d2j/pkg/database"
+	"github.com/VladPetriv/d2j/pkg/errs"
+)
+
+// CodegenOptions represents an options that used for generating types of table rows.
+type CodegenOptions struct {
+	// Columns defines selected columns and order of the fields.
+	Columns []string
+	// TypeName is a name of generated type, it's derived from the table name when empty.
+	TypeName string
+	// PackageName is a name of Go package, it's not used by TypeScript.
+	PackageName string
+}
+
+// ErrInvalidIdentifier - type or package name could not be used as an identifier in the target language.
+var ErrInvalidIdentifier = errs.New("invalid identifier")
+
+// goInitialisms are words that are written in upper case in Go identifiers.
+var goInitialisms = map[string]struct{}{
+	"api": {}, "css": {}, "db": {}, "html": {}, "http": {}, "https": {}, "id": {}, "ip": {}, "json": {},
+	"sql": {}, "ui": {}, "uri": {}, "url": {}, "uuid": {}, "xml": {},
+}
+
+var typeScriptIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// RenderGoStruct renders Go struct with json and db tags, which could be used to decode rows selected from the table.
+// Nullable columns are represented by pointers, except for JSON values and arrays, which could be null themselves.
+func RenderGoStruct(w io.Writer, description *database.TableDescription, options CodegenOptions) error {
+	typeName := options.TypeName
+	if typeName == "" {
+		typeName = goIdentifier(description.TableName)
+	}
+	if !token.IsIdentifier(typeName) {
+		return fmt.Errorf("%w: type name %q", ErrInvalidIdentifier, typeName)
+	}
+
+	packageName := options.PackageName
+	if packageName == "" {
+		packageName = "models"
+	}
+	if !token.IsIdentifier(packageName) {
+		return fmt.Errorf("%w: package name %q", ErrInvalidIdentifier, packageName)
+	}
+
+	columns := columnsByName(description)
+	imports := make(map[string]struct{})
+	fieldNames := make(map[string]int)
+
+	var fields strings.Builder
+	for _, name := range options.Columns {
+		column := columns[name]
+
+		fieldType, fieldImports := goFieldType(description.Dialect, column)
+		for _, fieldImport := range fieldImports {
+			imports[fieldImport] = struct{}{}
+		}
+
+		fieldName := goIdentifier(name)
+		fieldNames[fieldName]++
+		if count := fieldNames[fieldName]; count > 1 {
+			fieldName += strconv.Itoa(count)
+		}
+
+		if column.Comment != nil && *column.Comment != "" {
+			for _, line := range strings.Split(*column.Comment, "\n") {
+				fmt.Fprintf(&fields, "\t// %s\n", strings.TrimSpace(line))
+			}
+		}
+		fmt.Fprintf(&fields, "\t%s %s `json:%s db:%s`\n", fieldName, fieldType, strconv.Quote(name), strconv.Quote(name))
+	}
+
+	var source strings.Builder
+	fmt.Fprintf(&source, "package %s\n\n", packageName)
+
+	if len(imports) != 0 {
+		source.WriteString("import (\n")
+		for _, importPath := range []string{"encoding/json", "time"} {
+			if _, ok := imports[importPath]; ok {
+				fmt.Fprintf(&source, "\t%q\n", importPath)
+			}
+		}
+		source.WriteString(")\n\n")
+	}
+
+	fmt.Fprintf(&source, "// %s represents a row of %s table.\n", typeName, qualifiedTableName(description))
+	fmt.Fprintf(&source, "type %s struct {\n%s}\n", typeName, fields.String())
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return fmt.Errorf("format go source: %w", err)
+	}
+
+	_, err = w.Write(formatted)
+	return err
+}
+
+// goFieldType returns Go type of the column values and packages that must be imported for it.
+func goFieldType(dialect database.Dialect, column database.Column) (string, []string) {
+	valueType := columnValueType(dialect, column)
+
+	if len(valueType.jsonTypes) != 1 {
+		return "json.RawMessage", []string{"encoding/json"}
+	}
+
+	var fieldType string
+	var imports []string
+
+	switch valueType.jsonTypes[0] {
+	case jsonTypeInteger:
+		fieldType = goIntegerType(dialect, valueType.baseType)
+	case jsonTypeNumber:
+		fieldType = goNumberType(dialect, valueType.baseType)
+		if fieldType == "json.Number" {
+			imports = append(imports, "encoding/json")
+		}
+	case jsonTypeBoolean:
+		fieldType = "bool"
+	case jsonTypeString:
+		fieldType = "string"
+		if valueType.format == "date-time" {
+			fieldType = "time.Time"
+			imports = append(imports, "time")
+		}
+	}
+
+	if valueType.isArray {
+		return "[]" + fieldType, imports
+	}
+	if column.Nullable {
+		return "*" + fieldType, imports
+	}
+
+	return fieldType, imports
+}
+
+// goIntegerType returns Go integer type that fits values of the database integer type.
+func goIntegerType(dialect database.Dialect, baseType string) string {
+	switch dialect {
+	case database.DialectPostgreSQL:
+		switch baseType {
+		case "smallint":
+			return "int16"
+		case "integer":
+			return "int32"
+		}
+
+	case database.DialectMySQL:
+		fields := strings.Fields(baseType)
+
+		var integerType string
+		switch fields[0] {
+		case "tinyint":
+			integerType = "int8"
+		case "smallint", "year":
+			integerType = "int16"
+		case "mediumint", "int", "integer":
+			integerType = "int32"
+		default:
+			integerType = "int64"
+		}
+
+		for _, field := range fields[1:] {
+			if field == "unsigned" {
+				return "u" + integerType
+			}
+		}
+
+		return integerType
+	}
+
+	return "int64"
+}
+
+// goNumberType returns Go type of the database floating point or decimal type.
+// Decimals are represented by json.Number to keep their precision.
+func goNumberType(dialect database.Dialect, baseType string) string {
+	switch {
+	case strings.HasPrefix(baseType, "numeric") || strings.HasPrefix(baseType, "decimal"):
+		return "json.Number"
+	case dialect == database.DialectPostgreSQL && baseType == "real",
+		dialect == database.DialectMySQL && strings.HasPrefix(baseType, "float"):
+		return "float32"
+	default:
+		return "float64"
+	}
+}
+
+// goIdentifier converts the name into exported Go identifier, e.g. "user_id" into "UserID".
+func goIdentifier(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var identifier strings.Builder
+	for _, word := range words {
+		if _, ok := goInitialisms[strings.ToLower(word)]; ok {
+			identifier.WriteString(strings.ToUpper(word))
+			continue
+		}
+
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		identifier.WriteString(string(runes))
+	}
+
+	result := identifier.String()
+	if result == "" || !unicode.IsLetter([]rune(result)[0]) || !token.IsExported(result) {
+		result = "Field" + result
+	}
+
+	return result
+}
+
+// RenderTypeScriptInterface renders TypeScript interface of rows selected from the table.
+// Nullable columns are represented by union with null.
+func RenderTypeScriptInterface(w io.Writer, description *database.TableDescription, options CodegenOptions) error {
+	typeName := options.TypeName
+	if typeName == "" {
+		typeName = goIdentifier(description.TableName)
+	}
+	if !typeScriptIdentifierRegexp.MatchString(typeName) {
+		return fmt.Errorf("%w: type name %q", ErrInvalidIdentifier, typeName)
+	}
+
+	columns := columnsByName(description)
+
+	var source strings.Builder
+	fmt.Fprintf(&source, "/** Row of %s table. */\n", typeScriptComment(qualifiedTableName(description)))
+	fmt.Fprintf(&source, "export interface %s {\n", typeName)
+
+	for _, name := range options.Columns {
+		column := columns[name]
+
+		if column.Comment != nil && *column.Comment != "" {
+			fmt.Fprintf(&source, "  /** %s */\n", typeScriptComment(*column.Comment))
+		}
+
+		propertyName := name
+		if !typeScriptIdentifierRegexp.MatchString(name) {
+			propertyName = jsonString(name)
+		}
+
+		fmt.Fprintf(&source, "  %s: %s;\n", propertyName, typeScriptPropertyType(description.Dialect, column))
+	}
+
+	source.WriteString("}\n")
+
+	_, err := io.WriteString(w, source.String())
+	return err
+}
+
+// typeScriptPropertyType returns TypeScript type of the column values.
+func typeScriptPropertyType(dialect database.Dialect, column database.Column) string {
+	valueType := columnValueType(dialect, column)
+
+	var types []string
+
+	if len(column.EnumValues) != 0 {
+		for _, value := range column.EnumValues {
+			types = append(types, jsonString(value))
+		}
+	} else {
+		for _, jsonType := range valueType.jsonTypes {
+			switch jsonType {
+			case jsonTypeInteger, jsonTypeNumber:
+				types = append(types, "number")
+			default:
+				types = append(types, jsonType)
+			}
+		}
+	}
+
+	propertyType := strings.Join(types, " | ")
+	if len(types) == 0 {
+		propertyType = "unknown"
+	}
+
+	if valueType.isArray {
+		// Elements of PostgreSQL arrays could always be NULL.
+		propertyType = "(" + propertyType + " | null)[]"
+	}
+	if column.Nullable && propertyType != "unknown" {
+		propertyType += " | null"
+	}
+
+	return propertyType
+}
+
+// typeScriptComment escapes the text to be used inside of a block comment.
+func typeScriptComment(text string) string {
+	text = strings.ReplaceAll(text, "*/", "*\\/")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// jsonString returns the value as JSON string literal, which is also a valid TypeScript string literal.
+func jsonString(value string) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// columnsByName returns table columns by their names.
+func columnsByName(description *database.TableDescription) map[string]database.Column {
+	columns := make(map[string]database.Column, len(description.Columns))
+	for _, column := range description.Columns {
+		columns[column.Name] = column
+	}
+
+	return columns
+}
+
+// qualifiedTableName returns table name with its schema.
+func qualifiedTableName(description *database.TableDescription) string {
+	if description.SchemaName == "" {
+		return description.TableName
+	}
+
+	return description.SchemaName + "." + description.TableName
+}
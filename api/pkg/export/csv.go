@@ -0,0 +1,168 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// QuoteMode represents a rule of quoting CSV fields.
+type QuoteMode string
+
+const (
+	// QuoteMinimal - only fields with special characters and empty strings are quoted.
+	QuoteMinimal QuoteMode = "minimal"
+	// QuoteAll - all fields except NULLs are quoted.
+	QuoteAll QuoteMode = "all"
+	// QuoteNone - fields are never quoted, special characters are escaped with backslash instead.
+	QuoteNone QuoteMode = "none"
+)
+
+// CSVOptions represents an options that used for writing CSV.
+type CSVOptions struct {
+	// Columns defines header and order of the values in every record.
+	Columns   []string
+	Delimiter rune
+	QuoteMode QuoteMode
+	// NullValue is written instead of NULL values and missing columns.
+	NullValue string
+}
+
+// CSVWriter writes rows, which are JSON objects, as CSV records.
+// Arrays and nested objects are written as compact JSON.
+type CSVWriter struct {
+	w       io.Writer
+	options CSVOptions
+
+	record bytes.Buffer
+}
+
+// NewCSVWriter is used to create an instance of CSV writer.
+func NewCSVWriter(w io.Writer, options CSVOptions) *CSVWriter {
+	if options.QuoteMode == "" {
+		options.QuoteMode = QuoteMinimal
+	}
+
+	return &CSVWriter{
+		w:       w,
+		options: options,
+	}
+}
+
+// WriteHeader writes the record with column names.
+func (c *CSVWriter) WriteHeader() error {
+	c.record.Reset()
+
+	for i, column := range c.options.Columns {
+		c.writeField(i, column)
+	}
+
+	return c.flushRecord()
+}
+
+// WriteRow writes a single row, which is a JSON object, as CSV record.
+func (c *CSVWriter) WriteRow(row []byte) error {
+	values, err := decodeRow(row)
+	if err != nil {
+		return err
+	}
+
+	c.record.Reset()
+
+	for i, column := range c.options.Columns {
+		value := values[column]
+
+		if isNull(value) {
+			if i != 0 {
+				c.record.WriteRune(c.options.Delimiter)
+			}
+			c.record.WriteString(c.options.NullValue)
+
+			continue
+		}
+
+		text, err := valueToText(value)
+		if err != nil {
+			return fmt.Errorf("convert column %s value to text: %w", column, err)
+		}
+
+		c.writeField(i, text)
+	}
+
+	return c.flushRecord()
+}
+
+func (c *CSVWriter) writeField(position int, field string) {
+	if position != 0 {
+		c.record.WriteRune(c.options.Delimiter)
+	}
+
+	switch c.options.QuoteMode {
+	case QuoteNone:
+		c.writeEscapedField(field)
+
+	case QuoteAll:
+		c.writeQuotedField(field)
+
+	default:
+		if c.fieldNeedsQuotes(field) {
+			c.writeQuotedField(field)
+		} else {
+			c.record.WriteString(field)
+		}
+	}
+}
+
+// fieldNeedsQuotes reports whether field must be quoted in minimal quote mode.
+// Empty string is quoted when it could be confused with NULL.
+func (c *CSVWriter) fieldNeedsQuotes(field string) bool {
+	if field == "" {
+		return c.options.NullValue == ""
+	}
+	if field == c.options.NullValue {
+		return true
+	}
+
+	return strings.ContainsRune(field, c.options.Delimiter) ||
+		strings.ContainsAny(field, "\"\r\n") ||
+		field[0] == ' ' || field[0] == '\t'
+}
+
+func (c *CSVWriter) writeQuotedField(field string) {
+	c.record.WriteByte('"')
+	c.record.WriteString(strings.ReplaceAll(field, `"`, `""`))
+	c.record.WriteByte('"')
+}
+
+// writeEscapedField writes field with escaped backslash, delimiter and line breaks, like PostgreSQL text format does.
+func (c *CSVWriter) writeEscapedField(field string) {
+	for _, char := range field {
+		switch char {
+		case '\\':
+			c.record.WriteString(`\\`)
+		case '\n':
+			c.record.WriteString(`\n`)
+		case '\r':
+			c.record.WriteString(`\r`)
+		case '\t':
+			c.record.WriteString(`\t`)
+		case c.options.Delimiter:
+			c.record.WriteByte('\\')
+			c.record.WriteRune(char)
+		default:
+			c.record.WriteRune(char)
+		}
+	}
+}
+
+func (c *CSVWriter) flushRecord() error {
+	c.record.WriteByte('\n')
+
+	_, err := c.w.Write(c.record.Bytes())
+	if err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/VladPetriv/d2j/pkg/errs"
+)
+
+// DocumentOptions represents an options that used for rendering rows as a single document.
+type DocumentOptions struct {
+	// Columns defines order of the keys in every row.
+	Columns []string
+	// KeyColumn turns rows into a map by the value of this column, which is removed from the row.
+	// Rows are rendered as a list when it's empty.
+	KeyColumn string
+	// RootKey is a key of the rows list in formats that do not support top-level lists, e.g. TOML.
+	RootKey string
+}
+
+var (
+	// ErrDuplicateKey - rows could not be keyed by the column, because its values are not unique.
+	ErrDuplicateKey = errs.New("duplicate key")
+	// ErrInvalidKey - value of the key column is NULL, array or object.
+	ErrInvalidKey = errs.New("invalid key")
+	// ErrUnsupportedValue - value could not be represented in the output format.
+	ErrUnsupportedValue = errs.New("unsupported value")
+)
+
+// object represents a JSON object, which keeps the order of its keys.
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// keyedRow represents a row with its key in keyed mode.
+type keyedRow struct {
+	key string
+	row *object
+}
+
+// document represents rows prepared for rendering, only one of rows and keyedRows is set.
+type document struct {
+	rows      []*object
+	keyedRows []keyedRow
+	keyed     bool
+}
+
+// buildDocument parses rows, which are JSON objects, and orders their keys by columns.
+func buildDocument(rows [][]byte, options DocumentOptions) (*document, error) {
+	doc := &document{keyed: options.KeyColumn != ""}
+	keys := make(map[string]struct{})
+
+	for _, data := range rows {
+		row, err := parseRow(data, options.Columns)
+		if err != nil {
+			return nil, err
+		}
+
+		if !doc.keyed {
+			doc.rows = append(doc.rows, row)
+			continue
+		}
+
+		key, err := scalarToText(row.values[options.KeyColumn])
+		if err != nil {
+			return nil, fmt.Errorf("%w: column %s", err, options.KeyColumn)
+		}
+
+		if _, ok := keys[key]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateKey, key)
+		}
+		keys[key] = struct{}{}
+
+		row.remove(options.KeyColumn)
+		doc.keyedRows = append(doc.keyedRows, keyedRow{key: key, row: row})
+	}
+
+	return doc, nil
+}
+
+// parseRow parses a row and orders its keys by columns. Keys that are not in columns are kept at the end.
+func parseRow(data []byte, columns []string) (*object, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	value, err := parseValue(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("parse row: %w", err)
+	}
+
+	row, ok := value.(*object)
+	if !ok {
+		return nil, errors.New("parse row: row is not a JSON object")
+	}
+
+	ordered := &object{values: row.values}
+	for _, column := range columns {
+		if _, ok := row.values[column]; ok {
+			ordered.keys = append(ordered.keys, column)
+		}
+	}
+	for _, key := range row.keys {
+		if _, ok := ordered.find(key); !ok {
+			ordered.keys = append(ordered.keys, key)
+		}
+	}
+
+	return ordered, nil
+}
+
+// parseValue parses the next JSON value from the decoder.
+// Objects are returned as *object, numbers as json.Number and arrays as []interface{}.
+func parseValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delimiter, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delimiter {
+	case '{':
+		obj := &object{values: make(map[string]interface{})}
+
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyToken.(string)
+
+			value, err := parseValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := obj.values[key]; !ok {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+
+		_, err = decoder.Token()
+		return obj, err
+
+	case '[':
+		array := make([]interface{}, 0)
+
+		for decoder.More() {
+			value, err := parseValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			array = append(array, value)
+		}
+
+		_, err = decoder.Token()
+		return array, err
+
+	default:
+		return nil, fmt.Errorf("unexpected delimiter %s", delimiter)
+	}
+}
+
+func (o *object) find(key string) (int, bool) {
+	for i, k := range o.keys {
+		if k == key {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (o *object) remove(key string) {
+	if i, ok := o.find(key); ok {
+		o.keys = append(o.keys[:i], o.keys[i+1:]...)
+		delete(o.values, key)
+	}
+}
+
+// set sets the value of the key, new keys are added to the end.
+func (o *object) set(key string, value interface{}) {
+	if o.values == nil {
+		o.values = make(map[string]interface{})
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+
+	o.values[key] = value
+}
+
+// MarshalJSON encodes the object with keys in their order.
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, key := range o.keys {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		encodedValue, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedValue)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// scalarToText converts string, number or boolean into text, which could be used as a map key.
+func scalarToText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", ErrInvalidKey
+	}
+}
+
+// writeString writes string to the writer and wraps an error.
+func writeString(w io.Writer, s string) error {
+	_, err := io.WriteString(w, s)
+	if err != nil {
+		return fmt.Errorf("write document: %w", err)
+	}
+
+	return nil
+}
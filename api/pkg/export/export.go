@@ -0,0 +1,53 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// decodeRow decodes a row, which is a JSON object, into raw JSON values by column names.
+func decodeRow(row []byte) (map[string]json.RawMessage, error) {
+	var values map[string]json.RawMessage
+
+	err := json.Unmarshal(row, &values)
+	if err != nil {
+		return nil, fmt.Errorf("decode row: %w", err)
+	}
+
+	return values, nil
+}
+
+// isNull reports whether raw JSON value is null or missing.
+func isNull(value json.RawMessage) bool {
+	return len(value) == 0 || bytes.Equal(value, []byte("null"))
+}
+
+// valueToText converts raw JSON value into its text representation.
+// Strings are unquoted, numbers and booleans are kept as is and arrays and objects are compacted.
+func valueToText(value json.RawMessage) (string, error) {
+	switch value[0] {
+	case '"':
+		var text string
+
+		err := json.Unmarshal(value, &text)
+		if err != nil {
+			return "", fmt.Errorf("decode string value: %w", err)
+		}
+
+		return text, nil
+
+	case '[', '{':
+		var compacted bytes.Buffer
+
+		err := json.Compact(&compacted, value)
+		if err != nil {
+			return "", fmt.Errorf("compact JSON value: %w", err)
+		}
+
+		return compacted.String(), nil
+
+	default:
+		return string(value), nil
+	}
+}
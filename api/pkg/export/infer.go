@@ -0,0 +1,234 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/VladPetriv/d2j/pkg/database"
+	"github.com/VladPetriv/d2j/pkg/errs"
+)
+
+// ErrInvalidSample happens when sample row is not a JSON object.
+var ErrInvalidSample = errs.New("invalid sample")
+
+// inferredPrimaryKey is a name of the column, which becomes primary key when its values are unique and not null.
+const inferredPrimaryKey = "id"
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// InferredTable represents a table structure inferred from JSON objects.
+type InferredTable struct {
+	Columns    []database.NewColumn `json:"columns"`
+	PrimaryKey []string             `json:"primaryKey"`
+}
+
+// inferredColumn collects kinds of the column values from all sample rows.
+type inferredColumn struct {
+	column database.NewColumn
+	// rowsCount is a number of rows that contain not null value of the column.
+	rowsCount int
+	values    map[string]struct{}
+}
+
+// InferTable infers columns of a table from sample rows, which are JSON objects.
+// Columns are ordered by the first appearance of their keys. Column is nullable when some row has no value of it.
+// Column "id" becomes primary key when all rows have unique values of it.
+func InferTable(rows []json.RawMessage) (*InferredTable, error) {
+	var (
+		names   []string
+		columns = make(map[string]*inferredColumn)
+	)
+
+	for i, row := range rows {
+		fields, err := parseOrderedObject(row)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: %w", ErrInvalidSample, i+1, err)
+		}
+
+		for _, field := range fields {
+			column, ok := columns[field.key]
+			if !ok {
+				column = &inferredColumn{
+					column: database.NewColumn{Name: field.key},
+					values: make(map[string]struct{}),
+				}
+				columns[field.key] = column
+				names = append(names, field.key)
+			}
+
+			kind := inferValueKind(field.value)
+			if kind == "" {
+				continue
+			}
+
+			column.column.Kind = mergeValueKinds(column.column.Kind, kind)
+			column.rowsCount++
+			column.values[string(field.value)] = struct{}{}
+		}
+	}
+
+	table := &InferredTable{
+		Columns:    make([]database.NewColumn, len(names)),
+		PrimaryKey: make([]string, 0, 1),
+	}
+
+	for i, name := range names {
+		column := columns[name]
+
+		// Rows that do not contain the column or contain null have NULL value of it.
+		if column.rowsCount != len(rows) {
+			column.column.Nullable = true
+		}
+		// Type of the column could not be inferred from NULL values only.
+		if column.column.Kind == "" {
+			column.column.Kind = database.ValueKindText
+		}
+
+		table.Columns[i] = column.column
+	}
+
+	if key, ok := columns[inferredPrimaryKey]; ok && isInferredPrimaryKey(key, len(rows)) {
+		table.PrimaryKey = append(table.PrimaryKey, inferredPrimaryKey)
+	}
+
+	return table, nil
+}
+
+// isInferredPrimaryKey reports whether the column has unique scalar values in all rows.
+func isInferredPrimaryKey(column *inferredColumn, rowsCount int) bool {
+	switch column.column.Kind {
+	case database.ValueKindInteger, database.ValueKindUUID, database.ValueKindText:
+	default:
+		return false
+	}
+
+	return !column.column.Nullable && len(column.values) == rowsCount
+}
+
+// inferValueKind returns kind of JSON value, it's empty for null.
+func inferValueKind(raw json.RawMessage) database.ValueKind {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return database.ValueKindJSON
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		return database.ValueKindBoolean
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return database.ValueKindInteger
+		}
+
+		return database.ValueKindNumber
+	case string:
+		return inferStringKind(v)
+	default:
+		return database.ValueKindJSON
+	}
+}
+
+// inferStringKind recognizes dates, timestamps and UUIDs in strings.
+func inferStringKind(value string) database.ValueKind {
+	if uuidRegexp.MatchString(value) {
+		return database.ValueKindUUID
+	}
+
+	if _, err := time.Parse(time.DateOnly, value); err == nil {
+		return database.ValueKindDate
+	}
+
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return database.ValueKindTimestamp
+		}
+	}
+
+	return database.ValueKindText
+}
+
+// mergeValueKinds returns kind of the column, which could store values of both kinds.
+func mergeValueKinds(a, b database.ValueKind) database.ValueKind {
+	switch {
+	case a == "" || a == b:
+		return b
+	case isKindsPair(a, b, database.ValueKindInteger, database.ValueKindNumber):
+		return database.ValueKindNumber
+	case isKindsPair(a, b, database.ValueKindDate, database.ValueKindTimestamp):
+		return database.ValueKindTimestamp
+	// Any value could be stored as JSON, but other values could be stored only as text.
+	case a == database.ValueKindJSON || b == database.ValueKindJSON:
+		return database.ValueKindJSON
+	default:
+		return database.ValueKindText
+	}
+}
+
+func isKindsPair(a, b, first, second database.ValueKind) bool {
+	return a == first && b == second || a == second && b == first
+}
+
+// objectField represents a key and a raw value of JSON object.
+type objectField struct {
+	key   string
+	value json.RawMessage
+}
+
+// parseOrderedObject returns fields of JSON object in their order, which is lost when object is decoded into a map.
+func parseOrderedObject(raw json.RawMessage) ([]objectField, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("read object start: %w", err)
+	}
+	if token != json.Delim('{') {
+		return nil, errors.New("row is not a JSON object")
+	}
+
+	var fields []objectField
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("read key: %w", err)
+		}
+
+		var value json.RawMessage
+		err = decoder.Decode(&value)
+		if err != nil {
+			return nil, fmt.Errorf("read value: %w", err)
+		}
+
+		// Duplicated keys are not added twice, the last value is used like in decoding into a map.
+		key := token.(string)
+		duplicated := false
+		for i := range fields {
+			if fields[i].key == key {
+				fields[i].value = value
+				duplicated = true
+			}
+		}
+
+		if !duplicated {
+			fields = append(fields, objectField{key: key, value: value})
+		}
+	}
+
+	_, err = decoder.Token()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read object end: %w", err)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,162 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/VladPetriv/d2j/pkg/database"
+)
+
+func TestInferTable(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		rows           []string
+		wantColumns    []database.NewColumn
+		wantPrimaryKey []string
+		wantErr        bool
+	}{
+		{
+			name: "kinds of values",
+			rows: []string{
+				`{"id": 1, "name": "a", "price": 1, "flag": true, "uid": "6f1c2a4e-8b3d-4c5e-9f7a-0b1c2d3e4f5a", "day": "2024-01-02",
+					"at": "2024-01-02T03:04:05Z", "tags": [1], "note": null}`,
+				`{"id": 2, "name": "b", "price": 1.5, "flag": false, "uid": "7f1c2a4e-8b3d-4c5e-9f7a-0b1c2d3e4f5a", "day": "2024-01-03",
+					"at": "2024-01-03", "tags": {"a": 1}, "extra": "x"}`,
+			},
+			wantColumns: []database.NewColumn{
+				{Name: "id", Kind: database.ValueKindInteger},
+				{Name: "name", Kind: database.ValueKindText},
+				{Name: "price", Kind: database.ValueKindNumber},
+				{Name: "flag", Kind: database.ValueKindBoolean},
+				{Name: "uid", Kind: database.ValueKindUUID},
+				{Name: "day", Kind: database.ValueKindDate},
+				{Name: "at", Kind: database.ValueKindTimestamp},
+				{Name: "tags", Kind: database.ValueKindJSON},
+				{Name: "note", Kind: database.ValueKindText, Nullable: true},
+				{Name: "extra", Kind: database.ValueKindText, Nullable: true},
+			},
+			wantPrimaryKey: []string{"id"},
+		},
+		{
+			name: "timestamp without time zone",
+			rows: []string{`{"at": "2024-01-02 03:04:05"}`, `{"at": "2024-01-02T03:04:05.123"}`},
+			wantColumns: []database.NewColumn{
+				{Name: "at", Kind: database.ValueKindTimestamp},
+			},
+			wantPrimaryKey: []string{},
+		},
+		{
+			name: "duplicated id is not primary key",
+			rows: []string{`{"id": "a"}`, `{"id": "a"}`},
+			wantColumns: []database.NewColumn{
+				{Name: "id", Kind: database.ValueKindText},
+			},
+			wantPrimaryKey: []string{},
+		},
+		{
+			name: "nullable id is not primary key",
+			rows: []string{`{"id": 1}`, `{"id": null}`},
+			wantColumns: []database.NewColumn{
+				{Name: "id", Kind: database.ValueKindInteger, Nullable: true},
+			},
+			wantPrimaryKey: []string{},
+		},
+		{
+			name: "decimal id is not primary key",
+			rows: []string{`{"id": 1.5}`, `{"id": 2}`},
+			wantColumns: []database.NewColumn{
+				{Name: "id", Kind: database.ValueKindNumber},
+			},
+			wantPrimaryKey: []string{},
+		},
+		{
+			name: "duplicated key uses the last value",
+			rows: []string{`{"a": "x", "b": 1, "a": 2}`},
+			wantColumns: []database.NewColumn{
+				{Name: "a", Kind: database.ValueKindInteger},
+				{Name: "b", Kind: database.ValueKindInteger},
+			},
+			wantPrimaryKey: []string{},
+		},
+		{
+			name:    "not an object",
+			rows:    []string{`{"a": 1}`, `[1, 2]`},
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			rows:    []string{`{"a": }`},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows := make([]json.RawMessage, len(tc.rows))
+			for i, row := range tc.rows {
+				rows[i] = json.RawMessage(row)
+			}
+
+			got, err := InferTable(rows)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidSample) {
+					t.Fatalf("InferTable() error = %v, want %v", err, ErrInvalidSample)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("InferTable() unexpected error: %v", err)
+			}
+			if !slices.Equal(got.Columns, tc.wantColumns) {
+				t.Errorf("InferTable() columns = %+v, want %+v", got.Columns, tc.wantColumns)
+			}
+			if !slices.Equal(got.PrimaryKey, tc.wantPrimaryKey) {
+				t.Errorf("InferTable() primary key = %v, want %v", got.PrimaryKey, tc.wantPrimaryKey)
+			}
+		})
+	}
+}
+
+func TestMergeValueKinds(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		a, b database.ValueKind
+		want database.ValueKind
+	}{
+		{a: "", b: database.ValueKindDate, want: database.ValueKindDate},
+		{a: database.ValueKindUUID, b: database.ValueKindUUID, want: database.ValueKindUUID},
+		{a: database.ValueKindInteger, b: database.ValueKindNumber, want: database.ValueKindNumber},
+		{a: database.ValueKindNumber, b: database.ValueKindInteger, want: database.ValueKindNumber},
+		{a: database.ValueKindDate, b: database.ValueKindTimestamp, want: database.ValueKindTimestamp},
+		{a: database.ValueKindTimestamp, b: database.ValueKindDate, want: database.ValueKindTimestamp},
+		{a: database.ValueKindInteger, b: database.ValueKindText, want: database.ValueKindText},
+		{a: database.ValueKindUUID, b: database.ValueKindDate, want: database.ValueKindText},
+		{a: database.ValueKindBoolean, b: database.ValueKindInteger, want: database.ValueKindText},
+		{a: database.ValueKindJSON, b: database.ValueKindInteger, want: database.ValueKindJSON},
+		{a: database.ValueKindText, b: database.ValueKindJSON, want: database.ValueKindJSON},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(string(tc.a)+"_"+string(tc.b), func(t *testing.T) {
+			t.Parallel()
+
+			got := mergeValueKinds(tc.a, tc.b)
+			if got != tc.want {
+				t.Errorf("mergeValueKinds(%q, %q) = %q, want %q", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,615 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/VladPetriv/d2j/pkg/database"
+)
+
+// jsonSchemaDialect is a meta-schema of generated JSON schemas.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSON types of values.
+const (
+	jsonTypeString  = "string"
+	jsonTypeInteger = "integer"
+	jsonTypeNumber  = "number"
+	jsonTypeBoolean = "boolean"
+	jsonTypeArray   = "array"
+	jsonTypeObject  = "object"
+	jsonTypeNull    = "null"
+)
+
+var (
+	// sqlCastRegexp matches PostgreSQL type casts, which are added to check constraint expressions.
+	sqlCastRegexp = regexp.MustCompile(
+		`(?i)::(?:"[^"]+"|[a-z_][a-z0-9_]*(?: (?:precision|varying|without time zone|with time zone))?)(?:\(\d+(?:,\s*\d+)?\))?(?:\[\])*`,
+	)
+	// sqlCharsetIntroducerRegexp matches MySQL character set introducers of string literals, e.g. _utf8mb4'value'.
+	sqlCharsetIntroducerRegexp = regexp.MustCompile(`(?i)(^|[^a-z0-9_$])_[a-z0-9]+'`)
+	// sqlOperandParenthesesRegexp matches identifiers and numbers wrapped into parentheses, e.g. (price) or (0).
+	sqlOperandParenthesesRegexp = regexp.MustCompile(
+		"(?i)(^|[^a-z0-9_$])\\(\\s*(\"(?:[^\"]|\"\")+\"|`[^`]+`|\\[[^\\]]+\\]|[a-z_][a-z0-9_$]*|-?\\d+(?:\\.\\d+)?)\\s*\\)",
+	)
+
+	sqlIdentifierPattern = "(\"(?:[^\"]|\"\")+\"|`[^`]+`|\\[[^\\]]+\\]|[a-z_][a-z0-9_$]*)"
+	sqlNumberPattern     = `(-?\d+(?:\.\d+)?(?:e[-+]?\d+)?)`
+	sqlComparisonPattern = `(>=|<=|>|<|=)`
+
+	identifierComparisonRegexp = regexp.MustCompile(`(?i)^` + sqlIdentifierPattern + `\s*` + sqlComparisonPattern + `\s*` + sqlNumberPattern + `$`)
+	numberComparisonRegexp     = regexp.MustCompile(`(?i)^` + sqlNumberPattern + `\s*` + sqlComparisonPattern + `\s*` + sqlIdentifierPattern + `$`)
+	betweenRegexp              = regexp.MustCompile(`(?i)^` + sqlIdentifierPattern + `\s+between\s+` + sqlNumberPattern + `\s+and\s+` + sqlNumberPattern + `$`)
+	inListRegexp               = regexp.MustCompile(`(?i)^` + sqlIdentifierPattern + `\s+in\s*\((.*)\)$`)
+	anyArrayRegexp             = regexp.MustCompile(`(?i)^` + sqlIdentifierPattern + `\s*=\s*any\s*\(+\s*array\s*\[(.*)\]\s*\)+$`)
+	sqlIdentifierRegexp        = regexp.MustCompile(`(?i)` + sqlIdentifierPattern)
+	numberLiteralRegexp        = regexp.MustCompile(`(?i)^` + sqlNumberPattern + `$`)
+	lengthComparisonRegexp     = regexp.MustCompile(
+		`(?i)^(?:char_length|character_length|length)\s*\(\s*` + sqlIdentifierPattern + `\s*\)\s*` + sqlComparisonPattern + `\s*(\d+)$`,
+	)
+)
+
+// BuildJSONSchema builds JSON Schema (draft 2020-12) of rows selected from the table as JSON objects with keys in columns order.
+// Check constraints that could not be expressed by JSON Schema keywords are added as a comment.
+func BuildJSONSchema(description *database.TableDescription, columns []string) ([]byte, error) {
+	tableColumns := columnsByName(description)
+
+	properties := &object{}
+	for _, name := range columns {
+		column, ok := tableColumns[name]
+		if !ok {
+			// Type of the value is unknown, so any value is allowed.
+			properties.set(name, &object{})
+			continue
+		}
+
+		properties.set(name, columnJSONSchema(description.Dialect, column))
+	}
+
+	var comments []string
+	for _, check := range description.Checks {
+		for _, condition := range splitCheckConditions(normalizeCheckExpression(check.Expression)) {
+			// Conditions of columns that are not selected do not restrict the rows.
+			if !referencesProperty(properties, condition) || applyCheckCondition(properties, condition) {
+				continue
+			}
+
+			comment := "CHECK (" + condition + ")"
+			if check.Name != "" {
+				comment = check.Name + ": " + comment
+			}
+			comments = append(comments, comment)
+		}
+	}
+
+	// All columns are always present in the row, because NULL values are converted into null.
+	items := &object{}
+	items.set("type", jsonTypeObject)
+	items.set("properties", properties)
+	items.set("required", columns)
+	items.set("additionalProperties", false)
+	if len(comments) != 0 {
+		items.set("$comment", "Check constraints that are not expressed by the schema: "+strings.Join(comments, "; "))
+	}
+
+	schema := &object{}
+	schema.set("$schema", jsonSchemaDialect)
+	schema.set("title", qualifiedTableName(description))
+	schema.set("type", jsonTypeArray)
+	schema.set("items", items)
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("marshal json schema: %w", err)
+	}
+
+	return data, nil
+}
+
+// columnJSONSchema returns schema of the column values as they are converted into JSON by the database.
+func columnJSONSchema(dialect database.Dialect, column database.Column) *object {
+	valueType := columnValueType(dialect, column)
+
+	schema := &object{}
+
+	if valueType.isArray {
+		// Elements of PostgreSQL arrays could always be NULL.
+		items := valueJSONSchema(valueType.jsonTypes, valueType.format, column.EnumValues, true)
+
+		schema.set("type", withNull([]string{jsonTypeArray}, column.Nullable))
+		schema.set("items", items)
+	} else {
+		schema = valueJSONSchema(valueType.jsonTypes, valueType.format, column.EnumValues, column.Nullable)
+	}
+
+	if column.Comment != nil && *column.Comment != "" {
+		schema.set("description", *column.Comment)
+	}
+
+	return schema
+}
+
+// valueType represents type of column values in JSON.
+type valueType struct {
+	// jsonTypes are JSON types of values or elements of array, value of any type is allowed when it's empty.
+	jsonTypes []string
+	format    string
+	// baseType is a database type of values or elements of array without parameters, e.g. "character varying".
+	baseType string
+	isArray  bool
+}
+
+// columnValueType returns type of the column values as they are converted into JSON by the database.
+func columnValueType(dialect database.Dialect, column database.Column) valueType {
+	dataType := strings.ToLower(strings.TrimSpace(column.DataType))
+
+	var value valueType
+
+	value.isArray = dialect == database.DialectPostgreSQL && strings.HasSuffix(dataType, "[]")
+	if value.isArray {
+		dataType = strings.TrimSuffix(dataType, "[]")
+	}
+	value.baseType = strings.TrimSpace(sqlTypeParametersRegexp.ReplaceAllString(dataType, ""))
+
+	switch dialect {
+	case database.DialectPostgreSQL:
+		value.jsonTypes, value.format = postgreSQLJSONType(dataType)
+	case database.DialectMySQL:
+		value.jsonTypes, value.format = mySQLJSONType(dataType)
+	case database.DialectSQLite:
+		value.jsonTypes = sqLiteJSONType(dataType)
+	}
+
+	if len(column.EnumValues) != 0 {
+		value.jsonTypes, value.format = []string{jsonTypeString}, ""
+	}
+
+	return value
+}
+
+// valueJSONSchema returns schema of a scalar value, value of any type is allowed when types are empty.
+func valueJSONSchema(jsonTypes []string, format string, enumValues []string, nullable bool) *object {
+	schema := &object{}
+
+	if len(jsonTypes) != 0 {
+		schema.set("type", withNull(jsonTypes, nullable))
+	}
+	if format != "" {
+		schema.set("format", format)
+	}
+	if len(enumValues) != 0 {
+		enum := make([]interface{}, 0, len(enumValues)+1)
+		for _, value := range enumValues {
+			enum = append(enum, value)
+		}
+		if nullable {
+			enum = append(enum, nil)
+		}
+
+		schema.set("enum", enum)
+	}
+
+	return schema
+}
+
+// withNull adds null type to types of nullable value.
+func withNull(jsonTypes []string, nullable bool) interface{} {
+	if nullable {
+		jsonTypes = append(jsonTypes, jsonTypeNull)
+	}
+	if len(jsonTypes) == 1 {
+		return jsonTypes[0]
+	}
+
+	return jsonTypes
+}
+
+// postgreSQLJSONType returns JSON type of values converted by to_jsonb, type name must be in format_type format.
+// Types, for which it's unknown, e.g. composite types and domains, allow any value.
+func postgreSQLJSONType(dataType string) ([]string, string) {
+	baseType := strings.TrimSpace(sqlTypeParametersRegexp.ReplaceAllString(dataType, ""))
+
+	switch baseType {
+	case "smallint", "integer", "bigint":
+		return []string{jsonTypeInteger}, ""
+	case "numeric", "real", "double precision":
+		return []string{jsonTypeNumber}, ""
+	case "boolean":
+		return []string{jsonTypeBoolean}, ""
+	case "json", "jsonb":
+		return nil, ""
+	case "date":
+		return []string{jsonTypeString}, "date"
+	case "timestamp with time zone":
+		return []string{jsonTypeString}, "date-time"
+	case "uuid":
+		return []string{jsonTypeString}, "uuid"
+	case "text", "character varying", "character", `"char"`, "name", "citext", "bytea", "money", "interval",
+		"timestamp without time zone", "time without time zone", "time with time zone", "inet", "cidr", "macaddr",
+		"macaddr8", "xml", "bit", "bit varying", "tsvector", "tsquery", "point", "line", "lseg", "box", "path",
+		"polygon", "circle", "int4range", "int8range", "numrange", "tsrange", "tstzrange", "daterange", "oid":
+		return []string{jsonTypeString}, ""
+	default:
+		return nil, ""
+	}
+}
+
+// mySQLJSONType returns JSON type of values converted by JSON_OBJECT, type name must be in column_type format.
+func mySQLJSONType(dataType string) ([]string, string) {
+	baseType := strings.Fields(sqlTypeParametersRegexp.ReplaceAllString(dataType, ""))
+	if len(baseType) == 0 {
+		return nil, ""
+	}
+
+	switch baseType[0] {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "year":
+		return []string{jsonTypeInteger}, ""
+	case "decimal", "numeric", "float", "double", "real":
+		return []string{jsonTypeNumber}, ""
+	case "json", "geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon",
+		"geometrycollection", "geomcollection":
+		return nil, ""
+	case "date":
+		return []string{jsonTypeString}, "date"
+	default:
+		return []string{jsonTypeString}, ""
+	}
+}
+
+// sqLiteJSONType returns JSON type of values by type affinity of declared column type.
+// SQLite does not enforce column types, so schema describes values that are stored according to the affinity.
+func sqLiteJSONType(dataType string) []string {
+	switch {
+	case strings.Contains(dataType, "int"):
+		return []string{jsonTypeInteger}
+	case strings.Contains(dataType, "char") || strings.Contains(dataType, "clob") || strings.Contains(dataType, "text"):
+		return []string{jsonTypeString}
+	case dataType == "" || strings.Contains(dataType, "blob"):
+		return nil
+	case strings.Contains(dataType, "real") || strings.Contains(dataType, "floa") || strings.Contains(dataType, "doub"):
+		return []string{jsonTypeNumber}
+	default:
+		// Numeric affinity keeps text values that do not look like numbers, e.g. dates.
+		return []string{jsonTypeNumber, jsonTypeString}
+	}
+}
+
+// normalizeCheckExpression removes type casts, charset introducers and redundant parentheses from the expression.
+func normalizeCheckExpression(expression string) string {
+	expression = sqlCastRegexp.ReplaceAllString(expression, "")
+	expression = sqlCharsetIntroducerRegexp.ReplaceAllString(expression, "$1'")
+
+	for {
+		normalized := sqlOperandParenthesesRegexp.ReplaceAllString(expression, "$1$2")
+		if normalized == expression {
+			break
+		}
+		expression = normalized
+	}
+
+	return trimOuterParentheses(strings.TrimSpace(expression))
+}
+
+// splitCheckConditions splits expression into conditions joined by top-level AND.
+func splitCheckConditions(expression string) []string {
+	var conditions []string
+
+	depth, start := 0, 0
+	for i := 0; i < len(expression); i++ {
+		switch expression[i] {
+		case '\'', '"', '`':
+			i = skipQuoted(expression, i) - 1
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ' ':
+			if depth != 0 || !strings.HasPrefix(strings.ToLower(expression[i:]), " and ") {
+				continue
+			}
+
+			condition := strings.TrimSpace(expression[start:i])
+
+			// AND of BETWEEN operator does not separate conditions.
+			if fields := strings.Fields(strings.ToLower(condition)); len(fields) >= 2 && fields[len(fields)-2] == "between" {
+				continue
+			}
+
+			conditions = append(conditions, trimOuterParentheses(condition))
+			start = i + len(" and ")
+		}
+	}
+
+	return append(conditions, trimOuterParentheses(strings.TrimSpace(expression[start:])))
+}
+
+// trimOuterParentheses removes parentheses that wrap the whole expression.
+func trimOuterParentheses(expression string) string {
+	for strings.HasPrefix(expression, "(") && closingParenthesis(expression) == len(expression)-1 {
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+
+	return expression
+}
+
+// closingParenthesis returns position of the parenthesis that closes the first one.
+func closingParenthesis(expression string) int {
+	depth := 0
+
+	for i := 0; i < len(expression); i++ {
+		switch expression[i] {
+		case '\'', '"', '`':
+			i = skipQuoted(expression, i) - 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// skipQuoted returns position after the quoted literal or identifier that starts at the position.
+func skipQuoted(expression string, start int) int {
+	quote := expression[start]
+
+	for i := start + 1; i < len(expression); i++ {
+		if expression[i] != quote {
+			continue
+		}
+		if i+1 < len(expression) && expression[i+1] == quote {
+			i++
+			continue
+		}
+
+		return i + 1
+	}
+
+	return len(expression)
+}
+
+// applyCheckCondition adds JSON Schema keywords of the condition to the property of its column.
+// It returns false when condition could not be expressed by keywords.
+func applyCheckCondition(properties *object, condition string) bool {
+	if match := identifierComparisonRegexp.FindStringSubmatch(condition); match != nil {
+		return applyNumberComparison(properties, match[1], match[2], match[3])
+	}
+	if match := numberComparisonRegexp.FindStringSubmatch(condition); match != nil {
+		return applyNumberComparison(properties, match[3], flipComparison(match[2]), match[1])
+	}
+	if match := betweenRegexp.FindStringSubmatch(condition); match != nil {
+		property, ok := numberProperty(properties, match[1])
+		if !ok {
+			return false
+		}
+
+		property.set("minimum", json.Number(match[2]))
+		property.set("maximum", json.Number(match[3]))
+		return true
+	}
+	if match := lengthComparisonRegexp.FindStringSubmatch(condition); match != nil {
+		return applyLengthComparison(properties, match[1], match[2], match[3])
+	}
+
+	var identifier, list string
+	if match := inListRegexp.FindStringSubmatch(condition); match != nil {
+		identifier, list = match[1], match[2]
+	} else if match := anyArrayRegexp.FindStringSubmatch(condition); match != nil {
+		identifier, list = match[1], match[2]
+	} else {
+		return false
+	}
+
+	property, ok := findProperty(properties, identifier)
+	if !ok {
+		return false
+	}
+
+	values, ok := parseLiteralList(list)
+	if !ok {
+		return false
+	}
+
+	// NULL passes check constraints, so it remains allowed for nullable columns.
+	if columnTypes, ok := property.values["type"].([]string); ok && columnTypes[len(columnTypes)-1] == jsonTypeNull {
+		values = append(values, nil)
+	}
+
+	property.set("enum", values)
+	return true
+}
+
+// applyNumberComparison adds minimum or maximum keyword of the comparison with a number.
+func applyNumberComparison(properties *object, identifier, operator, number string) bool {
+	property, ok := numberProperty(properties, identifier)
+	if !ok {
+		return false
+	}
+
+	value := json.Number(number)
+
+	switch operator {
+	case ">":
+		property.set("exclusiveMinimum", value)
+	case ">=":
+		property.set("minimum", value)
+	case "<":
+		property.set("exclusiveMaximum", value)
+	case "<=":
+		property.set("maximum", value)
+	case "=":
+		property.set("const", value)
+	}
+
+	return true
+}
+
+// applyLengthComparison adds minLength or maxLength keyword of the comparison of string length.
+func applyLengthComparison(properties *object, identifier, operator, number string) bool {
+	property, ok := findProperty(properties, identifier)
+	if !ok || !hasType(property, jsonTypeString) {
+		return false
+	}
+
+	length, err := strconv.Atoi(number)
+	if err != nil {
+		return false
+	}
+
+	switch operator {
+	case ">":
+		property.set("minLength", length+1)
+	case ">=":
+		property.set("minLength", length)
+	case "<":
+		property.set("maxLength", max(length-1, 0))
+	case "<=":
+		property.set("maxLength", length)
+	case "=":
+		property.set("minLength", length)
+		property.set("maxLength", length)
+	}
+
+	return true
+}
+
+// numberProperty returns property of the column, which values are numbers.
+func numberProperty(properties *object, identifier string) (*object, bool) {
+	property, ok := findProperty(properties, identifier)
+	if !ok || !hasType(property, jsonTypeNumber) && !hasType(property, jsonTypeInteger) {
+		return nil, false
+	}
+
+	return property, true
+}
+
+// findProperty returns property of the column referenced by SQL identifier.
+// Unquoted identifiers are matched case-insensitively.
+func findProperty(properties *object, identifier string) (*object, bool) {
+	name, quoted := unquoteIdentifier(identifier)
+
+	for _, key := range properties.keys {
+		if key == name || !quoted && strings.EqualFold(key, name) {
+			property, ok := properties.values[key].(*object)
+			return property, ok
+		}
+	}
+
+	return nil, false
+}
+
+// referencesProperty reports whether condition contains an identifier of any property.
+func referencesProperty(properties *object, condition string) bool {
+	for _, identifier := range sqlIdentifierRegexp.FindAllString(stripStringLiterals(condition), -1) {
+		if _, ok := findProperty(properties, identifier); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stripStringLiterals replaces string literals of the expression with empty strings.
+func stripStringLiterals(expression string) string {
+	var stripped strings.Builder
+
+	for i := 0; i < len(expression); i++ {
+		if expression[i] == '\'' {
+			i = skipQuoted(expression, i) - 1
+			stripped.WriteString("''")
+			continue
+		}
+
+		stripped.WriteByte(expression[i])
+	}
+
+	return stripped.String()
+}
+
+// hasType reports whether property allows values of the type.
+func hasType(property *object, jsonType string) bool {
+	switch types := property.values["type"].(type) {
+	case string:
+		return types == jsonType
+	case []string:
+		for _, t := range types {
+			if t == jsonType {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// unquoteIdentifier removes quotes of SQL identifier and reports whether it was quoted.
+func unquoteIdentifier(identifier string) (string, bool) {
+	if len(identifier) < 2 {
+		return identifier, false
+	}
+
+	switch identifier[0] {
+	case '"':
+		return strings.ReplaceAll(identifier[1:len(identifier)-1], `""`, `"`), true
+	case '`':
+		return identifier[1 : len(identifier)-1], true
+	case '[':
+		return identifier[1 : len(identifier)-1], true
+	default:
+		return identifier, false
+	}
+}
+
+// parseLiteralList parses comma-separated list of string and number literals.
+func parseLiteralList(list string) ([]interface{}, bool) {
+	values := make([]interface{}, 0)
+
+	for start := 0; start <= len(list); {
+		end := start
+		for end < len(list) && list[end] != ',' {
+			if list[end] == '\'' {
+				end = skipQuoted(list, end)
+				continue
+			}
+			end++
+		}
+
+		literal := strings.TrimSpace(list[start:end])
+
+		switch {
+		case len(literal) >= 2 && literal[0] == '\'' && literal[len(literal)-1] == '\'':
+			values = append(values, strings.ReplaceAll(literal[1:len(literal)-1], "''", "'"))
+		case numberLiteralRegexp.MatchString(literal):
+			values = append(values, json.Number(literal))
+		default:
+			return nil, false
+		}
+
+		start = end + 1
+	}
+
+	return values, true
+}
+
+// flipComparison returns operator of the comparison with swapped operands.
+func flipComparison(operator string) string {
+	switch operator {
+	case ">":
+		return "<"
+	case ">=":
+		return "<="
+	case "<":
+		return ">"
+	case "<=":
+		return ">="
+	default:
+		return operator
+	}
+}
@@ -0,0 +1,528 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+	"github.com/parquet-go/parquet-go/encoding"
+)
+
+// ParquetType represents a parquet type to which column values are converted.
+type ParquetType string
+
+const (
+	// ParquetString - UTF-8 string, values of unknown types are also written as strings.
+	ParquetString ParquetType = "string"
+	// ParquetInt32 - 32-bit signed integer.
+	ParquetInt32 ParquetType = "int32"
+	// ParquetInt64 - 64-bit signed integer.
+	ParquetInt64 ParquetType = "int64"
+	// ParquetFloat - 32-bit floating point number.
+	ParquetFloat ParquetType = "float"
+	// ParquetDouble - 64-bit floating point number.
+	ParquetDouble ParquetType = "double"
+	// ParquetBoolean - boolean.
+	ParquetBoolean ParquetType = "boolean"
+	// ParquetDecimal - decimal with fixed precision and scale.
+	ParquetDecimal ParquetType = "decimal"
+	// ParquetTimestamp - timestamp in microseconds, values without time zone are treated as UTC.
+	ParquetTimestamp ParquetType = "timestamp"
+	// ParquetDate - date without time.
+	ParquetDate ParquetType = "date"
+	// ParquetUUID - UUID stored as 16 bytes.
+	ParquetUUID ParquetType = "uuid"
+	// ParquetJSON - JSON document.
+	ParquetJSON ParquetType = "json"
+)
+
+// ParquetColumn represents a column of parquet file.
+type ParquetColumn struct {
+	Name string
+	Type ParquetType
+	// Precision and Scale are used only by decimal type.
+	Precision int
+	Scale     int
+	// List means that column contains list of values of the Type.
+	List bool
+}
+
+// ParquetOptions represents an options that used for writing parquet file.
+type ParquetOptions struct {
+	Columns []ParquetColumn
+	// RowGroupSize is a maximum number of rows in a row group, rows are buffered in memory until group is full.
+	RowGroupSize int
+}
+
+// maxInt64DecimalPrecision is a maximum precision of decimal that fits into int64.
+const maxInt64DecimalPrecision = 18
+
+// maxDecimalPrecision is a maximum precision of decimal that fits into 16 bytes.
+const maxDecimalPrecision = 38
+
+// decimalByteLength is a length of fixed byte array of decimals with precision greater than maxInt64DecimalPrecision.
+const decimalByteLength = 16
+
+var (
+	sqlTypeParametersRegexp = regexp.MustCompile(`\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+	unixEpoch               = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	timestampLayouts        = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999",
+	}
+)
+
+// ParquetColumnFromSQLType returns parquet column for SQL data type, as it's reported by PostgreSQL, MySQL or SQLite.
+// Types that do not have a parquet analog are written as strings.
+func ParquetColumnFromSQLType(name, dataType string) ParquetColumn {
+	column := ParquetColumn{Name: name, Type: ParquetString}
+
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+	if strings.HasSuffix(dataType, "[]") {
+		column.List = true
+		dataType = strings.TrimSuffix(dataType, "[]")
+	}
+
+	var precision, scale int
+	hasPrecision := false
+
+	if match := sqlTypeParametersRegexp.FindStringSubmatch(dataType); match != nil {
+		precision, _ = strconv.Atoi(match[1])
+		scale, _ = strconv.Atoi(match[2])
+		hasPrecision = true
+	}
+
+	baseType := strings.TrimSpace(sqlTypeParametersRegexp.ReplaceAllString(dataType, ""))
+
+	switch {
+	case baseType == "boolean" || baseType == "bool" || dataType == "tinyint(1)":
+		column.Type = ParquetBoolean
+
+	case baseType == "bigint unsigned":
+		// Unsigned 64-bit values do not fit into int64.
+		column.Type = ParquetDecimal
+		column.Precision = 20
+
+	case baseType == "bigint" || baseType == "int8" || strings.HasSuffix(baseType, "unsigned") && strings.Contains(baseType, "int"):
+		column.Type = ParquetInt64
+
+	case baseType == "int4" || baseType == "smallint" || baseType == "int2" || baseType == "tinyint":
+		column.Type = ParquetInt32
+
+	case strings.Contains(baseType, "int"):
+		// SQLite stores 64-bit values in INTEGER columns and its integer affinity covers any type name
+		// that contains "int", so ambiguous names like "integer" are written as int64.
+		column.Type = ParquetInt64
+
+	case baseType == "float4":
+		column.Type = ParquetFloat
+
+	case baseType == "real" || baseType == "float" || baseType == "double precision" || baseType == "double" || baseType == "float8":
+		// REAL is 64-bit in SQLite and FLOAT could be 64-bit in MySQL, so they are written as double.
+		column.Type = ParquetDouble
+
+	case baseType == "numeric" || baseType == "decimal":
+		if hasPrecision && precision <= maxDecimalPrecision {
+			column.Type = ParquetDecimal
+			column.Precision = precision
+			column.Scale = scale
+		}
+
+	case strings.HasPrefix(baseType, "timestamp") || baseType == "datetime":
+		column.Type = ParquetTimestamp
+
+	case baseType == "date":
+		column.Type = ParquetDate
+
+	case baseType == "uuid":
+		column.Type = ParquetUUID
+
+	case baseType == "json" || baseType == "jsonb":
+		column.Type = ParquetJSON
+	}
+
+	return column
+}
+
+// ParquetWriter writes rows, which are JSON objects, into parquet file.
+// Rows are buffered only until the row group is full, so file could be written while rows are streamed.
+type ParquetWriter struct {
+	columns []ParquetColumn
+	writer  *parquet.Writer
+
+	row parquet.Row
+}
+
+// NewParquetWriter is used to create an instance of parquet writer.
+func NewParquetWriter(w io.Writer, options ParquetOptions) *ParquetWriter {
+	fields := make(parquetGroup, len(options.Columns))
+	for i, column := range options.Columns {
+		fields[i] = &parquetField{Node: parquetNode(column), name: column.Name}
+	}
+
+	writerOptions := []parquet.WriterOption{
+		parquet.NewSchema("rows", fields),
+		parquet.Compression(&parquet.Snappy),
+	}
+	if options.RowGroupSize > 0 {
+		writerOptions = append(writerOptions, parquet.MaxRowsPerRowGroup(int64(options.RowGroupSize)))
+	}
+
+	return &ParquetWriter{
+		columns: options.Columns,
+		writer:  parquet.NewWriter(w, writerOptions...),
+	}
+}
+
+// parquetNode returns optional node of the column, list elements are also optional.
+func parquetNode(column ParquetColumn) parquet.Node {
+	var node parquet.Node
+
+	switch column.Type {
+	case ParquetInt32:
+		node = parquet.Int(32)
+	case ParquetInt64:
+		node = parquet.Int(64)
+	case ParquetFloat:
+		node = parquet.Leaf(parquet.FloatType)
+	case ParquetDouble:
+		node = parquet.Leaf(parquet.DoubleType)
+	case ParquetBoolean:
+		node = parquet.Leaf(parquet.BooleanType)
+	case ParquetDecimal:
+		if column.Precision <= maxInt64DecimalPrecision {
+			node = parquet.Decimal(column.Scale, column.Precision, parquet.Int64Type)
+		} else {
+			node = parquet.Decimal(column.Scale, column.Precision, parquet.FixedLenByteArrayType(decimalByteLength))
+		}
+	case ParquetTimestamp:
+		node = parquet.Timestamp(parquet.Microsecond)
+	case ParquetDate:
+		node = parquet.Date()
+	case ParquetUUID:
+		node = parquet.UUID()
+	case ParquetJSON:
+		node = parquet.JSON()
+	default:
+		node = parquet.String()
+	}
+
+	if column.List {
+		return parquet.Optional(parquet.List(parquet.Optional(node)))
+	}
+
+	return parquet.Optional(node)
+}
+
+// Definition levels of list columns: list is NULL, list is empty, element is NULL and element is set.
+const (
+	listNullLevel = iota
+	listEmptyLevel
+	listElementNullLevel
+	listElementLevel
+)
+
+// WriteRow writes a single row, which is a JSON object.
+func (p *ParquetWriter) WriteRow(row []byte) error {
+	values, err := decodeRow(row)
+	if err != nil {
+		return err
+	}
+
+	p.row = p.row[:0]
+
+	for i, column := range p.columns {
+		value := values[column.Name]
+
+		if !column.List {
+			if isNull(value) {
+				p.row = append(p.row, parquet.NullValue().Level(0, 0, i))
+				continue
+			}
+
+			parquetValue, err := parquetValueOf(column, value)
+			if err != nil {
+				return fmt.Errorf("convert column %s value: %w", column.Name, err)
+			}
+
+			p.row = append(p.row, parquetValue.Level(0, 1, i))
+			continue
+		}
+
+		if isNull(value) {
+			p.row = append(p.row, parquet.NullValue().Level(0, listNullLevel, i))
+			continue
+		}
+
+		var elements []json.RawMessage
+		err := json.Unmarshal(value, &elements)
+		if err != nil {
+			return fmt.Errorf("%w: column %s value is not a list", ErrUnsupportedValue, column.Name)
+		}
+
+		if len(elements) == 0 {
+			p.row = append(p.row, parquet.NullValue().Level(0, listEmptyLevel, i))
+			continue
+		}
+
+		for j, element := range elements {
+			// The first element starts a new list, the others continue it.
+			repetitionLevel := 0
+			if j != 0 {
+				repetitionLevel = 1
+			}
+
+			if isNull(element) {
+				p.row = append(p.row, parquet.NullValue().Level(repetitionLevel, listElementNullLevel, i))
+				continue
+			}
+
+			parquetValue, err := parquetValueOf(column, element)
+			if err != nil {
+				return fmt.Errorf("convert column %s element value: %w", column.Name, err)
+			}
+
+			p.row = append(p.row, parquetValue.Level(repetitionLevel, listElementLevel, i))
+		}
+	}
+
+	_, err = p.writer.WriteRows([]parquet.Row{p.row})
+	if err != nil {
+		return fmt.Errorf("write parquet row: %w", err)
+	}
+
+	return nil
+}
+
+// Close writes buffered rows and footer of the file.
+func (p *ParquetWriter) Close() error {
+	err := p.writer.Close()
+	if err != nil {
+		return fmt.Errorf("close parquet writer: %w", err)
+	}
+
+	return nil
+}
+
+// parquetValueOf converts not NULL JSON value into parquet value of the column type.
+func parquetValueOf(column ParquetColumn, value json.RawMessage) (parquet.Value, error) {
+	if column.Type == ParquetJSON {
+		text, err := valueToText(value)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+
+		// Strings are kept as JSON strings to write valid JSON documents.
+		if value[0] == '"' {
+			text = string(value)
+		}
+
+		return parquet.ByteArrayValue([]byte(text)), nil
+	}
+
+	text, err := valueToText(value)
+	if err != nil {
+		return parquet.Value{}, err
+	}
+
+	unsupported := func(err error) (parquet.Value, error) {
+		return parquet.Value{}, fmt.Errorf("%w: %q could not be converted to %s: %v", ErrUnsupportedValue, text, column.Type, err)
+	}
+
+	switch column.Type {
+	case ParquetInt32:
+		number, err := strconv.ParseInt(text, 10, 32)
+		if err != nil {
+			return unsupported(err)
+		}
+
+		return parquet.Int32Value(int32(number)), nil
+
+	case ParquetInt64:
+		number, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return unsupported(err)
+		}
+
+		return parquet.Int64Value(number), nil
+
+	case ParquetFloat:
+		number, err := strconv.ParseFloat(text, 32)
+		if err != nil {
+			return unsupported(err)
+		}
+
+		return parquet.FloatValue(float32(number)), nil
+
+	case ParquetDouble:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return unsupported(err)
+		}
+
+		return parquet.DoubleValue(number), nil
+
+	case ParquetBoolean:
+		switch text {
+		case "true", "1":
+			return parquet.BooleanValue(true), nil
+		case "false", "0":
+			return parquet.BooleanValue(false), nil
+		default:
+			return unsupported(fmt.Errorf("not a boolean"))
+		}
+
+	case ParquetDecimal:
+		unscaled, err := unscaledDecimal(text, column.Scale)
+		if err != nil {
+			return unsupported(err)
+		}
+
+		if column.Precision <= maxInt64DecimalPrecision {
+			if !unscaled.IsInt64() {
+				return unsupported(fmt.Errorf("value overflows precision %d", column.Precision))
+			}
+
+			return parquet.Int64Value(unscaled.Int64()), nil
+		}
+
+		bytes, err := decimalBytes(unscaled)
+		if err != nil {
+			return unsupported(err)
+		}
+
+		return parquet.FixedLenByteArrayValue(bytes), nil
+
+	case ParquetTimestamp:
+		timestamp, err := parseTimestamp(text)
+		if err != nil {
+			return unsupported(err)
+		}
+
+		return parquet.Int64Value(timestamp.UnixMicro()), nil
+
+	case ParquetDate:
+		date, err := time.Parse(time.DateOnly, text)
+		if err != nil {
+			return unsupported(err)
+		}
+
+		return parquet.Int32Value(int32(date.Sub(unixEpoch).Hours() / 24)), nil
+
+	case ParquetUUID:
+		id, err := uuid.Parse(text)
+		if err != nil {
+			return unsupported(err)
+		}
+
+		return parquet.FixedLenByteArrayValue(id[:]), nil
+
+	default:
+		return parquet.ByteArrayValue([]byte(text)), nil
+	}
+}
+
+func parseTimestamp(text string) (time.Time, error) {
+	var err error
+
+	for _, layout := range timestampLayouts {
+		var timestamp time.Time
+
+		timestamp, err = time.Parse(layout, text)
+		if err == nil {
+			return timestamp, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+// unscaledDecimal returns decimal value multiplied by 10^scale and rounded half away from zero.
+func unscaledDecimal(text string, scale int) (*big.Int, error) {
+	value, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, fmt.Errorf("not a number")
+	}
+
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+
+	// Round when remainder is at least a half of the denominator.
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+
+	return quotient, nil
+}
+
+// decimalBytes returns big-endian two's complement representation of the value with decimalByteLength bytes.
+func decimalBytes(value *big.Int) ([]byte, error) {
+	limit := new(big.Int).Lsh(big.NewInt(1), decimalByteLength*8-1)
+	if value.Cmp(limit) >= 0 || value.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return nil, fmt.Errorf("value overflows %d bytes", decimalByteLength)
+	}
+
+	twosComplement := new(big.Int).Set(value)
+	if value.Sign() < 0 {
+		twosComplement.Add(twosComplement, new(big.Int).Lsh(big.NewInt(1), decimalByteLength*8))
+	}
+
+	return twosComplement.FillBytes(make([]byte, decimalByteLength)), nil
+}
+
+// parquetGroup is a root node of the schema, which keeps the order of columns.
+// It's used instead of parquet.Group, because the latter sorts columns by name.
+type parquetGroup []parquet.Field
+
+func (g parquetGroup) ID() int { return 0 }
+
+func (g parquetGroup) String() string {
+	names := make([]string, len(g))
+	for i, field := range g {
+		names[i] = field.Name()
+	}
+
+	return "group {" + strings.Join(names, ", ") + "}"
+}
+
+func (g parquetGroup) Type() parquet.Type { return parquet.Group{}.Type() }
+
+func (g parquetGroup) Optional() bool { return false }
+
+func (g parquetGroup) Repeated() bool { return false }
+
+func (g parquetGroup) Required() bool { return true }
+
+func (g parquetGroup) Leaf() bool { return false }
+
+func (g parquetGroup) Fields() []parquet.Field { return g }
+
+func (g parquetGroup) Encoding() encoding.Encoding { return nil }
+
+func (g parquetGroup) Compression() compress.Codec { return nil }
+
+func (g parquetGroup) GoType() reflect.Type { return reflect.TypeOf(map[string]interface{}(nil)) }
+
+// parquetField is a named column of the parquetGroup.
+type parquetField struct {
+	parquet.Node
+	name string
+}
+
+func (f *parquetField) Name() string { return f.name }
+
+func (f *parquetField) Value(base reflect.Value) reflect.Value {
+	return base.MapIndex(reflect.ValueOf(f.name))
+}
@@ -0,0 +1,188 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RenderTOML writes rows, which are JSON objects, as TOML document.
+// Rows are rendered as an array of tables under the root key, or as tables named by row keys in keyed mode.
+// TOML has no null, so keys with NULL values are omitted and NULL inside of arrays is not supported.
+func RenderTOML(w io.Writer, rows [][]byte, options DocumentOptions) error {
+	doc, err := buildDocument(rows, options)
+	if err != nil {
+		return err
+	}
+
+	var builder strings.Builder
+
+	if doc.keyed {
+		for i, keyed := range doc.keyedRows {
+			if i != 0 {
+				builder.WriteByte('\n')
+			}
+
+			builder.WriteString("[" + tomlKey(keyed.key) + "]\n")
+
+			err = writeTOMLTable(&builder, keyed.row)
+			if err != nil {
+				return err
+			}
+		}
+
+		return writeString(w, builder.String())
+	}
+
+	rootKey := tomlKey(options.RootKey)
+
+	// Array of tables could not be empty, so empty result is written as an empty array.
+	if len(doc.rows) == 0 {
+		return writeString(w, rootKey+" = []\n")
+	}
+
+	for i, row := range doc.rows {
+		if i != 0 {
+			builder.WriteByte('\n')
+		}
+
+		builder.WriteString("[[" + rootKey + "]]\n")
+
+		err = writeTOMLTable(&builder, row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeString(w, builder.String())
+}
+
+// writeTOMLTable writes key/value pairs of the table, nested objects are written as inline tables.
+func writeTOMLTable(builder *strings.Builder, table *object) error {
+	for _, key := range table.keys {
+		value := table.values[key]
+		if value == nil {
+			continue
+		}
+
+		text, err := tomlValue(value)
+		if err != nil {
+			return fmt.Errorf("%w: key %s", err, key)
+		}
+
+		builder.WriteString(tomlKey(key) + " = " + text + "\n")
+	}
+
+	return nil
+}
+
+func tomlValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case *object:
+		pairs := make([]string, 0, len(v.keys))
+
+		for _, key := range v.keys {
+			if v.values[key] == nil {
+				continue
+			}
+
+			text, err := tomlValue(v.values[key])
+			if err != nil {
+				return "", err
+			}
+
+			pairs = append(pairs, tomlKey(key)+" = "+text)
+		}
+
+		if len(pairs) == 0 {
+			return "{}", nil
+		}
+
+		return "{ " + strings.Join(pairs, ", ") + " }", nil
+
+	case []interface{}:
+		elements := make([]string, len(v))
+
+		for i, element := range v {
+			if element == nil {
+				return "", fmt.Errorf("%w: TOML arrays could not contain null", ErrUnsupportedValue)
+			}
+
+			text, err := tomlValue(element)
+			if err != nil {
+				return "", err
+			}
+
+			elements[i] = text
+		}
+
+		return "[" + strings.Join(elements, ", ") + "]", nil
+
+	case string:
+		return tomlString(v), nil
+
+	case json.Number:
+		return v.String(), nil
+
+	case bool:
+		return fmt.Sprint(v), nil
+
+	default:
+		return "", fmt.Errorf("%w: %v", ErrUnsupportedValue, v)
+	}
+}
+
+// tomlKey returns key as is when it's a valid bare key, otherwise it's quoted.
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+
+	for _, char := range key {
+		isBare := char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' ||
+			char == '_' || char == '-'
+		if !isBare {
+			return tomlString(key)
+		}
+	}
+
+	return key
+}
+
+// tomlString returns TOML basic string with escaped quotes, backslashes and control characters.
+func tomlString(value string) string {
+	var builder strings.Builder
+
+	builder.WriteByte('"')
+
+	for _, char := range value {
+		switch char {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '\r':
+			builder.WriteString(`\r`)
+		default:
+			if char < 0x20 || char == 0x7f {
+				fmt.Fprintf(&builder, `\u%04X`, char)
+				continue
+			}
+
+			builder.WriteRune(char)
+		}
+	}
+
+	builder.WriteByte('"')
+
+	return builder.String()
+}
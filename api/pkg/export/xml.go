@@ -0,0 +1,151 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"unicode"
+
+	"github.com/VladPetriv/d2j/pkg/errs"
+)
+
+// XMLOptions represents an options that used for writing XML.
+type XMLOptions struct {
+	// Columns defines order of the values in every row element.
+	Columns     []string
+	RootElement string
+	RowElement  string
+	// AttributeColumns are written as attributes of the row element, other columns are written as child elements.
+	AttributeColumns []string
+}
+
+// ErrInvalidXMLName - element or attribute name is not a valid XML name.
+var ErrInvalidXMLName = errs.New("invalid XML name")
+
+// XMLWriter writes rows, which are JSON objects, as XML elements.
+// NULL child elements are marked with xsi:nil, while NULL attributes are omitted, because attributes could not be nil.
+// Arrays and nested objects are written as compact JSON text.
+type XMLWriter struct {
+	w       io.Writer
+	options XMLOptions
+
+	element bytes.Buffer
+}
+
+// NewXMLWriter is used to create an instance of XML writer. All names are validated before writing anything.
+func NewXMLWriter(w io.Writer, options XMLOptions) (*XMLWriter, error) {
+	names := append([]string{options.RootElement, options.RowElement}, options.Columns...)
+	for _, name := range names {
+		if !isXMLName(name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidXMLName, name)
+		}
+	}
+
+	return &XMLWriter{
+		w:       w,
+		options: options,
+	}, nil
+}
+
+// WriteStart writes XML declaration and opening tag of the root element.
+func (x *XMLWriter) WriteStart() error {
+	return writeString(x.w, fmt.Sprintf(
+		"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<%s xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\">\n",
+		x.options.RootElement,
+	))
+}
+
+// WriteRow writes a single row, which is a JSON object, as row element.
+func (x *XMLWriter) WriteRow(row []byte) error {
+	values, err := decodeRow(row)
+	if err != nil {
+		return err
+	}
+
+	x.element.Reset()
+	x.element.WriteString("  <" + x.options.RowElement)
+
+	var children []string
+
+	for _, column := range x.options.Columns {
+		if !slices.Contains(x.options.AttributeColumns, column) {
+			children = append(children, column)
+			continue
+		}
+
+		value := values[column]
+		if isNull(value) {
+			continue
+		}
+
+		text, err := valueToText(value)
+		if err != nil {
+			return fmt.Errorf("convert column %s value to text: %w", column, err)
+		}
+
+		x.element.WriteString(" " + column + `="`)
+		x.escape(text)
+		x.element.WriteByte('"')
+	}
+
+	if len(children) == 0 {
+		x.element.WriteString("/>\n")
+		return writeString(x.w, x.element.String())
+	}
+
+	x.element.WriteString(">\n")
+
+	for _, column := range children {
+		value := values[column]
+		if isNull(value) {
+			x.element.WriteString("    <" + column + " xsi:nil=\"true\"/>\n")
+			continue
+		}
+
+		text, err := valueToText(value)
+		if err != nil {
+			return fmt.Errorf("convert column %s value to text: %w", column, err)
+		}
+
+		x.element.WriteString("    <" + column + ">")
+		x.escape(text)
+		x.element.WriteString("</" + column + ">\n")
+	}
+
+	x.element.WriteString("  </" + x.options.RowElement + ">\n")
+
+	return writeString(x.w, x.element.String())
+}
+
+// WriteEnd writes closing tag of the root element.
+func (x *XMLWriter) WriteEnd() error {
+	return writeString(x.w, "</"+x.options.RootElement+">\n")
+}
+
+// escape writes text with escaped special characters, which is valid both in attribute and element text.
+func (x *XMLWriter) escape(text string) {
+	// Writing into buffer never fails.
+	_ = xml.EscapeText(&x.element, []byte(text))
+}
+
+// isXMLName reports whether name could be used as element or attribute name without namespace prefix.
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, char := range name {
+		if unicode.IsLetter(char) || char == '_' {
+			continue
+		}
+		if i != 0 && (unicode.IsDigit(char) || char == '-' || char == '.') {
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RenderYAML writes rows, which are JSON objects, as YAML document.
+// Rows are rendered as a sequence, or as a mapping in keyed mode.
+func RenderYAML(w io.Writer, rows [][]byte, options DocumentOptions) error {
+	doc, err := buildDocument(rows, options)
+	if err != nil {
+		return err
+	}
+
+	root := &yaml.Node{Kind: yaml.SequenceNode}
+
+	if doc.keyed {
+		root.Kind = yaml.MappingNode
+
+		for _, keyed := range doc.keyedRows {
+			root.Content = append(root.Content, yamlString(keyed.key), yamlNode(keyed.row))
+		}
+	} else {
+		for _, row := range doc.rows {
+			root.Content = append(root.Content, yamlNode(row))
+		}
+	}
+
+	if len(root.Content) == 0 {
+		root.Style = yaml.FlowStyle
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	err = encoder.Encode(root)
+	if err != nil {
+		return fmt.Errorf("encode yaml: %w", err)
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("close yaml encoder: %w", err)
+	}
+
+	return nil
+}
+
+func yamlNode(value interface{}) *yaml.Node {
+	switch v := value.(type) {
+	case *object:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range v.keys {
+			node.Content = append(node.Content, yamlString(key), yamlNode(v.values[key]))
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+
+		return node
+
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, element := range v {
+			node.Content = append(node.Content, yamlNode(element))
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+
+		return node
+
+	case string:
+		return yamlString(v)
+
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+}
+
+// yamlString returns string node, which is quoted by encoder when it looks like a value of another type.
+func yamlString(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
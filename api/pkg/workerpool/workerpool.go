@@ -0,0 +1,105 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Pool represents a fixed number of workers that run submitted tasks.
+// Tasks that could not be started immediately wait in a bounded queue.
+type Pool interface {
+	// Submit adds the task to the queue. ErrQueueFull is returned when the queue has no free space.
+	Submit(task Task) error
+	// Close stops accepting tasks and waits until all submitted tasks are finished.
+	// Context of the tasks is canceled, so running tasks should stop and queued ones should not start the work.
+	Close() error
+}
+
+// Task represents a unit of work, its context is canceled when pool is closed.
+type Task func(ctx context.Context)
+
+// Options represents an options that used for creating worker pool.
+type Options struct {
+	// Workers is a number of tasks that could run at the same time.
+	Workers int
+	// QueueSize is a maximum number of tasks that wait for a free worker.
+	QueueSize int
+}
+
+var (
+	// ErrQueueFull happens when task is submitted, but all workers are busy and the queue has no free space.
+	ErrQueueFull = errors.New("queue is full")
+	// ErrPoolClosed happens when task is submitted after the pool is closed.
+	ErrPoolClosed = errors.New("pool is closed")
+)
+
+type pool struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	closed bool
+	tasks  chan Task
+
+	wg sync.WaitGroup
+}
+
+var _ Pool = (*pool)(nil)
+
+// New is used to create an instance of worker pool. It starts the workers until pool is closed.
+func New(options Options) *pool {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := &pool{
+		ctx:    ctx,
+		cancel: cancel,
+		tasks:  make(chan Task, options.QueueSize),
+	}
+
+	p.wg.Add(options.Workers)
+	for i := 0; i < options.Workers; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+func (p *pool) Submit(task Task) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.tasks <- task:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (p *pool) Close() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		p.cancel()
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	return nil
+}
+
+func (p *pool) work() {
+	defer p.wg.Done()
+
+	// Queued tasks are still called after the pool is closed, so they could record that they were not started.
+	for task := range p.tasks {
+		task(p.ctx)
+	}
+}